@@ -0,0 +1,155 @@
+// Comando backup exporta e importa os dados do sistema em um arquivo de backup
+//
+// Uso:
+//
+//	go run ./cmd/backup exportar -arquivo backup.ndjson [-senhas]
+//	go run ./cmd/backup importar -arquivo backup.ndjson
+//
+// Arquivos terminados em .gz são gravados compactados com gzip; na importação, a compactação é identificada pelo
+// conteúdo. A conexão com o banco de dados usa as mesmas variáveis de ambiente da API
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sistema-alunos-go/configs"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/services"
+	"sistema-alunos-go/utils"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		uso()
+	}
+
+	switch os.Args[1] {
+	case "exportar":
+		exportar(os.Args[2:])
+	case "importar":
+		importar(os.Args[2:])
+	default:
+		uso()
+	}
+}
+
+// exportar grava o backup no arquivo informado, compactado se o nome terminar em .gz
+func exportar(argumentos []string) {
+	opcoes := flag.NewFlagSet("exportar", flag.ExitOnError)
+	caminho := opcoes.String("arquivo", "", "arquivo de destino do backup (.ndjson ou .ndjson.gz)")
+	senhas := opcoes.Bool("senhas", false, "inclui os hashes das senhas de professores e alunos")
+	_ = opcoes.Parse(argumentos)
+	if *caminho == "" {
+		opcoes.Usage()
+		os.Exit(2)
+	}
+
+	conecta()
+
+	arquivo, err := os.Create(*caminho)
+	if err != nil {
+		falha("Erro ao criar arquivo de backup: %v", err)
+	}
+
+	var destino io.Writer = arquivo
+	var compactado *gzip.Writer
+	if strings.HasSuffix(*caminho, ".gz") {
+		compactado = gzip.NewWriter(arquivo)
+		destino = compactado
+	}
+
+	if restErr := services.ExportarBackup(destino, *senhas); restErr != nil {
+		descarta(arquivo)
+		falhaRest(restErr)
+	}
+	if compactado != nil {
+		if err := compactado.Close(); err != nil {
+			descarta(arquivo)
+			falha("Erro ao compactar arquivo de backup: %v", err)
+		}
+	}
+	if err := arquivo.Sync(); err != nil {
+		descarta(arquivo)
+		falha("Erro ao gravar arquivo de backup: %v", err)
+	}
+	if err := arquivo.Close(); err != nil {
+		_ = os.Remove(arquivo.Name())
+		falha("Erro ao gravar arquivo de backup: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Backup gravado em %s\n", *caminho)
+}
+
+// descarta fecha e remove o arquivo de um backup incompleto, para que não seja confundido com um backup válido
+func descarta(arquivo *os.File) {
+	_ = arquivo.Close()
+	_ = os.Remove(arquivo.Name())
+}
+
+// importar lê o backup do arquivo informado e o grava no banco de dados
+func importar(argumentos []string) {
+	opcoes := flag.NewFlagSet("importar", flag.ExitOnError)
+	caminho := opcoes.String("arquivo", "", "arquivo de backup a importar (.ndjson ou .ndjson.gz)")
+	_ = opcoes.Parse(argumentos)
+	if *caminho == "" {
+		opcoes.Usage()
+		os.Exit(2)
+	}
+
+	conecta()
+
+	arquivo, err := os.Open(*caminho)
+	if err != nil {
+		falha("Erro ao abrir arquivo de backup: %v", err)
+	}
+	defer arquivo.Close()
+
+	resultado, restErr := services.ImportarBackup(arquivo)
+	if restErr != nil {
+		falhaRest(restErr)
+	}
+
+	fmt.Fprintln(os.Stderr, "Backup importado com sucesso")
+	imprime(resultado)
+}
+
+// conecta carrega as variáveis de ambiente e conecta ao banco de dados, aplicando as migrações
+func conecta() {
+	configs.LoadEnv()
+	database.ConectaBD()
+}
+
+// falhaRest informa o erro de uma operação, com os problemas encontrados, e encerra o comando
+func falhaRest(restErr *utils.RestErr) {
+	fmt.Fprintln(os.Stderr, restErr.Msg)
+	if restErr.Err != nil {
+		fmt.Fprintln(os.Stderr, restErr.Err)
+	}
+	if restErr.Errors != nil {
+		imprime(restErr.Errors)
+	}
+	os.Exit(1)
+}
+
+// falha informa o erro e encerra o comando
+func falha(formato string, argumentos ...interface{}) {
+	fmt.Fprintf(os.Stderr, formato+"\n", argumentos...)
+	os.Exit(1)
+}
+
+// imprime escreve um valor em JSON indentado na saída padrão
+func imprime(valor interface{}) {
+	codificador := json.NewEncoder(os.Stdout)
+	codificador.SetIndent("", "  ")
+	_ = codificador.Encode(valor)
+}
+
+// uso informa a forma de uso do comando e o encerra
+func uso() {
+	fmt.Fprintln(os.Stderr, "Uso: backup exportar -arquivo <arquivo> [-senhas] | backup importar -arquivo <arquivo>")
+	os.Exit(2)
+}
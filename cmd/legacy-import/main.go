@@ -0,0 +1,85 @@
+// Comando legacy-import importa os arquivos de dados do antigo sistema de gerenciamento de alunos em C
+//
+// Uso:
+//
+//	go run ./cmd/legacy-import -layout layout.json -professor professor@escola.br [-dir dados] [-simular]
+//
+// O layout descreve o nome e a posição dos campos de cada arquivo; veja legado/layout-exemplo.json. Os arquivos são
+// lidos do diretório informado em -dir ou, se omitido, do diretório do layout. As disciplinas importadas ficam sob
+// responsabilidade do professor informado, que já deve estar cadastrado. Com -simular, nada é gravado.
+//
+// O resultado, com os registros que não puderam ser importados, é escrito em JSON na saída padrão. A importação pode
+// ser repetida com os mesmos arquivos sem duplicar registros. A conexão com o banco de dados usa as mesmas variáveis de
+// ambiente da API
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sistema-alunos-go/configs"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/legado"
+	"sistema-alunos-go/services"
+)
+
+func main() {
+	caminhoLayout := flag.String("layout", "", "arquivo JSON com o layout dos arquivos do sistema antigo")
+	diretorio := flag.String("dir", "", "diretório dos arquivos do sistema antigo (padrão: diretório do layout)")
+	professor := flag.String("professor", "", "e-mail do professor responsável pelas disciplinas importadas")
+	simular := flag.Bool("simular", false, "valida a importação sem gravar nada")
+	flag.Parse()
+
+	if *caminhoLayout == "" || *professor == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *diretorio == "" {
+		*diretorio = filepath.Dir(*caminhoLayout)
+	}
+
+	arquivoLayout, err := os.Open(*caminhoLayout)
+	if err != nil {
+		falha("Erro ao abrir layout: %v", err)
+	}
+	layout, err := legado.LeLayout(arquivoLayout)
+	arquivoLayout.Close()
+	if err != nil {
+		falha("Erro no layout: %v", err)
+	}
+
+	dados, err := layout.Carrega(*diretorio)
+	if err != nil {
+		falha("Erro ao ler arquivos do sistema antigo: %v", err)
+	}
+
+	configs.LoadEnv()
+	database.ConectaBD()
+	configs.BindingValidator()
+
+	resultado, restErr := services.ImportarLegado(dados, *professor, *simular)
+	if restErr != nil {
+		if restErr.Err != nil {
+			falha("%s: %v", restErr.Msg, restErr.Err)
+		}
+		falha("%s", restErr.Msg)
+	}
+
+	codificador := json.NewEncoder(os.Stdout)
+	codificador.SetIndent("", "  ")
+	_ = codificador.Encode(resultado)
+
+	situacao := "Importação concluída"
+	if resultado.Simulada {
+		situacao = "Importação simulada; nada foi gravado"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d registros rejeitados\n", situacao, len(resultado.Rejeitados))
+}
+
+// falha informa o erro e encerra o comando
+func falha(formato string, argumentos ...interface{}) {
+	fmt.Fprintf(os.Stderr, formato+"\n", argumentos...)
+	os.Exit(1)
+}
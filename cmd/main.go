@@ -8,15 +8,23 @@ import (
 	"sistema-alunos-go/database"
 	middleware "sistema-alunos-go/middlewares"
 	"sistema-alunos-go/routes"
+	"sistema-alunos-go/services"
+	"sistema-alunos-go/storage"
+	"time"
 )
 
 func init() {
 	configs.LoadEnv()
 	database.ConectaBD()
 	configs.BindingValidator()
+	storage.Inicializa()
 }
 
 func main() {
+	services.IniciaAvaliadorFrequencia()
+	go services.MonitorarChamadas(time.Minute)
+	go services.EntregarWebhooks(5 * time.Second)
+
 	r := gin.Default()
 	r.Use(middleware.ErrorHandlingMiddleware())
 
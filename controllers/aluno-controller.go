@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"bytes"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"sistema-alunos-go/models"
+	"sistema-alunos-go/relatorios"
 	"sistema-alunos-go/services"
 	"sistema-alunos-go/utils"
 	"sistema-alunos-go/validations"
@@ -34,6 +36,40 @@ func CadastrarAluno(ctx *gin.Context) {
 	))
 }
 
+// ImportarAlunos trata a importação de alunos a partir de uma planilha CSV ou XLSX.
+//
+// Recebe um formulário multipart com a planilha no campo "arquivo", com as colunas nome, email e, opcionalmente,
+// disciplina. Sem `aplicar=true`, apenas valida a planilha e retorna a prévia das alterações com os erros de cada
+// linha; com `aplicar=true`, cria ou atualiza os alunos e as matrículas em uma única transação.
+//
+// Retorna o resultado da importação com status 200 ou erro em caso de falha.
+func ImportarAlunos(ctx *gin.Context) {
+	var importacao models.ImportacaoPlanilha
+	if !validations.ImportacaoPlanilhaValida(&importacao, ctx) {
+		return
+	}
+
+	arquivo, _ := ctx.FormFile("arquivo")
+
+	result, restErr := services.ImportarAlunos(arquivo, importacao.Aplicar)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	mensagem := "Prévia da importação gerada com sucesso"
+	if result.Aplicada {
+		mensagem = "Alunos importados com sucesso"
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		mensagem,
+		http.StatusOK,
+		result,
+	))
+}
+
 // DesativarAluno trata a requisição para desativar um aluno (trancar matrícula).
 //
 // O ID do aluno é obtido via parâmetro de rota. Marca o aluno como inativo e retorna a entidade atualizada com status 200
@@ -96,3 +132,260 @@ func RemoverAluno(ctx *gin.Context) {
 		nil,
 	))
 }
+
+// ListarHistoricoPresencaAluno retorna o histórico de alterações de presença de um aluno
+//
+// O ID do aluno é obtido via parâmetro de rota. Opcionalmente filtra por disciplina via query string (`disciplinaId`)
+//
+// Retorna a lista de alterações com status 200 ou erro em caso de falha
+func ListarHistoricoPresencaAluno(ctx *gin.Context) {
+	id := ctx.Param("id")
+	disciplinaId := ctx.Query("disciplinaId")
+
+	result, restErr := services.ListarHistoricoPresencaAluno(id, disciplinaId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Histórico de presenças resgatado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ListarHistoricoNotasAluno retorna o histórico de alterações de notas de um aluno
+//
+// O ID do aluno é obtido via parâmetro de rota. Opcionalmente filtra por disciplina via query string (`disciplinaId`)
+//
+// Retorna a lista de alterações com status 200 ou erro em caso de falha
+func ListarHistoricoNotasAluno(ctx *gin.Context) {
+	id := ctx.Param("id")
+	disciplinaId := ctx.Query("disciplinaId")
+
+	result, restErr := services.ListarHistoricoNotasAluno(id, disciplinaId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Histórico de notas resgatado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ListarHistoricoEscolar retorna o histórico escolar de um aluno, com a situação final em cada disciplina
+//
+// O ID do aluno é obtido via parâmetro de rota.
+//
+// Retorna a lista de resultados com status 200 ou erro em caso de falha
+func ListarHistoricoEscolar(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	result, restErr := services.ListarHistoricoEscolar(id)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Histórico escolar resgatado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// DefinirSenhaAluno trata a requisição de definição da senha de acesso de um aluno
+//
+// O ID do aluno é obtido via parâmetro de rota e a senha, com sua confirmação, é enviada no corpo da requisição.
+// Apenas coordenadores e professores do aluno podem definir a senha.
+//
+// Retorna status 204 (No Content) se a senha for definida com sucesso ou erro em caso de falha
+func DefinirSenhaAluno(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	var definicao models.DefinicaoSenha
+	if !validations.DefinicaoSenhaValida(&definicao, ctx) {
+		return
+	}
+
+	if restErr := services.DefinirSenhaAluno(id, definicao.Senha, professorId); restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, utils.NewAppMessage(
+		"Senha definida com sucesso",
+		http.StatusNoContent,
+		nil,
+	))
+}
+
+// LoginAluno autentica um aluno com base em suas credenciais
+//
+// Valida o corpo da requisição (email e senha), chama o serviço de autenticação e retorna um token JWT juntamente com
+// os dados do aluno
+//
+// Retorna erro 401 se as credenciais forem inválidas
+func LoginAluno(ctx *gin.Context) {
+	var login models.Login
+	if !validations.LoginValido(&login, ctx) {
+		return
+	}
+	token, aluno, restErr := services.LoginAluno(login)
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"aluno": aluno,
+		"token": token,
+	})
+}
+
+// ListarAlertasAluno retorna os alertas de frequência do aluno autenticado
+//
+// Com `naoLidos=true` na query string, retorna apenas os alertas ainda não lidos.
+//
+// Retorna a lista de alertas com status 200 ou erro em caso de falha
+func ListarAlertasAluno(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	result, restErr := services.ListarAlertasAluno(alunoId, ctx.Query("naoLidos") == "true")
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Alertas resgatados com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GerarBoletim gera o boletim de um aluno em PDF para um período letivo
+//
+// O ID do aluno é obtido via parâmetro de rota e o período via query string (`periodo`, no formato yyyy-01 ou
+// yyyy-02).
+//
+// Retorna o arquivo PDF com status 200 ou erro em caso de falha
+func GerarBoletim(ctx *gin.Context) {
+	periodo := ctx.Query("periodo")
+
+	boletim, restErr := services.MontaBoletim(ctx.Param("id"), periodo)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	var pdf bytes.Buffer
+	if err := relatorios.GeraBoletim(boletim, &pdf); err != nil {
+		utils.RespondRestErr(utils.NewRestErr(http.StatusInternalServerError, "Erro ao gerar boletim", err), ctx)
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="boletim-`+periodo+`.pdf"`)
+	ctx.Data(http.StatusOK, "application/pdf", pdf.Bytes())
+}
+
+// GetFeedbackRubricaAluno retorna ao aluno autenticado a correção por rubrica do seu trabalho
+//
+// O ID da avaliação é passado via parâmetro de rota.
+//
+// Retorna a nota e o retorno de cada critério com status 200 ou erro em caso de falha
+func GetFeedbackRubricaAluno(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	result, restErr := services.GetFeedbackRubricaAluno(ctx.Param("avaliacaoId"), alunoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Correção resgatada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// MarcarAlertaLido marca como lido um alerta de frequência do aluno autenticado
+//
+// O ID do alerta é obtido via parâmetro de rota.
+//
+// Retorna o alerta atualizado com status 200 ou erro em caso de falha
+func MarcarAlertaLido(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	result, restErr := services.MarcarAlertaLido(ctx.Param("id"), alunoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Alerta marcado como lido",
+		http.StatusOK,
+		result,
+	))
+}
+
+// getAlunoId é uma função auxiliar que extrai o ID do aluno autenticado a partir do contexto da requisição
+//
+// Utiliza os dados salvos pelo middleware de autenticação JWT de alunos.
+//
+// Se não estiver presente, retorna erro 401 e encerra a execução do handler
+func getAlunoId(ctx *gin.Context) string {
+	aluno, exists := ctx.Get("aluno")
+	if !exists {
+		restErr := utils.NewRestErr(http.StatusUnauthorized, "Aluno não autenticado", nil)
+		utils.RespondRestErr(restErr, ctx)
+		return ""
+	}
+
+	return aluno.(string)
+}
+
+// getUsuario é uma função auxiliar que identifica o usuário autenticado, professor ou aluno
+//
+// Retorna o ID e o tipo do usuário (models.AutorProfessor ou models.AutorAluno). Se nenhum usuário estiver presente,
+// retorna erro 401, encerra a execução do handler e devolve um ID vazio
+func getUsuario(ctx *gin.Context) (string, string) {
+	if professor, exists := ctx.Get("professor"); exists {
+		return professor.(string), models.AutorProfessor
+	}
+
+	if aluno, exists := ctx.Get("aluno"); exists {
+		return aluno.(string), models.AutorAluno
+	}
+
+	restErr := utils.NewRestErr(http.StatusUnauthorized, "Usuário não autenticado", nil)
+	utils.RespondRestErr(restErr, ctx)
+	return "", ""
+}
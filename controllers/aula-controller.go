@@ -80,3 +80,147 @@ func GetAula(ctx *gin.Context) {
 		result,
 	))
 }
+
+// SubstituirAula trata a requisição de atualização completa de uma aula
+//
+// O ID da aula é obtido via parâmetro de rota. O corpo segue as mesmas regras do cadastro e substitui todos os dados
+// da aula, inclusive a lista de presenças
+//
+// Retorna a aula atualizada com status 200 ou erro, se houver falha
+func SubstituirAula(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	var aula models.Aula
+	if !validations.AulaValida(&aula, ctx) {
+		return
+	}
+
+	result, restErr := services.AtualizarAula(id, models.AtualizacaoAula{
+		Numero:          &aula.Numero,
+		Data:            &aula.Data,
+		QuantidadeHoras: &aula.QuantidadeHoras,
+		Conteudo:        &aula.Conteudo,
+		AlunoAula:       aula.AlunoAula,
+	}, professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Aula atualizada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// AtualizarAula trata a requisição de atualização parcial de uma aula
+//
+// O ID da aula é obtido via parâmetro de rota. Apenas os campos enviados no corpo são alterados
+//
+// Retorna a aula atualizada com status 200 ou erro, se houver falha
+func AtualizarAula(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	var atualizacao models.AtualizacaoAula
+	if !validations.AtualizacaoAulaValida(&atualizacao, ctx) {
+		return
+	}
+
+	result, restErr := services.AtualizarAula(id, atualizacao, professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Aula atualizada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// RemoverAula trata a requisição de exclusão de uma aula
+//
+// O ID da aula é obtido via parâmetro de rota. Remove a aula e suas presenças e retorna status 204 (No Content)
+func RemoverAula(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if restErr := services.RemoverAula(id); restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, utils.NewAppMessage(
+		"Aula removida com sucesso",
+		http.StatusNoContent,
+		nil,
+	))
+}
+
+// CorrigirPresenca trata a requisição de marcação ou correção de presença de alunos em uma aula já cadastrada
+//
+// O ID da aula é obtido via parâmetro de rota e o professor autenticado é registrado como autor da alteração.
+// O corpo contém a lista de presenças e o motivo da correção
+//
+// Retorna os registros de presença atualizados com status 200 ou erro, se houver falha
+func CorrigirPresenca(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	var correcao models.CorrecaoPresenca
+	if !validations.CorrecaoPresencaValida(&correcao, ctx) {
+		return
+	}
+
+	result, restErr := services.CorrigirPresenca(id, correcao, professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Presenças atualizadas com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ListarHistoricoPresencaAula retorna o histórico de alterações de presença de uma aula
+//
+// O ID da aula é obtido via parâmetro de rota.
+//
+// Retorna a lista de alterações com status 200 ou erro em caso de falha
+func ListarHistoricoPresencaAula(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	result, restErr := services.ListarHistoricoPresencaAula(id)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Histórico de presenças resgatado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
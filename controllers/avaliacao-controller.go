@@ -0,0 +1,395 @@
+package controllers
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/planilhas"
+	"sistema-alunos-go/services"
+	"sistema-alunos-go/utils"
+	"sistema-alunos-go/validations"
+)
+
+// SubstituirAvaliacao substitui todos os dados de uma avaliação de uma disciplina.
+//
+// Os IDs da disciplina e da avaliação são passados via rota e o corpo deve conter a avaliação completa, com as mesmas
+// regras do cadastro.
+//
+// Retorna a avaliação atualizada com status 200 ou erro, se houver falha.
+func SubstituirAvaliacao(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	var avaliacao models.Avaliacao
+	if !validations.AvaliacaoValida(&avaliacao, ctx) {
+		return
+	}
+
+	dados := models.AtualizacaoAvaliacao{
+		Nome:          &avaliacao.Nome,
+		Tipo:          &avaliacao.Tipo,
+		DataAvaliacao: &avaliacao.DataAvaliacao,
+		Peso:          &avaliacao.Peso,
+	}
+
+	result, restErr := services.AtualizarAvaliacao(disciplinaId, avaliacaoId, dados)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Avaliação atualizada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// AtualizarAvaliacao altera parcialmente os dados de uma avaliação de uma disciplina.
+//
+// Os IDs da disciplina e da avaliação são passados via rota e apenas os campos enviados no corpo são alterados.
+//
+// Retorna a avaliação atualizada com status 200 ou erro, se houver falha.
+func AtualizarAvaliacao(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	var dados models.AtualizacaoAvaliacao
+	if !validations.AtualizacaoAvaliacaoValida(&dados, ctx) {
+		return
+	}
+
+	result, restErr := services.AtualizarAvaliacao(disciplinaId, avaliacaoId, dados)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Avaliação atualizada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// RemoverAvaliacao apaga uma avaliação de uma disciplina, junto com as notas lançadas para ela.
+//
+// Os IDs da disciplina e da avaliação são passados via rota.
+//
+// Retorna status 200 em caso de sucesso ou erro, se houver falha.
+func RemoverAvaliacao(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	if restErr := services.RemoverAvaliacao(disciplinaId, avaliacaoId, professorId); restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Avaliação removida com sucesso",
+		http.StatusOK,
+		nil,
+	))
+}
+
+// VerificarPesos retorna o relatório de consistência dos pesos das avaliações de uma disciplina.
+//
+// Indica a soma dos pesos, o peso efetivo de cada avaliação e se o semestre pode ser fechado.
+func VerificarPesos(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+
+	result, restErr := services.VerificarPesos(disciplinaId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Pesos verificados com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// CorrigirNota altera a nota de um aluno em uma avaliação.
+//
+// Os IDs da disciplina, da avaliação e do aluno são passados via rota. O corpo deve conter a nova nota e a
+// justificativa da correção, que fica registrada no histórico de notas.
+//
+// Retorna a nota atualizada com status 200 ou erro, se houver falha.
+func CorrigirNota(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+	alunoId := ctx.Param("alunoId")
+
+	var correcao models.CorrecaoNota
+	if !validations.CorrecaoNotaValida(&correcao, ctx) {
+		return
+	}
+
+	result, restErr := services.CorrigirNota(disciplinaId, avaliacaoId, alunoId, correcao, professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Nota corrigida com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ListarHistoricoNotasAvaliacao retorna o histórico de alterações das notas de uma avaliação.
+//
+// Os IDs da disciplina e da avaliação são passados via rota.
+//
+// Retorna a lista de alterações com status 200 ou erro em caso de falha.
+func ListarHistoricoNotasAvaliacao(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	result, restErr := services.ListarHistoricoNotasAvaliacao(disciplinaId, avaliacaoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Histórico de notas resgatado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GetEstatisticasAvaliacao retorna as estatísticas e a distribuição das notas de uma avaliação.
+//
+// Os IDs da disciplina e da avaliação são passados via rota.
+//
+// Retorna as estatísticas com status 200 ou erro em caso de falha.
+func GetEstatisticasAvaliacao(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	result, restErr := services.GetEstatisticasAvaliacao(disciplinaId, avaliacaoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Estatísticas resgatadas com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// DefinirRubrica cadastra ou substitui a rubrica de correção de um trabalho.
+//
+// Os IDs da disciplina e da avaliação são passados via rota e os critérios, com seus níveis, no corpo da requisição.
+//
+// Retorna a rubrica cadastrada com status 200 ou erro em caso de falha.
+func DefinirRubrica(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	var rubrica models.Rubrica
+	if !validations.RubricaValida(&rubrica, ctx) {
+		return
+	}
+
+	result, restErr := services.DefinirRubrica(disciplinaId, avaliacaoId, rubrica)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Rubrica definida com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GetRubrica retorna a rubrica de correção de uma avaliação.
+//
+// Os IDs da disciplina e da avaliação são passados via rota.
+//
+// Retorna a rubrica com status 200 ou erro em caso de falha.
+func GetRubrica(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	result, restErr := services.GetRubrica(disciplinaId, avaliacaoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Rubrica resgatada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// CorrigirRubrica corrige o trabalho de um aluno pela rubrica e lança a nota calculada.
+//
+// Os IDs da disciplina, da avaliação e do aluno são passados via rota e os níveis escolhidos para cada critério, no
+// corpo da requisição.
+//
+// Retorna a nota e o retorno de cada critério com status 200 ou erro em caso de falha.
+func CorrigirRubrica(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+	alunoId := ctx.Param("alunoId")
+
+	var correcao models.CorrecaoRubrica
+	if !validations.CorrecaoRubricaValida(&correcao, ctx) {
+		return
+	}
+
+	result, restErr := services.CorrigirRubrica(disciplinaId, avaliacaoId, alunoId, correcao, professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Trabalho corrigido com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GetFeedbackRubrica retorna a correção por rubrica do trabalho de um aluno.
+//
+// Os IDs da disciplina, da avaliação e do aluno são passados via rota.
+//
+// Retorna a correção com status 200 ou erro em caso de falha.
+func GetFeedbackRubrica(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+	alunoId := ctx.Param("alunoId")
+
+	result, restErr := services.GetFeedbackRubrica(disciplinaId, avaliacaoId, alunoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Correção resgatada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// BaixarPlanilhaNotas envia o modelo da planilha de notas de uma avaliação, com os alunos matriculados e as notas já
+// lançadas.
+//
+// Os IDs da disciplina e da avaliação são passados via rota e o formato, "xlsx" (padrão) ou "csv", via query string
+// (`formato`). A planilha preenchida pode ser enviada de volta para ImportarNotasAvaliacao.
+//
+// Retorna a planilha como anexo com status 200 ou erro em caso de falha.
+func BaixarPlanilhaNotas(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	formato := ctx.DefaultQuery("formato", planilhas.FormatoXlsx)
+	tipos := map[string]string{
+		planilhas.FormatoXlsx: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		planilhas.FormatoCsv:  "text/csv; charset=utf-8",
+	}
+	tipo, ok := tipos[formato]
+	if !ok {
+		utils.RespondRestErr(utils.NewRestErr(http.StatusBadRequest, "O formato deve ser xlsx ou csv", nil), ctx)
+		return
+	}
+
+	linhas, restErr := services.PlanilhaNotasAvaliacao(disciplinaId, avaliacaoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	var planilha bytes.Buffer
+	if err := planilhas.Escreve(formato, "Notas", linhas, &planilha); err != nil {
+		utils.RespondRestErr(utils.NewRestErr(http.StatusInternalServerError, "Erro ao gerar planilha", err), ctx)
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="notas-`+avaliacaoId+"."+formato+`"`)
+	ctx.Data(http.StatusOK, tipo, planilha.Bytes())
+}
+
+// ImportarNotasAvaliacao trata a importação das notas de uma avaliação a partir de uma planilha CSV ou XLSX.
+//
+// Os IDs da disciplina e da avaliação são passados via rota. Recebe um formulário multipart com a planilha no campo
+// "arquivo", com a coluna nota e a coluna matrícula ou email, como a gerada por BaixarPlanilhaNotas. Sem
+// `aplicar=true`, apenas valida a planilha e retorna a prévia com a nota atual e a nova de cada aluno; com
+// `aplicar=true`, lança as notas novas ou alteradas, registrando a `justificativa` opcional no histórico de notas.
+//
+// Retorna o resultado da importação com status 200 ou erro em caso de falha.
+func ImportarNotasAvaliacao(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	var importacao models.ImportacaoNotas
+	if !validations.ImportacaoNotasValida(&importacao, ctx) {
+		return
+	}
+
+	arquivo, _ := ctx.FormFile("arquivo")
+
+	result, restErr := services.ImportarNotasAvaliacao(arquivo, disciplinaId, avaliacaoId, professorId, importacao)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	mensagem := "Prévia da importação gerada com sucesso"
+	if result.Aplicada {
+		mensagem = "Notas importadas com sucesso"
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		mensagem,
+		http.StatusOK,
+		result,
+	))
+}
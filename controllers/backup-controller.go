@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sistema-alunos-go/services"
+	"sistema-alunos-go/utils"
+	"time"
+)
+
+// ExportarBackup envia um backup de professores, alunos, disciplinas, aulas, avaliações, matrículas, presenças, notas
+// e médias em NDJSON.
+//
+// Apenas coordenadores podem exportar backups. Os hashes das senhas só são incluídos com `senhas=true` na query
+// string. Erros durante a gravação só podem ser registrados em log, pois a resposta já foi iniciada.
+func ExportarBackup(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	if restErr := services.VerificarCoordenador(professorId); restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	nome := "backup-" + time.Now().UTC().Format("20060102-150405") + ".ndjson"
+	ctx.Header("Content-Type", "application/x-ndjson")
+	ctx.Header("Content-Disposition", `attachment; filename="`+nome+`"`)
+	ctx.Status(http.StatusOK)
+
+	if restErr := services.ExportarBackup(ctx.Writer, ctx.Query("senhas") == "true"); restErr != nil {
+		log.Printf("Erro ao exportar backup: %v", restErr.Err)
+	}
+}
+
+// ImportarBackup importa um backup gerado por ExportarBackup, preservando os IDs dos registros.
+//
+// Apenas coordenadores podem importar backups. Recebe um formulário multipart com o arquivo, compactado ou não com
+// gzip, no campo "arquivo". Nada é gravado se o arquivo for inválido ou se algum registro conflitar com os dados
+// atuais; nesse caso, a resposta lista os problemas encontrados.
+//
+// Retorna a quantidade de registros importados de cada tabela com status 201 ou erro em caso de falha.
+func ImportarBackup(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	if restErr := services.VerificarCoordenador(professorId); restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	cabecalho, err := ctx.FormFile("arquivo")
+	if err != nil {
+		utils.RespondRestErr(utils.NewRestErr(http.StatusBadRequest, "O arquivo de backup é obrigatório", err), ctx)
+		return
+	}
+
+	arquivo, err := cabecalho.Open()
+	if err != nil {
+		utils.RespondRestErr(utils.NewRestErr(http.StatusBadRequest, "Erro ao ler arquivo de backup", err), ctx)
+		return
+	}
+	defer arquivo.Close()
+
+	result, restErr := services.ImportarBackup(arquivo)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.NewAppMessage(
+		"Backup importado com sucesso",
+		http.StatusCreated,
+		result,
+	))
+}
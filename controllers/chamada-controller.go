@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/services"
+	"sistema-alunos-go/utils"
+	"sistema-alunos-go/validations"
+)
+
+// AbrirChamada trata a abertura de uma sessão de check-in para uma aula
+//
+// O ID da aula é obtido via parâmetro de rota e a duração da sessão é enviada no corpo da requisição.
+//
+// Retorna a sessão criada com status 201 ou erro, se houver falha
+func AbrirChamada(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	var abertura models.AberturaChamada
+	if !validations.AberturaChamadaValida(&abertura, ctx) {
+		return
+	}
+
+	result, restErr := services.AbrirChamada(ctx.Param("aulaId"), abertura, professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.NewAppMessage(
+		"Chamada aberta com sucesso",
+		http.StatusCreated,
+		result,
+	))
+}
+
+// GetCodigoChamada retorna o código vigente da chamada aberta de uma aula
+//
+// Retorna o código e sua validade com status 200 ou erro, se não houver chamada aberta
+func GetCodigoChamada(ctx *gin.Context) {
+	result, restErr := services.GetCodigoChamada(ctx.Param("aulaId"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Código da chamada resgatado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GetQRCodeChamada envia a imagem PNG do QR code com o código vigente da chamada aberta de uma aula
+func GetQRCodeChamada(ctx *gin.Context) {
+	png, restErr := services.GetQRCodeChamada(ctx.Param("aulaId"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "image/png", png)
+}
+
+// RegistrarCheckIn trata o check-in do aluno autenticado na chamada aberta de uma aula
+//
+// O ID da aula é obtido via parâmetro de rota e o código exibido pelo professor é enviado no corpo da requisição.
+//
+// Retorna o registro de presença com status 200 ou erro, se o código for inválido
+func RegistrarCheckIn(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	var checkIn models.CheckIn
+	if !validations.CheckInValido(&checkIn, ctx) {
+		return
+	}
+
+	result, restErr := services.RegistrarCheckIn(ctx.Param("aulaId"), checkIn.Codigo, alunoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Presença registrada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// EncerrarChamada trata o encerramento antecipado da chamada aberta de uma aula
+//
+// Os alunos que não fizeram check-in são marcados como ausentes.
+//
+// Retorna a sessão encerrada com status 200 ou erro, se não houver chamada aberta
+func EncerrarChamada(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	result, restErr := services.EncerrarChamada(ctx.Param("aulaId"), professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Chamada encerrada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
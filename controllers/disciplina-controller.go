@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"bytes"
 	"github.com/gin-gonic/gin"
+	"io"
 	"net/http"
 	"sistema-alunos-go/models"
+	"sistema-alunos-go/relatorios"
 	"sistema-alunos-go/services"
 	"sistema-alunos-go/utils"
 	"sistema-alunos-go/validations"
@@ -93,10 +96,19 @@ func AdicionarAvaliacao(ctx *gin.Context) {
 // AdicionarNotaAvaliacao registra as notas dos alunos para uma avaliação específica
 //
 // Os IDs da disciplina e da avaliação são passados via rota. Recebe uma lista de notas no corpo da requisição e
-// chama o serviço de persistência.
+// chama o serviço de persistência. Notas já lançadas são substituídas e a justificativa opcional, enviada via query
+// string (`justificativa`), é registrada no histórico de notas.
+//
+// Com `parcial=true` na query string, as linhas válidas são gravadas e as inválidas são devolvidas como rejeitadas;
+// caso contrário, qualquer linha inválida rejeita toda a lista.
 //
 // Retorna as notas cadastradas com status 201 ou erro em caso de falha
 func AdicionarNotaAvaliacao(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
 	disciplinaId := ctx.Param("disciplinaId")
 	avaliacaoId := ctx.Param("avaliacaoId")
 
@@ -105,20 +117,64 @@ func AdicionarNotaAvaliacao(ctx *gin.Context) {
 		return
 	}
 
-	result, restErr := services.AdicionarNotaAvaliacao(alunosNota, avaliacaoId, disciplinaId)
+	justificativa := ctx.Query("justificativa")
+	parcial := ctx.Query("parcial") == "true"
+
+	result, restErr := services.AdicionarNotaAvaliacao(alunosNota, avaliacaoId, disciplinaId, professorId, justificativa, parcial)
 
 	if restErr != nil {
 		utils.RespondRestErr(restErr, ctx)
 		return
 	}
 
+	if !parcial {
+		ctx.JSON(http.StatusCreated, utils.NewAppMessage(
+			"Notas adicionadas com sucesso",
+			http.StatusCreated,
+			result.Salvas,
+		))
+		return
+	}
+
+	mensagem := "Notas adicionadas com sucesso"
+	if len(result.Rejeitadas) > 0 {
+		mensagem = "Notas adicionadas parcialmente"
+	}
+
 	ctx.JSON(http.StatusCreated, utils.NewAppMessage(
-		"Notas adicionadas com sucesso",
+		mensagem,
 		http.StatusCreated,
 		result,
 	))
 }
 
+// ConfigurarDisciplina altera os critérios de cálculo de uma disciplina, como o modo de frequência
+//
+// O ID da disciplina é passado via parâmetro de rota e apenas os campos enviados no corpo são alterados.
+//
+// Retorna a disciplina atualizada com status 200 ou erro, se houver falha.
+func ConfigurarDisciplina(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+
+	var configuracao models.ConfiguracaoDisciplina
+	if !validations.ConfiguracaoDisciplinaValida(&configuracao, ctx) {
+		return
+	}
+
+	result, restErr := services.ConfigurarDisciplina(disciplinaId, configuracao)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Disciplina atualizada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
 // ListarDisciplinas retorna todas as disciplinas do professor autenticado.
 //
 // Retorna a lista de disciplinas com status 201 ou erro em caso de falha.
@@ -162,6 +218,187 @@ func FecharSemestre(ctx *gin.Context) {
 	))
 }
 
+// FecharRecuperacao calcula o resultado definitivo dos alunos em recuperação e fecha a disciplina.
+//
+// O ID da disciplina é passado via parâmetro de rota.
+//
+// Retorna as médias atualizadas dos alunos em recuperação com status 200 ou erro.
+func FecharRecuperacao(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+
+	result, restErr := services.FecharRecuperacao(disciplinaId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Recuperação fechada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ListarResultadosDisciplina retorna os resultados finais dos alunos de uma disciplina.
+//
+// O ID da disciplina é passado via parâmetro de rota e a situação pode ser filtrada via query string (`situacao`).
+//
+// Retorna a lista de resultados com status 200 ou erro, se houver falha.
+func ListarResultadosDisciplina(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+
+	result, restErr := services.ListarResultadosDisciplina(disciplinaId, ctx.Query("situacao"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Resultados resgatados com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GetEstatisticasDisciplina retorna as estatísticas dos resultados finais de uma disciplina.
+//
+// O ID da disciplina é passado via parâmetro de rota. Inclui a distribuição das médias e frequências e as taxas de
+// aprovação e reprovação por situação.
+//
+// Retorna as estatísticas com status 200 ou erro, se houver falha.
+func GetEstatisticasDisciplina(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+
+	result, restErr := services.GetEstatisticasDisciplina(disciplinaId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Estatísticas resgatadas com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GetEscalaDisciplina retorna a escala de notas de uma disciplina e sua tabela de conversão.
+//
+// O ID da disciplina é passado via parâmetro de rota.
+//
+// Retorna a escala com status 200 ou erro, se houver falha.
+func GetEscalaDisciplina(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+
+	result, restErr := services.GetEscalaDisciplina(disciplinaId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Escala de notas resgatada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ListarAlertasDisciplina retorna os alertas de frequência emitidos para os alunos de uma disciplina
+//
+// O ID da disciplina é passado via parâmetro de rota.
+//
+// Retorna a lista de alertas com status 200 ou erro em caso de falha
+func ListarAlertasDisciplina(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+
+	result, restErr := services.ListarAlertasDisciplina(disciplinaId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Alertas resgatados com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ExportarDiarioClasse exporta o diário de classe de uma disciplina, com as aulas e a grade de frequência.
+//
+// O ID da disciplina é passado via parâmetro de rota e o formato via query string (`formato`, "pdf" ou "csv"; o
+// padrão é "pdf").
+//
+// Retorna o arquivo com status 200 ou erro em caso de falha.
+func ExportarDiarioClasse(ctx *gin.Context) {
+	diario, restErr := services.MontaDiarioClasse(ctx.Param("disciplinaId"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	enviaDocumento(ctx, "diario-classe-"+diario.DisciplinaId,
+		func(w io.Writer) error { return relatorios.GeraDiarioClasse(diario, w) },
+		func(w io.Writer) error { return relatorios.GeraDiarioClasseCsv(diario, w) },
+	)
+}
+
+// ExportarAtaFinal exporta a ata final de uma disciplina, com as notas, a frequência, a média e a situação de cada
+// aluno matriculado.
+//
+// O ID da disciplina é passado via parâmetro de rota e o formato via query string (`formato`, "pdf" ou "csv"; o
+// padrão é "pdf").
+//
+// Retorna o arquivo com status 200 ou erro em caso de falha.
+func ExportarAtaFinal(ctx *gin.Context) {
+	ata, restErr := services.MontaAtaFinal(ctx.Param("disciplinaId"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	enviaDocumento(ctx, "ata-final-"+ata.DisciplinaId,
+		func(w io.Writer) error { return relatorios.GeraAtaFinal(ata, w) },
+		func(w io.Writer) error { return relatorios.GeraAtaFinalCsv(ata, w) },
+	)
+}
+
+// enviaDocumento gera um documento no formato pedido na query string (`formato`, "pdf" ou "csv") e o envia como
+// anexo com o nome informado, acrescido da extensão.
+//
+// Responde com erro 400 se o formato for inválido ou 500 se a geração falhar.
+func enviaDocumento(ctx *gin.Context, nome string, geraPdf func(io.Writer) error, geraCsv func(io.Writer) error) {
+	formato := ctx.DefaultQuery("formato", "pdf")
+
+	var gera func(io.Writer) error
+	var tipo string
+	switch formato {
+	case "pdf":
+		gera, tipo = geraPdf, "application/pdf"
+	case "csv":
+		gera, tipo = geraCsv, "text/csv; charset=utf-8"
+	default:
+		utils.RespondRestErr(utils.NewRestErr(http.StatusBadRequest, "O formato deve ser pdf ou csv", nil), ctx)
+		return
+	}
+
+	var documento bytes.Buffer
+	if err := gera(&documento); err != nil {
+		utils.RespondRestErr(utils.NewRestErr(http.StatusInternalServerError, "Erro ao gerar documento", err), ctx)
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="`+nome+"."+formato+`"`)
+	ctx.Data(http.StatusOK, tipo, documento.Bytes())
+}
+
 // getProfessorId é uma função auxiliar que extrai o ID do professor autenticado a partir do contexto da requisição
 //
 // # Utiliza os dados salvos pelo middleware de autenticação JWT
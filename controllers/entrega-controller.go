@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/services"
+	"sistema-alunos-go/utils"
+	"sistema-alunos-go/validations"
+)
+
+// DefinirJanelaEntrega trata a definição do período de entrega de um trabalho
+//
+// Os IDs da disciplina e da avaliação são passados via rota e o prazo, o atraso máximo e a penalidade, no corpo.
+//
+// Retorna a janela de entrega com status 200 ou erro, se houver falha
+func DefinirJanelaEntrega(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	var janela models.JanelaEntrega
+	if !validations.JanelaEntregaValida(&janela, ctx) {
+		return
+	}
+
+	result, restErr := services.DefinirJanelaEntrega(disciplinaId, avaliacaoId, janela)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Janela de entrega definida com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// EnviarEntrega trata o envio do arquivo de um trabalho pelo aluno autenticado
+//
+// O ID da avaliação é passado via parâmetro de rota e o arquivo, no campo `arquivo` de um formulário multipart.
+//
+// Retorna a entrega registrada com status 201 ou erro, se houver falha
+func EnviarEntrega(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	arquivo, _ := ctx.FormFile("arquivo")
+
+	result, restErr := services.EnviarEntrega(ctx.Param("avaliacaoId"), alunoId, arquivo)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.NewAppMessage(
+		"Entrega enviada com sucesso",
+		http.StatusCreated,
+		result,
+	))
+}
+
+// ListarEntregasAluno retorna o histórico de entregas do aluno autenticado para um trabalho
+//
+// O ID da avaliação é passado via parâmetro de rota.
+//
+// Retorna a lista de entregas com status 200 ou erro, se houver falha
+func ListarEntregasAluno(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	result, restErr := services.ListarEntregasAluno(ctx.Param("avaliacaoId"), alunoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Entregas resgatadas com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ListarEntregasAvaliacao retorna as entregas dos alunos para um trabalho
+//
+// Os IDs da disciplina e da avaliação são passados via rota. Com `historico=true` na query string, as versões
+// anteriores de cada entrega também são retornadas.
+//
+// Retorna a lista de entregas com status 200 ou erro, se houver falha
+func ListarEntregasAvaliacao(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	result, restErr := services.ListarEntregasAvaliacao(disciplinaId, avaliacaoId, ctx.Query("historico") == "true")
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Entregas resgatadas com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// BaixarEntregas envia um arquivo zip com as entregas atuais de todos os alunos para um trabalho
+//
+// Os IDs da disciplina e da avaliação são passados via rota. Com `historico=true` na query string, as versões
+// anteriores também são incluídas. Erros durante a geração do zip só podem ser registrados em log, pois a resposta já
+// foi iniciada
+func BaixarEntregas(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	entregas, restErr := services.ListarEntregasAvaliacao(disciplinaId, avaliacaoId, ctx.Query("historico") == "true")
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", `attachment; filename="entregas-`+avaliacaoId+`.zip"`)
+	ctx.Status(http.StatusOK)
+
+	if err := services.CompactaEntregas(ctx.Writer, entregas); err != nil {
+		log.Printf("Erro ao gerar zip de entregas da avaliação %s: %v", avaliacaoId, err)
+	}
+}
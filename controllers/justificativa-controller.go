@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"mime"
+	"net/http"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/services"
+	"sistema-alunos-go/utils"
+	"sistema-alunos-go/validations"
+)
+
+// EnviarJustificativa trata o envio de uma justificativa de faltas com documento comprobatório
+//
+// Recebe um formulário multipart com a disciplina, as aulas, o motivo e o arquivo no campo "documento". Pode ser
+// usada por alunos, em nome próprio, ou por professores, informando o aluno
+//
+// Retorna a justificativa criada com status 201 ou erro, se houver falha
+func EnviarJustificativa(ctx *gin.Context) {
+	usuarioId, usuarioTipo := getUsuario(ctx)
+	if usuarioId == "" {
+		return
+	}
+
+	var solicitacao models.SolicitacaoJustificativa
+	if !validations.SolicitacaoJustificativaValida(&solicitacao, ctx) {
+		return
+	}
+
+	documento, _ := ctx.FormFile("documento")
+
+	result, restErr := services.EnviarJustificativa(solicitacao, documento, usuarioId, usuarioTipo)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.NewAppMessage(
+		"Justificativa enviada com sucesso",
+		http.StatusCreated,
+		result,
+	))
+}
+
+// AprovarJustificativa trata a aprovação de uma justificativa pendente pelo professor da disciplina ou coordenador
+//
+// O ID da justificativa é obtido via parâmetro de rota e uma observação opcional pode ser enviada no corpo.
+//
+// Retorna a justificativa atualizada com status 200 ou erro, se houver falha
+func AprovarJustificativa(ctx *gin.Context) {
+	avaliarJustificativa(ctx, true)
+}
+
+// RejeitarJustificativa trata a rejeição de uma justificativa pendente pelo professor da disciplina ou coordenador
+//
+// O ID da justificativa é obtido via parâmetro de rota e uma observação opcional pode ser enviada no corpo.
+//
+// Retorna a justificativa atualizada com status 200 ou erro, se houver falha
+func RejeitarJustificativa(ctx *gin.Context) {
+	avaliarJustificativa(ctx, false)
+}
+
+// ListarJustificativasDisciplina retorna as justificativas enviadas para uma disciplina
+//
+// O ID da disciplina é obtido via parâmetro de rota e a situação pode ser filtrada via query string (`status`).
+// Apenas o professor responsável pela disciplina ou um coordenador pode listar as justificativas.
+//
+// Retorna a lista de justificativas com status 200 ou erro em caso de falha
+func ListarJustificativasDisciplina(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	result, restErr := services.ListarJustificativasDisciplina(disciplinaId, ctx.Query("status"), professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Justificativas resgatadas com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ListarJustificativasAluno retorna as justificativas do aluno autenticado
+//
+// A situação pode ser filtrada via query string (`status`).
+//
+// Retorna a lista de justificativas com status 200 ou erro em caso de falha
+func ListarJustificativasAluno(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	result, restErr := services.ListarJustificativasAluno(alunoId, ctx.Query("status"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Justificativas resgatadas com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GetJustificativa retorna os detalhes de uma justificativa
+//
+// Alunos só podem consultar as próprias justificativas.
+//
+// Retorna a justificativa com status 200 ou erro em caso de falha
+func GetJustificativa(ctx *gin.Context) {
+	usuarioId, usuarioTipo := getUsuario(ctx)
+	if usuarioId == "" {
+		return
+	}
+
+	result, restErr := services.GetJustificativa(ctx.Param("id"), usuarioId, usuarioTipo)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Justificativa resgatada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// BaixarDocumentoJustificativa envia o documento comprobatório de uma justificativa
+//
+// Alunos só podem baixar os documentos das próprias justificativas
+func BaixarDocumentoJustificativa(ctx *gin.Context) {
+	usuarioId, usuarioTipo := getUsuario(ctx)
+	if usuarioId == "" {
+		return
+	}
+
+	documento, justificativa, restErr := services.AbrirDocumentoJustificativa(ctx.Param("id"), usuarioId, usuarioTipo)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+	defer documento.Close()
+
+	// O nome foi informado pelo aluno e pode conter aspas, ponto e vírgula ou acentos, que precisam ser codificados
+	disposicao := mime.FormatMediaType("attachment", map[string]string{"filename": justificativa.DocumentoNome})
+	if disposicao == "" {
+		disposicao = "attachment"
+	}
+
+	ctx.DataFromReader(http.StatusOK, -1, justificativa.DocumentoTipo, documento, map[string]string{
+		"Content-Disposition": disposicao,
+	})
+}
+
+// avaliarJustificativa é uma função auxiliar que aprova ou rejeita a justificativa indicada na rota
+func avaliarJustificativa(ctx *gin.Context, aprovada bool) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	var avaliacao models.AvaliacaoJustificativa
+	if ctx.Request.ContentLength != 0 && !validations.AvaliacaoJustificativaValida(&avaliacao, ctx) {
+		return
+	}
+
+	result, restErr := services.AvaliarJustificativa(ctx.Param("id"), aprovada, avaliacao.Observacao, professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	mensagem := "Justificativa rejeitada com sucesso"
+	if aprovada {
+		mensagem = "Justificativa aprovada com sucesso"
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		mensagem,
+		http.StatusOK,
+		result,
+	))
+}
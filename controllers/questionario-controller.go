@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/services"
+	"sistema-alunos-go/utils"
+	"sistema-alunos-go/validations"
+)
+
+// CadastrarQuestao trata a inclusão de uma questão no banco de questões de uma matéria
+//
+// Obtém o ID do professor autenticado e valida a questão enviada no corpo da requisição.
+//
+// Retorna a questão criada com status 201 ou erro em caso de falha
+func CadastrarQuestao(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	var questao models.Questao
+	if !validations.QuestaoValida(&questao, ctx) {
+		return
+	}
+
+	result, restErr := services.CadastrarQuestao(questao, professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.NewAppMessage(
+		"Questão cadastrada com sucesso",
+		http.StatusCreated,
+		result,
+	))
+}
+
+// ListarQuestoes retorna as questões do banco de uma matéria
+//
+// A matéria é informada via query string (`materia`) e o tipo pode ser filtrado com `tipo`.
+//
+// Retorna a lista de questões com status 200 ou erro em caso de falha
+func ListarQuestoes(ctx *gin.Context) {
+	result, restErr := services.ListarQuestoes(ctx.Query("materia"), ctx.Query("tipo"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Questões resgatadas com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// RemoverQuestao trata a remoção de uma questão do banco de questões pelo professor que a cadastrou
+//
+// O ID da questão é obtido via parâmetro de rota e, em caso de sucesso, retorna status 204 (No Content)
+func RemoverQuestao(ctx *gin.Context) {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return
+	}
+
+	restErr := services.RemoverQuestao(ctx.Param("id"), professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, utils.NewAppMessage(
+		"Questão removida com sucesso",
+		http.StatusNoContent,
+		nil,
+	))
+}
+
+// MontarQuestionario trata a montagem do questionário online de uma avaliação
+//
+// Os IDs da disciplina e da avaliação são passados via rota e o período, a duração e as questões, no corpo.
+//
+// Retorna o questionário com status 200 ou erro em caso de falha
+func MontarQuestionario(ctx *gin.Context) {
+	disciplinaId := ctx.Param("disciplinaId")
+	avaliacaoId := ctx.Param("avaliacaoId")
+
+	var montagem models.MontagemQuestionario
+	if !validations.MontagemQuestionarioValida(&montagem, ctx) {
+		return
+	}
+
+	result, restErr := services.MontarQuestionario(disciplinaId, avaliacaoId, montagem)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Questionário montado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GetQuestionario retorna o questionário online de uma avaliação com o gabarito
+//
+// Os IDs da disciplina e da avaliação são passados via rota.
+//
+// Retorna o questionário com status 200 ou erro em caso de falha
+func GetQuestionario(ctx *gin.Context) {
+	result, restErr := services.GetQuestionario(ctx.Param("disciplinaId"), ctx.Param("avaliacaoId"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Questionário resgatado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ListarTentativas retorna as tentativas dos alunos no questionário de uma avaliação
+//
+// Os IDs da disciplina e da avaliação são passados via rota.
+//
+// Retorna a lista de tentativas com status 200 ou erro em caso de falha
+func ListarTentativas(ctx *gin.Context) {
+	result, restErr := services.ListarTentativas(ctx.Param("disciplinaId"), ctx.Param("avaliacaoId"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Tentativas resgatadas com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// IniciarTentativa inicia ou retoma a tentativa do aluno autenticado no questionário de uma avaliação
+//
+// O ID da avaliação é passado via parâmetro de rota.
+//
+// Retorna as questões sorteadas para o aluno com status 200 ou erro em caso de falha
+func IniciarTentativa(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	result, restErr := services.IniciarTentativa(ctx.Param("avaliacaoId"), alunoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Questionário iniciado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// GetTentativaAluno retorna a tentativa do aluno autenticado no questionário de uma avaliação
+//
+// O ID da avaliação é passado via parâmetro de rota.
+//
+// Retorna as questões, as respostas e, após a finalização, a nota com status 200 ou erro em caso de falha
+func GetTentativaAluno(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	result, restErr := services.GetTentativaAluno(ctx.Param("avaliacaoId"), alunoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Questionário resgatado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ResponderQuestao grava a resposta do aluno autenticado a uma questão do questionário
+//
+// O ID da avaliação é passado via parâmetro de rota e a questão e a resposta, no corpo.
+//
+// Retorna a resposta registrada com status 200 ou erro em caso de falha
+func ResponderQuestao(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	var resposta models.RespostaQuestao
+	if !validations.RespostaQuestaoValida(&resposta, ctx) {
+		return
+	}
+
+	result, restErr := services.ResponderQuestao(ctx.Param("avaliacaoId"), alunoId, resposta)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Resposta registrada com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// FinalizarTentativa encerra a tentativa do aluno autenticado e corrige o questionário
+//
+// O ID da avaliação é passado via parâmetro de rota.
+//
+// Retorna o resultado do aluno com status 200 ou erro em caso de falha
+func FinalizarTentativa(ctx *gin.Context) {
+	alunoId := getAlunoId(ctx)
+	if alunoId == "" {
+		return
+	}
+
+	result, restErr := services.FinalizarTentativa(ctx.Param("avaliacaoId"), alunoId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Questionário finalizado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/services"
+	"sistema-alunos-go/utils"
+	"sistema-alunos-go/validations"
+)
+
+// CadastrarWebhook inscreve um sistema externo para receber eventos por webhook.
+//
+// Apenas coordenadores podem gerenciar webhooks. Recebe a URL, os eventos assinados e, opcionalmente, o segredo usado
+// na assinatura das entregas; se omitido, um segredo aleatório é gerado.
+//
+// Retorna a assinatura criada, com o segredo, que não é exibido novamente, com status 201 ou erro em caso de falha.
+func CadastrarWebhook(ctx *gin.Context) {
+	professorId := coordenadorAutenticado(ctx)
+	if professorId == "" {
+		return
+	}
+
+	var cadastro models.CadastroAssinaturaWebhook
+	if !validations.CadastroWebhookValido(&cadastro, ctx) {
+		return
+	}
+
+	result, restErr := services.CadastrarWebhook(cadastro, professorId)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.NewAppMessage(
+		"Webhook cadastrado com sucesso",
+		http.StatusCreated,
+		result,
+	))
+}
+
+// ListarWebhooks retorna as assinaturas de webhook cadastradas, sem os segredos.
+func ListarWebhooks(ctx *gin.Context) {
+	if coordenadorAutenticado(ctx) == "" {
+		return
+	}
+
+	result, restErr := services.ListarWebhooks()
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Webhooks encontrados",
+		http.StatusOK,
+		result,
+	))
+}
+
+// AtualizarWebhook altera parcialmente uma assinatura de webhook, inclusive para desativá-la ou reativá-la.
+//
+// Retorna a assinatura atualizada ou erro caso não exista.
+func AtualizarWebhook(ctx *gin.Context) {
+	if coordenadorAutenticado(ctx) == "" {
+		return
+	}
+
+	var atualizacao models.AtualizacaoAssinaturaWebhook
+	if !validations.AtualizacaoWebhookValida(&atualizacao, ctx) {
+		return
+	}
+
+	result, restErr := services.AtualizarWebhook(ctx.Param("id"), atualizacao)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Webhook atualizado com sucesso",
+		http.StatusOK,
+		result,
+	))
+}
+
+// RemoverWebhook remove uma assinatura de webhook e o registro de seus envios.
+func RemoverWebhook(ctx *gin.Context) {
+	if coordenadorAutenticado(ctx) == "" {
+		return
+	}
+
+	if restErr := services.RemoverWebhook(ctx.Param("id")); restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Webhook removido com sucesso",
+		http.StatusOK,
+		nil,
+	))
+}
+
+// ListarEnviosWebhook retorna o registro de envios de uma assinatura de webhook, com a situação e o resultado da
+// última tentativa de cada envio.
+//
+// Aceita o filtro `situacao` (pendente, entregue ou abandonado) na query string.
+func ListarEnviosWebhook(ctx *gin.Context) {
+	if coordenadorAutenticado(ctx) == "" {
+		return
+	}
+
+	situacao := ctx.Query("situacao")
+	switch situacao {
+	case "", models.EnvioPendente, models.EnvioEntregue, models.EnvioAbandonado:
+	default:
+		utils.RespondRestErr(utils.NewRestErr(http.StatusBadRequest, "Situação de envio inválida", nil), ctx)
+		return
+	}
+
+	result, restErr := services.ListarEnviosWebhook(ctx.Param("id"), situacao)
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, utils.NewAppMessage(
+		"Envios encontrados",
+		http.StatusOK,
+		result,
+	))
+}
+
+// ReenviarWebhook agenda o reenvio de um evento a uma assinatura de webhook, mantendo o ID e o corpo originais.
+//
+// Retorna o novo envio com status 201 ou erro caso a assinatura ou o envio não existam.
+func ReenviarWebhook(ctx *gin.Context) {
+	if coordenadorAutenticado(ctx) == "" {
+		return
+	}
+
+	result, restErr := services.ReenviarWebhook(ctx.Param("id"), ctx.Param("envioId"))
+
+	if restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, utils.NewAppMessage(
+		"Reenvio agendado com sucesso",
+		http.StatusCreated,
+		result,
+	))
+}
+
+// coordenadorAutenticado retorna o ID do professor autenticado, se ele for coordenador.
+//
+// Caso contrário, responde com o erro e retorna uma string vazia.
+func coordenadorAutenticado(ctx *gin.Context) string {
+	professorId := getProfessorId(ctx)
+	if professorId == "" {
+		return ""
+	}
+
+	if restErr := services.VerificarCoordenador(professorId); restErr != nil {
+		utils.RespondRestErr(restErr, ctx)
+		return ""
+	}
+	return professorId
+}
@@ -70,6 +70,8 @@ func ConectaBD() {
 //
 // As structs dos modelos são registradas em ordem de dependência. Em caso de erro, a aplicação é finalizada com log.Fatalf.
 func migrate() {
+	preparaMigracao()
+
 	err := DB.AutoMigrate(
 		&models.Professor{},
 		&models.Aluno{},
@@ -80,11 +82,232 @@ func migrate() {
 		&models.AlunoAvaliacao{},
 		&models.AlunoAula{},
 		&models.AlunoMedia{},
+		&models.AlunoAulaHistorico{},
+		&models.Justificativa{},
+		&models.JustificativaAula{},
+		&models.SessaoChamada{},
+		&models.CheckInChamada{},
+		&models.FalhasCheckIn{},
+		&models.AlertaFrequencia{},
+		&models.AlunoAvaliacaoHistorico{},
+		&models.Rubrica{},
+		&models.CriterioRubrica{},
+		&models.NivelRubrica{},
+		&models.AlunoRubrica{},
+		&models.JanelaEntrega{},
+		&models.Entrega{},
+		&models.Questao{},
+		&models.AlternativaQuestao{},
+		&models.Questionario{},
+		&models.QuestionarioQuestao{},
+		&models.Tentativa{},
+		&models.RespostaTentativa{},
+		&models.AssinaturaWebhook{},
+		&models.EnvioWebhook{},
 	)
 
 	if err != nil {
 		log.Fatalf("Erro ao realizar AutoMigrate: %v", err)
 	}
 
+	migraDados()
+
 	fmt.Println("Migrações aplicadas com sucesso.")
 }
+
+// preparaMigracao ajusta os registros já existentes que impediriam o AutoMigrate de criar novas restrições
+//
+// As atualizações são idempotentes e só são executadas se as tabelas envolvidas já existirem. Em caso de erro, a
+// aplicação é finalizada com log.Fatalf.
+func preparaMigracao() {
+	if DB.Migrator().HasTable(&models.AlunoAvaliacao{}) {
+		// Notas lançadas em duplicidade: mantém apenas a mais recente de cada aluno em cada avaliação
+		resultado := DB.Exec(`
+			DELETE FROM aluno_avaliacao a USING aluno_avaliacao b
+			WHERE a.aluno_id = b.aluno_id AND a.avaliacao_id = b.avaliacao_id
+				AND (a.updated_at < b.updated_at OR (a.updated_at = b.updated_at AND a.id < b.id))
+		`)
+		if resultado.Error != nil {
+			log.Fatalf("Erro ao remover notas duplicadas: %v", resultado.Error)
+		}
+		if resultado.RowsAffected > 0 {
+			log.Printf("Notas duplicadas removidas: %d", resultado.RowsAffected)
+		}
+	}
+
+	if DB.Migrator().HasTable(&models.Aula{}) {
+		// Aulas com número repetido na disciplina: a mais antiga mantém o número e as demais passam a ser numeradas
+		// após a última aula da disciplina
+		var renumeradas []struct {
+			Id             string
+			DisciplinaId   string
+			NumeroAnterior int
+			Numero         int
+		}
+		err := DB.Raw(`
+			UPDATE aulas SET numero = repetidas.novo_numero
+			FROM (
+				SELECT a.id, a.numero AS numero_anterior, ultimas.numero + ROW_NUMBER() OVER (
+					PARTITION BY a.disciplina_id ORDER BY a.created_at, a.id
+				) AS novo_numero
+				FROM aulas a
+				JOIN (SELECT disciplina_id, MAX(numero) AS numero FROM aulas GROUP BY disciplina_id) ultimas
+					ON ultimas.disciplina_id = a.disciplina_id
+				WHERE EXISTS (
+					SELECT 1 FROM aulas b
+					WHERE b.disciplina_id = a.disciplina_id AND b.numero = a.numero
+						AND (b.created_at < a.created_at OR (b.created_at = a.created_at AND b.id < a.id))
+				)
+			) repetidas
+			WHERE aulas.id = repetidas.id
+			RETURNING aulas.id, aulas.disciplina_id, repetidas.numero_anterior, aulas.numero
+		`).Scan(&renumeradas).Error
+		if err != nil {
+			log.Fatalf("Erro ao renumerar aulas repetidas: %v", err)
+		}
+		for _, aula := range renumeradas {
+			log.Printf("Aula %s da disciplina %s renumerada de %d para %d", aula.Id, aula.DisciplinaId,
+				aula.NumeroAnterior, aula.Numero)
+		}
+	}
+
+	if DB.Migrator().HasTable(&models.Entrega{}) {
+		// Entregas com versão repetida: as versões do aluno no trabalho são renumeradas na ordem em que foram enviadas
+		resultado := DB.Exec(`
+			UPDATE entregas SET versao = ordenadas.versao
+			FROM (
+				SELECT e.id, ROW_NUMBER() OVER (
+					PARTITION BY e.avaliacao_id, e.aluno_id ORDER BY e.versao, e.created_at, e.id
+				) AS versao
+				FROM entregas e
+				WHERE (e.avaliacao_id, e.aluno_id) IN (
+					SELECT avaliacao_id, aluno_id FROM entregas
+					GROUP BY avaliacao_id, aluno_id, versao HAVING COUNT(*) > 1
+				)
+			) ordenadas
+			WHERE entregas.id = ordenadas.id AND entregas.versao <> ordenadas.versao
+		`)
+		if resultado.Error != nil {
+			log.Fatalf("Erro ao renumerar versões de entregas: %v", resultado.Error)
+		}
+		if resultado.RowsAffected > 0 {
+			log.Printf("Entregas com versão repetida renumeradas: %d", resultado.RowsAffected)
+		}
+
+		// Alunos com mais de uma entrega atual no trabalho: apenas a última versão continua atual
+		resultado = DB.Exec(`
+			UPDATE entregas SET atual = false
+			FROM (
+				SELECT avaliacao_id, aluno_id, MAX(versao) AS versao FROM entregas
+				WHERE atual GROUP BY avaliacao_id, aluno_id HAVING COUNT(*) > 1
+			) ultimas
+			WHERE entregas.avaliacao_id = ultimas.avaliacao_id AND entregas.aluno_id = ultimas.aluno_id
+				AND entregas.atual AND entregas.versao < ultimas.versao
+		`)
+		if resultado.Error != nil {
+			log.Fatalf("Erro ao ajustar entregas atuais: %v", resultado.Error)
+		}
+		if resultado.RowsAffected > 0 {
+			log.Printf("Entregas deixaram de ser atuais: %d", resultado.RowsAffected)
+		}
+	}
+
+	if DB.Migrator().HasTable(&models.AssinaturaWebhook{}) {
+		// Assinaturas criadas com remoção em cascata: a chave é recriada pela migração, mantendo a assinatura quando o
+		// professor que a cadastrou é removido
+		err := DB.Exec(`
+			DO $$
+			BEGIN
+				IF EXISTS (
+					SELECT 1 FROM pg_constraint
+					WHERE conname = 'fk_assinaturas_webhook_professor' AND confdeltype = 'c'
+				) THEN
+					ALTER TABLE assinaturas_webhook DROP CONSTRAINT fk_assinaturas_webhook_professor;
+				END IF;
+			END $$
+		`).Error
+		if err != nil {
+			log.Fatalf("Erro ao ajustar chave estrangeira dos webhooks: %v", err)
+		}
+	}
+}
+
+// migraDados ajusta os registros já existentes às regras introduzidas após a criação das tabelas
+//
+// As atualizações são idempotentes e executadas a cada inicialização. Em caso de erro, a aplicação é finalizada com log.Fatalf.
+func migraDados() {
+	// Presenças registradas antes do controle por horas passam a valer a duração completa da aula
+	err := DB.Exec(`
+		UPDATE aluno_aula SET horas_presentes = aulas.quantidade_horas
+		FROM aulas
+		WHERE aluno_aula.aula_id = aulas.id AND aluno_aula.presenca = true AND aluno_aula.horas_presentes = 0
+	`).Error
+	if err != nil {
+		log.Fatalf("Erro ao migrar horas presentes: %v", err)
+	}
+
+	// Médias calculadas antes da recuperação: a média do semestre é a própria média final
+	err = DB.Exec(`
+		UPDATE aluno_media SET media_semestre = media_final
+		WHERE media_semestre = 0 AND media_final <> 0 AND nota_recuperacao IS NULL
+	`).Error
+	if err != nil {
+		log.Fatalf("Erro ao migrar médias do semestre: %v", err)
+	}
+
+	// Notas e médias registradas antes das escalas de notas estão na escala de 0 a 10
+	err = DB.Exec(`
+		UPDATE aluno_avaliacao SET nota_escala = CAST(ROUND(CAST(nota AS numeric), 2) AS float8)::text
+		WHERE nota_escala = ''
+	`).Error
+	if err != nil {
+		log.Fatalf("Erro ao migrar notas para a escala: %v", err)
+	}
+
+	err = DB.Exec(`
+		UPDATE aluno_media SET media_convertida = CAST(ROUND(CAST(media_final AS numeric), 2) AS float8)::text
+		WHERE media_convertida = ''
+	`).Error
+	if err != nil {
+		log.Fatalf("Erro ao migrar médias para a escala: %v", err)
+	}
+
+	// Resultados registrados apenas com o indicador de aprovação recebem a situação correspondente
+	err = DB.Exec(`
+		UPDATE aluno_media SET situacao = CASE
+			WHEN aluno_media.aprovado AND aluno_media.em_recuperacao THEN 'aprovado_recuperacao'
+			WHEN aluno_media.aprovado THEN 'aprovado'
+			WHEN aluno_media.em_recuperacao AND aluno_media.nota_recuperacao IS NULL THEN 'em_recuperacao'
+			WHEN aluno_media.em_recuperacao THEN 'reprovado_nota'
+			WHEN aluno_media.media_final < disciplinas.nota_minima
+				AND aluno_media.frequencia < disciplinas.frequencia_minima THEN 'reprovado_nota_falta'
+			WHEN aluno_media.frequencia < disciplinas.frequencia_minima THEN 'reprovado_falta'
+			ELSE 'reprovado_nota'
+		END
+		FROM disciplinas
+		WHERE aluno_media.disciplina_id = disciplinas.id AND aluno_media.situacao = ''
+	`).Error
+	if err != nil {
+		log.Fatalf("Erro ao migrar situação dos alunos: %v", err)
+	}
+
+	// Disciplinas com médias registradas antes da situação do semestre já tiveram o semestre fechado; as que ainda têm
+	// alunos aguardando a nota da recuperação continuam em recuperação
+	resultado := DB.Exec(`
+		UPDATE disciplinas SET situacao = CASE
+			WHEN EXISTS (
+				SELECT 1 FROM aluno_media
+				WHERE aluno_media.disciplina_id = disciplinas.id
+					AND aluno_media.em_recuperacao AND aluno_media.nota_recuperacao IS NULL
+			) THEN ?
+			ELSE ?
+		END
+		WHERE situacao = ? AND EXISTS (SELECT 1 FROM aluno_media WHERE aluno_media.disciplina_id = disciplinas.id)
+	`, models.DisciplinaEmRecuperacao, models.DisciplinaFechada, models.DisciplinaAberta)
+	if resultado.Error != nil {
+		log.Fatalf("Erro ao migrar situação das disciplinas: %v", resultado.Error)
+	}
+	if resultado.RowsAffected > 0 {
+		log.Printf("Disciplinas com o semestre já fechado: %d", resultado.RowsAffected)
+	}
+}
@@ -0,0 +1,286 @@
+package formula
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// valor é o resultado da avaliação de um nó: um único número ou uma lista de notas
+type valor struct {
+	escalar float64
+	notas   []float64
+	ehLista bool
+}
+
+// funcao descreve uma função disponível nas fórmulas
+//
+// maxArgs negativo indica que a função aceita qualquer quantidade de argumentos a partir de minArgs
+type funcao struct {
+	minArgs      int
+	maxArgs      int
+	retornaLista bool
+	aplica       func(nome string, pos int, args []valor, avaliacoes []Avaliacao) (valor, error)
+	limita       func(nome string, pos int, args []intervalo, avaliacoes []Avaliacao, nota intervalo) (intervalo, error)
+}
+
+// descricaoArgs descreve a quantidade de argumentos aceita pela função, para mensagens de erro
+func (f *funcao) descricaoArgs() string {
+	switch {
+	case f.maxArgs == 0:
+		return "não recebe argumentos"
+	case f.maxArgs < 0:
+		return fmt.Sprintf("requer pelo menos %d argumento(s)", f.minArgs)
+	default:
+		return fmt.Sprintf("requer entre %d e %d argumentos", f.minArgs, f.maxArgs)
+	}
+}
+
+// funcoes são as funções disponíveis, indexadas pelo nome normalizado
+var funcoes = map[string]*funcao{
+	"media":           {minArgs: 1, maxArgs: -1, aplica: reducao(mediaNotas), limita: limitaReducao(mediaNotas)},
+	"max":             {minArgs: 1, maxArgs: -1, aplica: reducao(maiorNota), limita: limitaReducao(maiorNota)},
+	"min":             {minArgs: 1, maxArgs: -1, aplica: reducao(menorNota), limita: limitaReducao(menorNota)},
+	"soma":            {minArgs: 1, maxArgs: -1, aplica: reducao(somaNotas), limita: limitaReducao(somaNotas)},
+	"descartar_menor": {minArgs: 1, maxArgs: -1, retornaLista: true, aplica: descartaMenor, limita: limitaDescartaMenor},
+	"provas":          {maxArgs: 0, retornaLista: true, aplica: notasDoTipo(TipoProva), limita: limitaNotasDoTipo(TipoProva)},
+	"trabalhos":       {maxArgs: 0, retornaLista: true, aplica: notasDoTipo(TipoTrabalho), limita: limitaNotasDoTipo(TipoTrabalho)},
+}
+
+// reducao cria uma função que junta todos os argumentos em uma lista e a reduz a um único valor
+func reducao(reduz func([]float64) float64) func(string, int, []valor, []Avaliacao) (valor, error) {
+	return func(nome string, pos int, args []valor, _ []Avaliacao) (valor, error) {
+		notas := achata(args)
+		if len(notas) == 0 {
+			return valor{}, novoErro(pos, "a função %s recebeu uma lista vazia de notas", nome)
+		}
+		return valor{escalar: reduz(notas)}, nil
+	}
+}
+
+// descartaMenor junta todos os argumentos em uma lista e remove a menor nota
+func descartaMenor(nome string, pos int, args []valor, _ []Avaliacao) (valor, error) {
+	notas := achata(args)
+	if len(notas) < 2 {
+		return valor{}, novoErro(pos, "a função %s requer pelo menos duas notas", nome)
+	}
+
+	ordenadas := append([]float64(nil), notas...)
+	sort.Float64s(ordenadas)
+	return valor{notas: ordenadas[1:], ehLista: true}, nil
+}
+
+// notasDoTipo cria uma função que devolve as notas de todas as avaliações do tipo informado
+func notasDoTipo(tipo string) func(string, int, []valor, []Avaliacao) (valor, error) {
+	return func(_ string, _ int, _ []valor, avaliacoes []Avaliacao) (valor, error) {
+		notas := []float64{}
+		for _, a := range avaliacoes {
+			if a.Tipo == tipo {
+				notas = append(notas, a.Nota)
+			}
+		}
+		return valor{notas: notas, ehLista: true}, nil
+	}
+}
+
+// achata junta números e listas de notas em uma única lista
+func achata(args []valor) []float64 {
+	var notas []float64
+	for _, a := range args {
+		if a.ehLista {
+			notas = append(notas, a.notas...)
+		} else {
+			notas = append(notas, a.escalar)
+		}
+	}
+	return notas
+}
+
+// mediaNotas retorna a média aritmética de uma lista não vazia
+func mediaNotas(notas []float64) float64 {
+	return somaNotas(notas) / float64(len(notas))
+}
+
+// somaNotas soma as notas da lista
+func somaNotas(notas []float64) float64 {
+	var soma float64
+	for _, n := range notas {
+		soma += n
+	}
+	return soma
+}
+
+// maiorNota retorna a maior nota de uma lista não vazia
+func maiorNota(notas []float64) float64 {
+	maior := notas[0]
+	for _, n := range notas[1:] {
+		maior = math.Max(maior, n)
+	}
+	return maior
+}
+
+// menorNota retorna a menor nota de uma lista não vazia
+func menorNota(notas []float64) float64 {
+	menor := notas[0]
+	for _, n := range notas[1:] {
+		menor = math.Min(menor, n)
+	}
+	return menor
+}
+
+// buscaAvaliacao localiza uma avaliação pelo nome, sem diferenciar maiúsculas de minúsculas
+func buscaAvaliacao(avaliacoes []Avaliacao, nome string, pos int) (*Avaliacao, error) {
+	var encontrada *Avaliacao
+	for i := range avaliacoes {
+		if strings.EqualFold(strings.TrimSpace(avaliacoes[i].Nome), strings.TrimSpace(nome)) {
+			if encontrada != nil {
+				return nil, novoErro(pos, "mais de uma avaliação se chama %q", nome)
+			}
+			encontrada = &avaliacoes[i]
+		}
+	}
+
+	if encontrada == nil {
+		return nil, novoErro(pos, "avaliação %q não encontrada", nome)
+	}
+	return encontrada, nil
+}
+
+// noNumero é um número literal
+type noNumero struct {
+	pos   int
+	valor float64
+}
+
+func (n *noNumero) posicao() int { return n.pos }
+
+func (n *noNumero) lista() bool { return false }
+
+func (n *noNumero) valida(_ []Avaliacao) error { return nil }
+
+func (n *noNumero) avalia(_ []Avaliacao) (valor, error) {
+	return valor{escalar: n.valor}, nil
+}
+
+// noAvaliacao é a nota de uma avaliação referenciada pelo nome
+type noAvaliacao struct {
+	pos  int
+	nome string
+}
+
+func (n *noAvaliacao) posicao() int { return n.pos }
+
+func (n *noAvaliacao) lista() bool { return false }
+
+func (n *noAvaliacao) valida(avaliacoes []Avaliacao) error {
+	_, err := buscaAvaliacao(avaliacoes, n.nome, n.pos)
+	return err
+}
+
+func (n *noAvaliacao) avalia(avaliacoes []Avaliacao) (valor, error) {
+	a, err := buscaAvaliacao(avaliacoes, n.nome, n.pos)
+	if err != nil {
+		return valor{}, err
+	}
+	return valor{escalar: a.Nota}, nil
+}
+
+// noNegacao inverte o sinal de um valor
+type noNegacao struct {
+	pos      int
+	operando no
+}
+
+func (n *noNegacao) posicao() int { return n.pos }
+
+func (n *noNegacao) lista() bool { return false }
+
+func (n *noNegacao) valida(avaliacoes []Avaliacao) error {
+	return n.operando.valida(avaliacoes)
+}
+
+func (n *noNegacao) avalia(avaliacoes []Avaliacao) (valor, error) {
+	v, err := n.operando.avalia(avaliacoes)
+	if err != nil {
+		return valor{}, err
+	}
+	return valor{escalar: -v.escalar}, nil
+}
+
+// noBinario é uma operação aritmética entre dois valores
+type noBinario struct {
+	pos      int
+	operador string
+	esquerda no
+	direita  no
+}
+
+func (n *noBinario) posicao() int { return n.pos }
+
+func (n *noBinario) lista() bool { return false }
+
+func (n *noBinario) valida(avaliacoes []Avaliacao) error {
+	if err := n.esquerda.valida(avaliacoes); err != nil {
+		return err
+	}
+	return n.direita.valida(avaliacoes)
+}
+
+func (n *noBinario) avalia(avaliacoes []Avaliacao) (valor, error) {
+	esquerda, err := n.esquerda.avalia(avaliacoes)
+	if err != nil {
+		return valor{}, err
+	}
+	direita, err := n.direita.avalia(avaliacoes)
+	if err != nil {
+		return valor{}, err
+	}
+
+	switch n.operador {
+	case "+":
+		return valor{escalar: esquerda.escalar + direita.escalar}, nil
+	case "-":
+		return valor{escalar: esquerda.escalar - direita.escalar}, nil
+	case "*":
+		return valor{escalar: esquerda.escalar * direita.escalar}, nil
+	default:
+		if direita.escalar == 0 {
+			return valor{}, novoErro(n.pos, "divisão por zero")
+		}
+		return valor{escalar: esquerda.escalar / direita.escalar}, nil
+	}
+}
+
+// noFuncao é a chamada de uma função
+type noFuncao struct {
+	pos  int
+	nome string
+	fn   *funcao
+	args []no
+}
+
+func (n *noFuncao) posicao() int { return n.pos }
+
+func (n *noFuncao) lista() bool { return n.fn.retornaLista }
+
+func (n *noFuncao) valida(avaliacoes []Avaliacao) error {
+	for _, arg := range n.args {
+		if err := arg.valida(avaliacoes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *noFuncao) avalia(avaliacoes []Avaliacao) (valor, error) {
+	args := make([]valor, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.avalia(avaliacoes)
+		if err != nil {
+			return valor{}, err
+		}
+		args = append(args, v)
+	}
+	return n.fn.aplica(n.nome, n.pos, args, avaliacoes)
+}
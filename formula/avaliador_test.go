@@ -0,0 +1,87 @@
+package formula
+
+import (
+	"math"
+	"testing"
+)
+
+// avaliacoesTeste são as avaliações usadas nos testes do avaliador
+var avaliacoesTeste = []Avaliacao{
+	{Nome: "P1", Tipo: TipoProva, Nota: 6},
+	{Nome: "P2", Tipo: TipoProva, Nota: 8},
+	{Nome: "P3", Tipo: TipoProva, Nota: 3},
+	{Nome: "Trabalho em grupo", Tipo: TipoTrabalho, Nota: 9},
+	{Nome: "T2", Tipo: TipoTrabalho, Nota: 7},
+}
+
+func TestAvalia(t *testing.T) {
+	casos := []struct {
+		texto    string
+		esperado float64
+	}{
+		{"P1", 6},
+		{"p2", 8},
+		{"(P1 + P2)/2 * 0.7 + média(trabalhos) * 0.3", 7*0.7 + 8*0.3},
+		{"media(provas())", 17.0 / 3},
+		{"media(descartar_menor(provas()))", 7},
+		{"max(provas)", 8},
+		{"min(provas, trabalhos)", 3},
+		{"soma(trabalhos)", 16},
+		{"[Trabalho em grupo] / 3", 3},
+		{"media(P1, P2, 10)", 8},
+	}
+
+	for _, c := range casos {
+		f, err := Compila(c.texto)
+		if err != nil {
+			t.Fatalf("Compila(%q) retornou erro inesperado: %v", c.texto, err)
+		}
+
+		resultado, err := f.Avalia(avaliacoesTeste)
+		if err != nil {
+			t.Fatalf("Avalia(%q) retornou erro inesperado: %v", c.texto, err)
+		}
+		if math.Abs(resultado-c.esperado) > 1e-9 {
+			t.Errorf("Avalia(%q) = %v, esperado %v", c.texto, resultado, c.esperado)
+		}
+	}
+}
+
+func TestAvaliaErros(t *testing.T) {
+	casos := []struct {
+		texto      string
+		avaliacoes []Avaliacao
+	}{
+		{"P4", avaliacoesTeste},
+		{"P1 / (P2 - 8)", avaliacoesTeste},
+		{"media(trabalhos)", avaliacoesTeste[:3]},
+		{"media(descartar_menor(trabalhos))", avaliacoesTeste[:4]},
+		{"P1", append([]Avaliacao{{Nome: "p1", Tipo: TipoProva}}, avaliacoesTeste...)},
+	}
+
+	for _, c := range casos {
+		f, err := Compila(c.texto)
+		if err != nil {
+			t.Fatalf("Compila(%q) retornou erro inesperado: %v", c.texto, err)
+		}
+
+		if _, err := f.Avalia(c.avaliacoes); err == nil {
+			t.Errorf("Avalia(%q) deveria retornar erro", c.texto)
+		}
+	}
+}
+
+func TestValida(t *testing.T) {
+	f, err := Compila("(P1 + [Trabalho em grupo]) / 2 + media(trabalhos)")
+	if err != nil {
+		t.Fatalf("Compila retornou erro inesperado: %v", err)
+	}
+
+	if err := f.Valida(avaliacoesTeste); err != nil {
+		t.Errorf("Valida retornou erro inesperado: %v", err)
+	}
+
+	if err := f.Valida(avaliacoesTeste[1:]); err == nil {
+		t.Error("Valida deveria recusar a fórmula sem a avaliação P1")
+	}
+}
@@ -0,0 +1,142 @@
+package formula
+
+import (
+	"math"
+	"sort"
+)
+
+// intervalo são os limites do valor de um nó: um único intervalo ou uma lista de intervalos, um para cada nota
+type intervalo struct {
+	menor     float64
+	maior     float64
+	elementos []intervalo
+	ehLista   bool
+}
+
+// limitaReducao cria os limites de uma função que reduz uma lista a um único valor
+//
+// As reduções disponíveis não diminuem quando uma nota aumenta, de modo que os limites são a redução dos limites
+// inferiores e a redução dos limites superiores
+func limitaReducao(reduz func([]float64) float64) func(string, int, []intervalo, []Avaliacao, intervalo) (intervalo, error) {
+	return func(nome string, pos int, args []intervalo, _ []Avaliacao, _ intervalo) (intervalo, error) {
+		menores, maiores := achataIntervalos(args)
+		if len(menores) == 0 {
+			return intervalo{}, novoErro(pos, "a função %s recebeu uma lista vazia de notas", nome)
+		}
+		return intervalo{menor: reduz(menores), maior: reduz(maiores)}, nil
+	}
+}
+
+// limitaDescartaMenor calcula os limites de descartar_menor
+//
+// A k-ésima menor nota fica entre o k-ésimo menor limite inferior e o k-ésimo menor limite superior, então os limites
+// das notas restantes são os limites ordenados sem o primeiro de cada lado
+func limitaDescartaMenor(nome string, pos int, args []intervalo, _ []Avaliacao, _ intervalo) (intervalo, error) {
+	menores, maiores := achataIntervalos(args)
+	if len(menores) < 2 {
+		return intervalo{}, novoErro(pos, "a função %s requer pelo menos duas notas", nome)
+	}
+
+	sort.Float64s(menores)
+	sort.Float64s(maiores)
+	elementos := make([]intervalo, 0, len(menores)-1)
+	for i := 1; i < len(menores); i++ {
+		elementos = append(elementos, intervalo{menor: menores[i], maior: maiores[i]})
+	}
+	return intervalo{elementos: elementos, ehLista: true}, nil
+}
+
+// limitaNotasDoTipo cria os limites de uma função que devolve as notas de todas as avaliações do tipo informado
+func limitaNotasDoTipo(tipo string) func(string, int, []intervalo, []Avaliacao, intervalo) (intervalo, error) {
+	return func(_ string, _ int, _ []intervalo, avaliacoes []Avaliacao, nota intervalo) (intervalo, error) {
+		elementos := []intervalo{}
+		for _, a := range avaliacoes {
+			if a.Tipo == tipo {
+				elementos = append(elementos, nota)
+			}
+		}
+		return intervalo{elementos: elementos, ehLista: true}, nil
+	}
+}
+
+// achataIntervalos junta os limites de números e listas de notas em listas de limites inferiores e superiores
+func achataIntervalos(args []intervalo) ([]float64, []float64) {
+	var menores, maiores []float64
+	for _, a := range args {
+		if !a.ehLista {
+			menores = append(menores, a.menor)
+			maiores = append(maiores, a.maior)
+			continue
+		}
+		for _, e := range a.elementos {
+			menores = append(menores, e.menor)
+			maiores = append(maiores, e.maior)
+		}
+	}
+	return menores, maiores
+}
+
+// limitesProduto retorna os limites do produto de dois intervalos
+func limitesProduto(a intervalo, b intervalo) intervalo {
+	produtos := []float64{a.menor * b.menor, a.menor * b.maior, a.maior * b.menor, a.maior * b.maior}
+	return intervalo{menor: menorNota(produtos), maior: maiorNota(produtos)}
+}
+
+func (n *noNumero) faixa(_ []Avaliacao, _ intervalo) (intervalo, error) {
+	return intervalo{menor: n.valor, maior: n.valor}, nil
+}
+
+func (n *noAvaliacao) faixa(avaliacoes []Avaliacao, nota intervalo) (intervalo, error) {
+	if _, err := buscaAvaliacao(avaliacoes, n.nome, n.pos); err != nil {
+		return intervalo{}, err
+	}
+	return nota, nil
+}
+
+func (n *noNegacao) faixa(avaliacoes []Avaliacao, nota intervalo) (intervalo, error) {
+	v, err := n.operando.faixa(avaliacoes, nota)
+	if err != nil {
+		return intervalo{}, err
+	}
+	return intervalo{menor: -v.maior, maior: -v.menor}, nil
+}
+
+func (n *noBinario) faixa(avaliacoes []Avaliacao, nota intervalo) (intervalo, error) {
+	esquerda, err := n.esquerda.faixa(avaliacoes, nota)
+	if err != nil {
+		return intervalo{}, err
+	}
+	direita, err := n.direita.faixa(avaliacoes, nota)
+	if err != nil {
+		return intervalo{}, err
+	}
+
+	switch n.operador {
+	case "+":
+		return intervalo{menor: esquerda.menor + direita.menor, maior: esquerda.maior + direita.maior}, nil
+	case "-":
+		return intervalo{menor: esquerda.menor - direita.maior, maior: esquerda.maior - direita.menor}, nil
+	case "*":
+		return limitesProduto(esquerda, direita), nil
+	default:
+		if direita.menor == 0 && direita.maior == 0 {
+			return intervalo{}, novoErro(n.pos, "divisão por zero")
+		}
+		if direita.menor <= 0 && direita.maior >= 0 {
+			return intervalo{menor: math.Inf(-1), maior: math.Inf(1)}, nil
+		}
+		return limitesProduto(esquerda, intervalo{menor: 1 / direita.maior, maior: 1 / direita.menor}), nil
+	}
+}
+
+func (n *noFuncao) faixa(avaliacoes []Avaliacao, nota intervalo) (intervalo, error) {
+	args := make([]intervalo, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.faixa(avaliacoes, nota)
+		if err != nil {
+			return intervalo{}, err
+		}
+		args = append(args, v)
+	}
+	return n.fn.limita(n.nome, n.pos, args, avaliacoes, nota)
+}
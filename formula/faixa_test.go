@@ -0,0 +1,66 @@
+package formula
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFaixa(t *testing.T) {
+	casos := []struct {
+		texto string
+		menor float64
+		maior float64
+	}{
+		{"P1", 0, 10},
+		{"(P1 + P2)/2 * 0.7 + média(trabalhos) * 0.3", 0, 10},
+		{"media(descartar_menor(provas()))", 0, 10},
+		{"max(provas, trabalhos)", 0, 10},
+		{"soma(provas)", 0, 30},
+		{"P1 * 2", 0, 20},
+		{"P1 - P2", -10, 10},
+		{"-P1 + 10", 0, 10},
+		{"P1 / 4", 0, 2.5},
+		{"P1 / -2", -5, 0},
+		{"max(P1, 12)", 12, 12},
+		{"soma(descartar_menor(P1, 5, 8))", 13, 18},
+		{"P1 / P2", math.Inf(-1), math.Inf(1)},
+	}
+
+	for _, c := range casos {
+		f, err := Compila(c.texto)
+		if err != nil {
+			t.Fatalf("Compila(%q) retornou erro inesperado: %v", c.texto, err)
+		}
+
+		menor, maior, err := f.Faixa(avaliacoesTeste, 0, 10)
+		if err != nil {
+			t.Fatalf("Faixa(%q) retornou erro inesperado: %v", c.texto, err)
+		}
+		if menor != c.menor && math.Abs(menor-c.menor) > 1e-9 || maior != c.maior && math.Abs(maior-c.maior) > 1e-9 {
+			t.Errorf("Faixa(%q) = %v, %v; esperado %v, %v", c.texto, menor, maior, c.menor, c.maior)
+		}
+	}
+}
+
+func TestFaixaErros(t *testing.T) {
+	casos := []struct {
+		texto      string
+		avaliacoes []Avaliacao
+	}{
+		{"P4", avaliacoesTeste},
+		{"P1 / 0", avaliacoesTeste},
+		{"media(trabalhos)", avaliacoesTeste[:3]},
+		{"media(descartar_menor(trabalhos))", avaliacoesTeste[:4]},
+	}
+
+	for _, c := range casos {
+		f, err := Compila(c.texto)
+		if err != nil {
+			t.Fatalf("Compila(%q) retornou erro inesperado: %v", c.texto, err)
+		}
+
+		if _, _, err := f.Faixa(c.avaliacoes, 0, 10); err == nil {
+			t.Errorf("Faixa(%q) deveria retornar erro", c.texto)
+		}
+	}
+}
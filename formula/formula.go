@@ -0,0 +1,137 @@
+// Package formula implementa a linguagem de expressões usada para calcular a média final de uma disciplina
+//
+// Uma fórmula combina as notas das avaliações com números, os operadores + - * / e parênteses. As avaliações são
+// referenciadas pelo nome, sem diferenciar maiúsculas de minúsculas; nomes com espaços ou símbolos devem ficar entre
+// colchetes, como em [Prova final]. As funções disponíveis são:
+//
+//   - media(...), max(...), min(...) e soma(...): reduzem as notas e listas recebidas a um único valor
+//   - descartar_menor(...): devolve a lista recebida sem a menor nota
+//   - provas() e trabalhos(): devolvem as notas de todas as provas ou de todos os trabalhos
+//
+// Por exemplo, "(P1 + P2)/2 * 0.7 + media(trabalhos) * 0.3" ou "media(descartar_menor(provas()))". Os parênteses
+// de provas e trabalhos são opcionais e acentos nos nomes das funções são ignorados, de modo que "média" equivale a
+// "media".
+package formula
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tipos de avaliação reconhecidos pelas funções provas() e trabalhos()
+const (
+	TipoProva    = "P"
+	TipoTrabalho = "T"
+)
+
+// Avaliacao representa uma avaliação disponível para a fórmula e a nota do aluno nela
+type Avaliacao struct {
+	Nome string
+	Tipo string
+	Nota float64
+}
+
+// Erro descreve um problema encontrado ao compilar, validar ou avaliar uma fórmula
+//
+// Posicao é o índice, em caracteres a partir de zero, do trecho da fórmula que causou o erro
+type Erro struct {
+	Posicao  int
+	Mensagem string
+}
+
+// Error formata o erro indicando a posição na fórmula
+func (e *Erro) Error() string {
+	return fmt.Sprintf("posição %d: %s", e.Posicao+1, e.Mensagem)
+}
+
+// novoErro cria um Erro na posição informada
+func novoErro(posicao int, formato string, args ...interface{}) *Erro {
+	return &Erro{Posicao: posicao, Mensagem: fmt.Sprintf(formato, args...)}
+}
+
+// Formula é uma expressão compilada, pronta para ser validada e avaliada
+type Formula struct {
+	texto string
+	raiz  no
+}
+
+// Compila analisa o texto de uma fórmula
+//
+// Verifica a sintaxe, as funções usadas, a quantidade de argumentos e se listas de notas só são usadas onde uma
+// função as aceita. Os nomes das avaliações só são verificados por Valida ou Avalia
+//
+// Retorna a fórmula compilada ou um *Erro indicando o problema
+func Compila(texto string) (*Formula, error) {
+	tokens, err := tokeniza(texto)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	raiz, err := p.expressao()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.atual(); t.tipo != tokenFim {
+		return nil, novoErro(t.posicao, "trecho inesperado %q", t.texto)
+	}
+
+	if raiz.lista() {
+		return nil, novoErro(raiz.posicao(), "a fórmula deve resultar em um único valor, não em uma lista de notas")
+	}
+
+	return &Formula{texto: texto, raiz: raiz}, nil
+}
+
+// String retorna o texto original da fórmula
+func (f *Formula) String() string {
+	return f.texto
+}
+
+// Valida verifica se todas as avaliações referenciadas pela fórmula existem, sem ambiguidade, na lista informada
+func (f *Formula) Valida(avaliacoes []Avaliacao) error {
+	return f.raiz.valida(avaliacoes)
+}
+
+// Avalia calcula o resultado da fórmula com as notas das avaliações informadas
+//
+// Retorna um *Erro se uma avaliação referenciada não existir, se uma função receber uma lista vazia ou se houver
+// divisão por zero
+func (f *Formula) Avalia(avaliacoes []Avaliacao) (float64, error) {
+	v, err := f.raiz.avalia(avaliacoes)
+	if err != nil {
+		return 0, err
+	}
+	return v.escalar, nil
+}
+
+// Faixa calcula os limites do resultado da fórmula quando cada nota varia entre `menor` e `maior`
+//
+// Os limites são obtidos por aritmética de intervalos e podem ser mais largos que os valores realmente alcançáveis
+// quando uma mesma avaliação aparece mais de uma vez na fórmula. Uma divisão por um valor que pode ser zero resulta
+// em limites infinitos ou NaN. As notas informadas em `avaliacoes` são ignoradas
+//
+// Retorna os limites inferior e superior ou um *Erro nas mesmas situações de Avalia
+func (f *Formula) Faixa(avaliacoes []Avaliacao, menor float64, maior float64) (float64, float64, error) {
+	v, err := f.raiz.faixa(avaliacoes, intervalo{menor: menor, maior: maior})
+	if err != nil {
+		return 0, 0, err
+	}
+	return v.menor, v.maior, nil
+}
+
+// normalizaNome converte um nome de função para minúsculas e sem acentos
+func normalizaNome(nome string) string {
+	return semAcentos.Replace(strings.ToLower(nome))
+}
+
+// semAcentos remove os acentos usados em português
+var semAcentos = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ü", "u",
+	"ç", "c",
+)
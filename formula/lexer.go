@@ -0,0 +1,97 @@
+package formula
+
+import (
+	"strconv"
+	"unicode"
+)
+
+// tipoToken identifica a categoria de um token da fórmula
+type tipoToken int
+
+const (
+	tokenFim tipoToken = iota
+	tokenNumero
+	tokenNome
+	tokenNomeEntreColchetes
+	tokenOperador
+	tokenAbreParentese
+	tokenFechaParentese
+	tokenVirgula
+)
+
+// token é um trecho da fórmula reconhecido pelo analisador léxico
+type token struct {
+	tipo    tipoToken
+	texto   string
+	numero  float64
+	posicao int
+}
+
+// tokeniza divide o texto da fórmula em tokens, terminando sempre com um token de fim
+func tokeniza(texto string) ([]token, error) {
+	runas := []rune(texto)
+	var tokens []token
+
+	for i := 0; i < len(runas); {
+		r := runas[i]
+		inicio := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runas) && unicode.IsDigit(runas[i+1])):
+			for i < len(runas) && (unicode.IsDigit(runas[i]) || runas[i] == '.') {
+				i++
+			}
+			literal := string(runas[inicio:i])
+			numero, err := strconv.ParseFloat(literal, 64)
+			if err != nil {
+				return nil, novoErro(inicio, "número inválido %q", literal)
+			}
+			tokens = append(tokens, token{tipo: tokenNumero, texto: literal, numero: numero, posicao: inicio})
+
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runas) && (unicode.IsLetter(runas[i]) || unicode.IsDigit(runas[i]) || runas[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, token{tipo: tokenNome, texto: string(runas[inicio:i]), posicao: inicio})
+
+		case r == '[':
+			i++
+			for i < len(runas) && runas[i] != ']' {
+				i++
+			}
+			if i == len(runas) {
+				return nil, novoErro(inicio, "colchete sem fechamento")
+			}
+			nome := string(runas[inicio+1 : i])
+			if len([]rune(nome)) == 0 {
+				return nil, novoErro(inicio, "nome de avaliação vazio entre colchetes")
+			}
+			i++
+			tokens = append(tokens, token{tipo: tokenNomeEntreColchetes, texto: nome, posicao: inicio})
+
+		case r == '+' || r == '-' || r == '*' || r == '/':
+			i++
+			tokens = append(tokens, token{tipo: tokenOperador, texto: string(r), posicao: inicio})
+
+		case r == '(':
+			i++
+			tokens = append(tokens, token{tipo: tokenAbreParentese, texto: "(", posicao: inicio})
+
+		case r == ')':
+			i++
+			tokens = append(tokens, token{tipo: tokenFechaParentese, texto: ")", posicao: inicio})
+
+		case r == ',':
+			i++
+			tokens = append(tokens, token{tipo: tokenVirgula, texto: ",", posicao: inicio})
+
+		default:
+			return nil, novoErro(inicio, "caractere inesperado %q", r)
+		}
+	}
+
+	return append(tokens, token{tipo: tokenFim, posicao: len(runas)}), nil
+}
@@ -0,0 +1,192 @@
+package formula
+
+// no é um nó da árvore sintática de uma fórmula
+type no interface {
+	// posicao retorna a posição do nó no texto da fórmula
+	posicao() int
+	// lista indica se o nó resulta em uma lista de notas em vez de um único valor
+	lista() bool
+	// valida verifica se as avaliações referenciadas pelo nó existem
+	valida(avaliacoes []Avaliacao) error
+	// avalia calcula o valor do nó
+	avalia(avaliacoes []Avaliacao) (valor, error)
+	// faixa calcula os limites do valor do nó quando cada nota varia dentro de `nota`
+	faixa(avaliacoes []Avaliacao, nota intervalo) (intervalo, error)
+}
+
+// parser é um analisador descendente recursivo sobre a lista de tokens da fórmula
+//
+// A gramática, em ordem crescente de precedência, é:
+//
+//	expressao = termo { ("+" | "-") termo }
+//	termo     = unario { ("*" | "/") unario }
+//	unario    = "-" unario | primario
+//	primario  = numero | nome [ "(" [ expressao { "," expressao } ] ")" ] | "[" nome "]" | "(" expressao ")"
+type parser struct {
+	tokens []token
+	indice int
+}
+
+// atual retorna o token ainda não consumido
+func (p *parser) atual() token {
+	return p.tokens[p.indice]
+}
+
+// avanca consome o token atual e o retorna
+func (p *parser) avanca() token {
+	t := p.tokens[p.indice]
+	if t.tipo != tokenFim {
+		p.indice++
+	}
+	return t
+}
+
+// expressao reconhece somas e subtrações
+func (p *parser) expressao() (no, error) {
+	esquerda, err := p.termo()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.atual(); t.tipo == tokenOperador && (t.texto == "+" || t.texto == "-"); t = p.atual() {
+		p.avanca()
+		direita, err := p.termo()
+		if err != nil {
+			return nil, err
+		}
+		if esquerda, err = novoBinario(t, esquerda, direita); err != nil {
+			return nil, err
+		}
+	}
+
+	return esquerda, nil
+}
+
+// termo reconhece multiplicações e divisões
+func (p *parser) termo() (no, error) {
+	esquerda, err := p.unario()
+	if err != nil {
+		return nil, err
+	}
+
+	for t := p.atual(); t.tipo == tokenOperador && (t.texto == "*" || t.texto == "/"); t = p.atual() {
+		p.avanca()
+		direita, err := p.unario()
+		if err != nil {
+			return nil, err
+		}
+		if esquerda, err = novoBinario(t, esquerda, direita); err != nil {
+			return nil, err
+		}
+	}
+
+	return esquerda, nil
+}
+
+// unario reconhece a negação
+func (p *parser) unario() (no, error) {
+	if t := p.atual(); t.tipo == tokenOperador && t.texto == "-" {
+		p.avanca()
+		operando, err := p.unario()
+		if err != nil {
+			return nil, err
+		}
+		if operando.lista() {
+			return nil, erroListaEmOperacao(operando)
+		}
+		return &noNegacao{pos: t.posicao, operando: operando}, nil
+	}
+
+	return p.primario()
+}
+
+// primario reconhece números, avaliações, chamadas de função e expressões entre parênteses
+func (p *parser) primario() (no, error) {
+	t := p.avanca()
+
+	switch t.tipo {
+	case tokenNumero:
+		return &noNumero{pos: t.posicao, valor: t.numero}, nil
+
+	case tokenNomeEntreColchetes:
+		return &noAvaliacao{pos: t.posicao, nome: t.texto}, nil
+
+	case tokenNome:
+		if p.atual().tipo == tokenAbreParentese {
+			return p.chamada(t)
+		}
+		if fn, ok := funcoes[normalizaNome(t.texto)]; ok && fn.maxArgs == 0 {
+			return &noFuncao{pos: t.posicao, nome: t.texto, fn: fn}, nil
+		}
+		return &noAvaliacao{pos: t.posicao, nome: t.texto}, nil
+
+	case tokenAbreParentese:
+		interna, err := p.expressao()
+		if err != nil {
+			return nil, err
+		}
+		if fecha := p.avanca(); fecha.tipo != tokenFechaParentese {
+			return nil, novoErro(fecha.posicao, "esperado \")\"")
+		}
+		return interna, nil
+
+	case tokenFim:
+		return nil, novoErro(t.posicao, "fim inesperado da fórmula")
+
+	default:
+		return nil, novoErro(t.posicao, "trecho inesperado %q", t.texto)
+	}
+}
+
+// chamada reconhece a lista de argumentos de uma função cujo nome já foi consumido
+func (p *parser) chamada(nome token) (no, error) {
+	fn, ok := funcoes[normalizaNome(nome.texto)]
+	if !ok {
+		return nil, novoErro(nome.posicao, "função desconhecida %q", nome.texto)
+	}
+
+	p.avanca() // "("
+
+	var args []no
+	if p.atual().tipo != tokenFechaParentese {
+		for {
+			arg, err := p.expressao()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			if p.atual().tipo != tokenVirgula {
+				break
+			}
+			p.avanca()
+		}
+	}
+
+	if fecha := p.avanca(); fecha.tipo != tokenFechaParentese {
+		return nil, novoErro(fecha.posicao, "esperado \")\" ao final dos argumentos de %s", nome.texto)
+	}
+
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, novoErro(nome.posicao, "a função %s %s", nome.texto, fn.descricaoArgs())
+	}
+
+	return &noFuncao{pos: nome.posicao, nome: nome.texto, fn: fn, args: args}, nil
+}
+
+// novoBinario cria o nó de uma operação aritmética, recusando listas de notas como operandos
+func novoBinario(operador token, esquerda no, direita no) (no, error) {
+	if esquerda.lista() {
+		return nil, erroListaEmOperacao(esquerda)
+	}
+	if direita.lista() {
+		return nil, erroListaEmOperacao(direita)
+	}
+
+	return &noBinario{pos: operador.posicao, operador: operador.texto, esquerda: esquerda, direita: direita}, nil
+}
+
+// erroListaEmOperacao indica que uma lista de notas foi usada onde era esperado um único valor
+func erroListaEmOperacao(n no) error {
+	return novoErro(n.posicao(), "listas de notas só podem ser usadas como argumento de media, max, min, soma ou descartar_menor")
+}
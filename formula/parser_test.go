@@ -0,0 +1,96 @@
+package formula
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompilaFormulasValidas(t *testing.T) {
+	formulas := []string{
+		"P1",
+		"(P1 + P2)/2 * 0.7 + média(trabalhos) * 0.3",
+		"media(descartar_menor(provas()))",
+		"max(P1, P2) - -1",
+		"[Prova final] * 0.5 + [Trabalho em grupo] * .5",
+		"soma(provas, trabalhos()) / 4",
+		"MIN(p1, 10)",
+		"  ( ( P1 ) )  ",
+	}
+
+	for _, texto := range formulas {
+		if _, err := Compila(texto); err != nil {
+			t.Errorf("Compila(%q) retornou erro inesperado: %v", texto, err)
+		}
+	}
+}
+
+func TestCompilaFormulasInvalidas(t *testing.T) {
+	casos := []struct {
+		texto   string
+		posicao int
+	}{
+		{"", 0},
+		{"P1 +", 4},
+		{"P1 P2", 3},
+		{"(P1 + P2", 8},
+		{"P1 + )", 5},
+		{"P1 # 2", 3},
+		{"[Prova final", 0},
+		{"[] + P1", 0},
+		{"mediana(P1)", 0},
+		{"media()", 0},
+		{"provas(P1)", 0},
+		{"media(P1, P2", 12},
+		{"provas()", 0},
+		{"provas * 2", 0},
+		{"P1 + descartar_menor(P1, P2)", 5},
+		{"-trabalhos", 1},
+		{"1.2.3", 0},
+	}
+
+	for _, c := range casos {
+		_, err := Compila(c.texto)
+		if err == nil {
+			t.Errorf("Compila(%q) deveria retornar erro", c.texto)
+			continue
+		}
+
+		var erro *Erro
+		if !errors.As(err, &erro) {
+			t.Errorf("Compila(%q) retornou erro de tipo inesperado: %T", c.texto, err)
+			continue
+		}
+		if erro.Posicao != c.posicao {
+			t.Errorf("Compila(%q) indicou a posição %d, esperado %d (%v)", c.texto, erro.Posicao, c.posicao, err)
+		}
+	}
+}
+
+func TestCompilaPrecedencia(t *testing.T) {
+	casos := []struct {
+		texto    string
+		esperado float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"12 / 3 / 2", 2},
+		{"-2 * 3 + 10", 4},
+		{"2 - -2", 4},
+	}
+
+	for _, c := range casos {
+		f, err := Compila(c.texto)
+		if err != nil {
+			t.Fatalf("Compila(%q) retornou erro inesperado: %v", c.texto, err)
+		}
+
+		resultado, err := f.Avalia(nil)
+		if err != nil {
+			t.Fatalf("Avalia(%q) retornou erro inesperado: %v", c.texto, err)
+		}
+		if resultado != c.esperado {
+			t.Errorf("Avalia(%q) = %v, esperado %v", c.texto, resultado, c.esperado)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// Package legado lê os arquivos de dados do antigo sistema de gerenciamento de alunos em C
+//
+// O programa antigo gravava cada cadastro em um arquivo texto, com registros de largura fixa ou campos separados por
+// um delimitador. Como o formato variou entre as instalações, a posição de cada campo é descrita por um layout em
+// JSON. O pacote apenas extrai os campos de cada registro; o mapeamento para os modelos da API fica a cargo de quem o
+// usa
+package legado
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Tipos de arquivo do sistema antigo, usados como chave em Layout.Arquivos
+const (
+	ArquivoAlunos      = "alunos"
+	ArquivoDisciplinas = "disciplinas"
+	ArquivoAvaliacoes  = "avaliacoes"
+	ArquivoNotas       = "notas"
+)
+
+// Codificações aceitas nos arquivos do sistema antigo
+const (
+	CodificacaoLatin1 = "latin1"
+	CodificacaoUtf8   = "utf-8"
+)
+
+// CamposObrigatorios são os campos que o layout de cada tipo de arquivo deve descrever
+var CamposObrigatorios = map[string][]string{
+	ArquivoAlunos:      {"matricula", "nome"},
+	ArquivoDisciplinas: {"codigo", "nome", "ano_semestre"},
+	ArquivoAvaliacoes:  {"disciplina", "codigo", "data", "peso"},
+	ArquivoNotas:       {"aluno", "disciplina", "avaliacao", "nota"},
+}
+
+// Campo descreve a posição de um campo nos registros de um arquivo
+//
+// Em arquivos de largura fixa, o campo ocupa Tamanho caracteres a partir de Inicio, contado a partir de 1; em arquivos
+// delimitados, o campo é a Coluna informada, também contada a partir de 1. Padrao é o valor usado quando o campo está
+// em branco; um campo que só tem Padrao não é lido do arquivo
+type Campo struct {
+	Inicio  int    `json:"inicio,omitempty"`
+	Tamanho int    `json:"tamanho,omitempty"`
+	Coluna  int    `json:"coluna,omitempty"`
+	Padrao  string `json:"padrao,omitempty"`
+}
+
+// Filtro seleciona os registros de um arquivo pelo valor de um campo
+//
+// É usado quando o sistema antigo gravava vários tipos de registro no mesmo arquivo, identificados por um campo de tipo
+type Filtro struct {
+	Campo
+	Valor string `json:"valor"`
+}
+
+// Arquivo descreve o layout de um arquivo do sistema antigo
+//
+// Sem Separador, os registros têm largura fixa. IgnorarLinhas descarta as primeiras linhas do arquivo, como
+// cabeçalhos, e Tipo, se informado, seleciona apenas os registros com o valor indicado
+type Arquivo struct {
+	Arquivo       string           `json:"arquivo"`
+	Separador     string           `json:"separador,omitempty"`
+	IgnorarLinhas int              `json:"ignorar_linhas,omitempty"`
+	Tipo          *Filtro          `json:"tipo,omitempty"`
+	Campos        map[string]Campo `json:"campos"`
+}
+
+// Layout descreve os arquivos de dados de uma instalação do sistema antigo
+//
+// Codificacao é "latin1" (padrão, usada pelo programa em C) ou "utf-8". DominioEmail é o domínio usado para montar o
+// e-mail dos alunos que não têm e-mail no arquivo, a partir da matrícula
+type Layout struct {
+	Codificacao  string             `json:"codificacao,omitempty"`
+	DominioEmail string             `json:"dominio_email"`
+	Arquivos     map[string]Arquivo `json:"arquivos"`
+}
+
+// Registro representa um registro lido de um arquivo do sistema antigo, com o valor de cada campo do layout
+type Registro struct {
+	Arquivo string
+	Linha   int
+	Campos  map[string]string
+}
+
+// Dados reúne os registros lidos de cada tipo de arquivo
+type Dados struct {
+	DominioEmail string
+	Registros    map[string][]Registro
+}
+
+// LeLayout lê e valida um layout em JSON
+//
+// Retorna erro se o JSON for inválido, se algum tipo de arquivo for desconhecido, se faltar algum campo obrigatório ou
+// se a posição de algum campo for inválida
+func LeLayout(origem io.Reader) (*Layout, error) {
+	decodificador := json.NewDecoder(origem)
+	decodificador.DisallowUnknownFields()
+
+	var layout Layout
+	if err := decodificador.Decode(&layout); err != nil {
+		return nil, fmt.Errorf("layout inválido: %w", err)
+	}
+
+	if layout.Codificacao == "" {
+		layout.Codificacao = CodificacaoLatin1
+	}
+	if layout.Codificacao != CodificacaoLatin1 && layout.Codificacao != CodificacaoUtf8 {
+		return nil, fmt.Errorf("codificação %q não suportada; use %q ou %q", layout.Codificacao, CodificacaoLatin1, CodificacaoUtf8)
+	}
+	if layout.DominioEmail == "" {
+		return nil, errors.New("o layout deve informar o dominio_email dos alunos sem e-mail")
+	}
+	if len(layout.Arquivos) == 0 {
+		return nil, errors.New("o layout não descreve nenhum arquivo")
+	}
+
+	for tipo, arquivo := range layout.Arquivos {
+		obrigatorios, ok := CamposObrigatorios[tipo]
+		if !ok {
+			return nil, fmt.Errorf("tipo de arquivo %q desconhecido", tipo)
+		}
+		if arquivo.Arquivo == "" {
+			return nil, fmt.Errorf("%s: o nome do arquivo é obrigatório", tipo)
+		}
+		for _, nome := range obrigatorios {
+			if _, ok := arquivo.Campos[nome]; !ok {
+				return nil, fmt.Errorf("%s: o campo %q é obrigatório", tipo, nome)
+			}
+		}
+		for nome, campo := range arquivo.Campos {
+			if err := arquivo.validaCampo(campo); err != nil {
+				return nil, fmt.Errorf("%s: campo %q: %w", tipo, nome, err)
+			}
+		}
+		if arquivo.Tipo != nil {
+			if err := arquivo.validaCampo(arquivo.Tipo.Campo); err != nil {
+				return nil, fmt.Errorf("%s: filtro de tipo: %w", tipo, err)
+			}
+		}
+	}
+
+	return &layout, nil
+}
+
+// Carrega lê todos os arquivos descritos no layout a partir do diretório informado
+//
+// Retorna os registros de cada tipo de arquivo ou erro caso algum arquivo não possa ser lido
+func (l *Layout) Carrega(diretorio string) (*Dados, error) {
+	dados := &Dados{DominioEmail: l.DominioEmail, Registros: make(map[string][]Registro, len(l.Arquivos))}
+
+	tipos := make([]string, 0, len(l.Arquivos))
+	for tipo := range l.Arquivos {
+		tipos = append(tipos, tipo)
+	}
+	sort.Strings(tipos)
+
+	for _, tipo := range tipos {
+		arquivo := l.Arquivos[tipo]
+		conteudo, err := os.Open(filepath.Join(diretorio, arquivo.Arquivo))
+		if err != nil {
+			return nil, err
+		}
+
+		registros, err := l.Le(tipo, conteudo)
+		conteudo.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arquivo.Arquivo, err)
+		}
+		dados.Registros[tipo] = registros
+	}
+
+	return dados, nil
+}
+
+// Le lê os registros de um tipo de arquivo do layout
+//
+// Linhas em branco, as linhas iniciais ignoradas e os registros de outros tipos são descartados. Os valores são
+// retornados sem espaços e caracteres nulos nas pontas, com o padrão do campo quando em branco. Campos além do fim
+// da linha são considerados em branco, pois o programa antigo nem sempre completava os registros
+func (l *Layout) Le(tipo string, origem io.Reader) ([]Registro, error) {
+	arquivo, ok := l.Arquivos[tipo]
+	if !ok {
+		return nil, fmt.Errorf("tipo de arquivo %q não descrito no layout", tipo)
+	}
+
+	linhas := bufio.NewScanner(origem)
+	linhas.Buffer(make([]byte, 64<<10), 1<<20)
+
+	var registros []Registro
+	numero := 0
+	for linhas.Scan() {
+		numero++
+		if numero <= arquivo.IgnorarLinhas {
+			continue
+		}
+
+		linha := l.decodifica(linhas.Bytes())
+		linha = strings.TrimRight(linha, "\r")
+		if strings.TrimSpace(linha) == "" {
+			continue
+		}
+
+		var colunas []string
+		if arquivo.Separador != "" {
+			colunas = strings.Split(linha, arquivo.Separador)
+		}
+
+		if arquivo.Tipo != nil && extrai(linha, colunas, arquivo.Tipo.Campo) != arquivo.Tipo.Valor {
+			continue
+		}
+
+		registro := Registro{Arquivo: arquivo.Arquivo, Linha: numero, Campos: make(map[string]string, len(arquivo.Campos))}
+		for nome, campo := range arquivo.Campos {
+			registro.Campos[nome] = extrai(linha, colunas, campo)
+		}
+		registros = append(registros, registro)
+	}
+
+	if err := linhas.Err(); err != nil {
+		return nil, err
+	}
+	return registros, nil
+}
+
+// validaCampo verifica se a posição de um campo é compatível com o formato do arquivo
+func (a *Arquivo) validaCampo(campo Campo) error {
+	if campo.Inicio == 0 && campo.Tamanho == 0 && campo.Coluna == 0 {
+		if campo.Padrao == "" {
+			return errors.New("informe a posição ou o valor padrão")
+		}
+		return nil
+	}
+
+	if a.Separador != "" {
+		if campo.Coluna < 1 || campo.Inicio != 0 || campo.Tamanho != 0 {
+			return errors.New("em arquivos delimitados, informe apenas a coluna, a partir de 1")
+		}
+		return nil
+	}
+
+	if campo.Inicio < 1 || campo.Tamanho < 1 || campo.Coluna != 0 {
+		return errors.New("em arquivos de largura fixa, informe o início, a partir de 1, e o tamanho")
+	}
+	return nil
+}
+
+// decodifica converte uma linha do arquivo para texto na codificação do layout
+func (l *Layout) decodifica(linha []byte) string {
+	if l.Codificacao == CodificacaoUtf8 {
+		return string(linha)
+	}
+
+	// No Latin-1, cada byte corresponde ao caractere Unicode de mesmo código
+	texto := make([]rune, len(linha))
+	for i, b := range linha {
+		texto[i] = rune(b)
+	}
+	return string(texto)
+}
+
+// extrai retorna o valor de um campo em uma linha, ou o padrão do campo se ele estiver em branco
+//
+// As posições de largura fixa são contadas em caracteres, não em bytes
+func extrai(linha string, colunas []string, campo Campo) string {
+	var valor string
+	switch {
+	case campo.Coluna > 0:
+		if campo.Coluna <= len(colunas) {
+			valor = colunas[campo.Coluna-1]
+		}
+	case campo.Inicio > 0:
+		caracteres := []rune(linha)
+		if len(caracteres) >= campo.Inicio {
+			fim := min(campo.Inicio-1+campo.Tamanho, len(caracteres))
+			valor = string(caracteres[campo.Inicio-1 : fim])
+		}
+	}
+
+	valor = strings.Trim(valor, " \t\x00")
+	if valor == "" {
+		return campo.Padrao
+	}
+	return valor
+}
@@ -0,0 +1,265 @@
+package legado
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// layoutTeste monta um layout com um arquivo de largura fixa e um delimitado para os testes de leitura
+func layoutTeste(codificacao string) *Layout {
+	return &Layout{
+		Codificacao:  codificacao,
+		DominioEmail: "legado.escola.br",
+		Arquivos: map[string]Arquivo{
+			ArquivoAlunos: {
+				Arquivo: "ALUNOS.DAT",
+				Tipo:    &Filtro{Campo: Campo{Inicio: 1, Tamanho: 1}, Valor: "A"},
+				Campos: map[string]Campo{
+					"matricula": {Inicio: 2, Tamanho: 5},
+					"nome":      {Inicio: 7, Tamanho: 10},
+					"ativo":     {Inicio: 17, Tamanho: 1, Padrao: "S"},
+				},
+			},
+			ArquivoNotas: {
+				Arquivo:       "NOTAS.TXT",
+				Separador:     ";",
+				IgnorarLinhas: 1,
+				Campos: map[string]Campo{
+					"aluno":      {Coluna: 1},
+					"disciplina": {Coluna: 2},
+					"avaliacao":  {Coluna: 3},
+					"nota":       {Coluna: 4, Padrao: "0"},
+				},
+			},
+		},
+	}
+}
+
+func TestLeLayout(t *testing.T) {
+	casos := []struct {
+		nome   string
+		layout string
+		erro   string
+	}{
+		{
+			nome:   "válido com codificação padrão",
+			layout: `{"dominio_email": "escola.br", "arquivos": {"alunos": {"arquivo": "A.DAT", "campos": {"matricula": {"inicio": 1, "tamanho": 5}, "nome": {"inicio": 6, "tamanho": 30}}}}}`,
+		},
+		{
+			nome:   "campo apenas com valor padrão",
+			layout: `{"dominio_email": "escola.br", "arquivos": {"alunos": {"arquivo": "A.TXT", "separador": ";", "campos": {"matricula": {"coluna": 1}, "nome": {"coluna": 2}, "ativo": {"padrao": "S"}}}}}`,
+		},
+		{
+			nome:   "JSON inválido",
+			layout: `{"dominio_email": `,
+			erro:   "layout inválido",
+		},
+		{
+			nome:   "campo desconhecido no layout",
+			layout: `{"dominio_email": "escola.br", "separador": ";", "arquivos": {}}`,
+			erro:   "layout inválido",
+		},
+		{
+			nome:   "codificação não suportada",
+			layout: `{"codificacao": "cp850", "dominio_email": "escola.br", "arquivos": {"alunos": {}}}`,
+			erro:   "codificação",
+		},
+		{
+			nome:   "sem domínio de e-mail",
+			layout: `{"arquivos": {"alunos": {}}}`,
+			erro:   "dominio_email",
+		},
+		{
+			nome:   "sem arquivos",
+			layout: `{"dominio_email": "escola.br", "arquivos": {}}`,
+			erro:   "nenhum arquivo",
+		},
+		{
+			nome:   "tipo de arquivo desconhecido",
+			layout: `{"dominio_email": "escola.br", "arquivos": {"professores": {"arquivo": "P.DAT", "campos": {}}}}`,
+			erro:   "desconhecido",
+		},
+		{
+			nome:   "sem nome de arquivo",
+			layout: `{"dominio_email": "escola.br", "arquivos": {"alunos": {"campos": {"matricula": {"inicio": 1, "tamanho": 5}, "nome": {"inicio": 6, "tamanho": 30}}}}}`,
+			erro:   "nome do arquivo",
+		},
+		{
+			nome:   "campo obrigatório ausente",
+			layout: `{"dominio_email": "escola.br", "arquivos": {"alunos": {"arquivo": "A.DAT", "campos": {"matricula": {"inicio": 1, "tamanho": 5}}}}}`,
+			erro:   `"nome" é obrigatório`,
+		},
+		{
+			nome:   "coluna em arquivo de largura fixa",
+			layout: `{"dominio_email": "escola.br", "arquivos": {"alunos": {"arquivo": "A.DAT", "campos": {"matricula": {"coluna": 1}, "nome": {"inicio": 6, "tamanho": 30}}}}}`,
+			erro:   "largura fixa",
+		},
+		{
+			nome:   "posição em arquivo delimitado",
+			layout: `{"dominio_email": "escola.br", "arquivos": {"alunos": {"arquivo": "A.TXT", "separador": ";", "campos": {"matricula": {"coluna": 1}, "nome": {"inicio": 6, "tamanho": 30}}}}}`,
+			erro:   "delimitados",
+		},
+		{
+			nome:   "campo sem posição nem padrão",
+			layout: `{"dominio_email": "escola.br", "arquivos": {"alunos": {"arquivo": "A.DAT", "campos": {"matricula": {"inicio": 1, "tamanho": 5}, "nome": {}}}}}`,
+			erro:   "posição ou o valor padrão",
+		},
+		{
+			nome:   "filtro de tipo inválido",
+			layout: `{"dominio_email": "escola.br", "arquivos": {"alunos": {"arquivo": "A.DAT", "tipo": {"inicio": 1, "valor": "A"}, "campos": {"matricula": {"inicio": 2, "tamanho": 5}, "nome": {"inicio": 7, "tamanho": 30}}}}}`,
+			erro:   "filtro de tipo",
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			layout, err := LeLayout(strings.NewReader(caso.layout))
+			if caso.erro == "" {
+				if err != nil {
+					t.Fatalf("LeLayout retornou erro: %v", err)
+				}
+				if layout.Codificacao != CodificacaoLatin1 {
+					t.Errorf("codificação = %q, esperado %q", layout.Codificacao, CodificacaoLatin1)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("LeLayout não retornou erro; esperado erro com %q", caso.erro)
+			}
+			if !strings.Contains(err.Error(), caso.erro) {
+				t.Errorf("erro = %q, esperado contendo %q", err, caso.erro)
+			}
+		})
+	}
+}
+
+func TestLayoutExemplo(t *testing.T) {
+	arquivo, err := os.Open("layout-exemplo.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer arquivo.Close()
+
+	layout, err := LeLayout(arquivo)
+	if err != nil {
+		t.Fatalf("o layout de exemplo é inválido: %v", err)
+	}
+	for tipo := range CamposObrigatorios {
+		if _, ok := layout.Arquivos[tipo]; !ok {
+			t.Errorf("o layout de exemplo não descreve o arquivo %q", tipo)
+		}
+	}
+}
+
+func TestLe(t *testing.T) {
+	casos := []struct {
+		nome        string
+		codificacao string
+		tipo        string
+		conteudo    string
+		esperados   []Registro
+	}{
+		{
+			nome:        "largura fixa em Latin-1",
+			codificacao: CodificacaoLatin1,
+			tipo:        ArquivoAlunos,
+			// "Jos\xe9" é "José" em Latin-1: o acento ocupa um byte e uma posição
+			conteudo: "A00001Jos\xe9 SilvaN\r\n",
+			esperados: []Registro{
+				{Arquivo: "ALUNOS.DAT", Linha: 1, Campos: map[string]string{"matricula": "00001", "nome": "José Silva", "ativo": "N"}},
+			},
+		},
+		{
+			nome:        "largura fixa em UTF-8 conta caracteres, não bytes",
+			codificacao: CodificacaoUtf8,
+			tipo:        ArquivoAlunos,
+			conteudo:    "A00002Conceição S\n",
+			esperados: []Registro{
+				{Arquivo: "ALUNOS.DAT", Linha: 1, Campos: map[string]string{"matricula": "00002", "nome": "Conceição", "ativo": "S"}},
+			},
+		},
+		{
+			nome:        "linha curta usa o padrão e deixa em branco os campos ausentes",
+			codificacao: CodificacaoLatin1,
+			tipo:        ArquivoAlunos,
+			conteudo:    "A00003Ana\nA00004\n",
+			esperados: []Registro{
+				{Arquivo: "ALUNOS.DAT", Linha: 1, Campos: map[string]string{"matricula": "00003", "nome": "Ana", "ativo": "S"}},
+				{Arquivo: "ALUNOS.DAT", Linha: 2, Campos: map[string]string{"matricula": "00004", "nome": "", "ativo": "S"}},
+			},
+		},
+		{
+			nome:        "filtro de tipo, linhas em branco e caracteres nulos",
+			codificacao: CodificacaoLatin1,
+			tipo:        ArquivoAlunos,
+			conteudo:    "C99999Cabeçalho\n\n   \nA00005Bia\x00\x00\x00\x00\x00\x00\x00 \nX00006Outro\n",
+			esperados: []Registro{
+				{Arquivo: "ALUNOS.DAT", Linha: 4, Campos: map[string]string{"matricula": "00005", "nome": "Bia", "ativo": "S"}},
+			},
+		},
+		{
+			nome:        "delimitado com cabeçalho ignorado e colunas faltando",
+			codificacao: CodificacaoLatin1,
+			tipo:        ArquivoNotas,
+			conteudo:    "ALUNO;DISC;AVAL;NOTA\n00001; MAT01 ;P1;7,5\n00002;MAT01;P1\n",
+			esperados: []Registro{
+				{Arquivo: "NOTAS.TXT", Linha: 2, Campos: map[string]string{"aluno": "00001", "disciplina": "MAT01", "avaliacao": "P1", "nota": "7,5"}},
+				{Arquivo: "NOTAS.TXT", Linha: 3, Campos: map[string]string{"aluno": "00002", "disciplina": "MAT01", "avaliacao": "P1", "nota": "0"}},
+			},
+		},
+		{
+			nome:        "arquivo apenas com cabeçalho",
+			codificacao: CodificacaoLatin1,
+			tipo:        ArquivoNotas,
+			conteudo:    "ALUNO;DISC;AVAL;NOTA\n",
+			esperados:   nil,
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			registros, err := layoutTeste(caso.codificacao).Le(caso.tipo, strings.NewReader(caso.conteudo))
+			if err != nil {
+				t.Fatalf("Le retornou erro: %v", err)
+			}
+			if !reflect.DeepEqual(registros, caso.esperados) {
+				t.Errorf("registros = %+v\nesperado  %+v", registros, caso.esperados)
+			}
+		})
+	}
+}
+
+func TestLeTipoNaoDescrito(t *testing.T) {
+	if _, err := layoutTeste(CodificacaoLatin1).Le(ArquivoDisciplinas, strings.NewReader("")); err == nil {
+		t.Error("Le não retornou erro para um tipo de arquivo ausente do layout")
+	}
+}
+
+func TestCarrega(t *testing.T) {
+	diretorio := t.TempDir()
+	if err := os.WriteFile(diretorio+"/ALUNOS.DAT", []byte("A00001Ana\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	layout := layoutTeste(CodificacaoLatin1)
+	if _, err := layout.Carrega(diretorio); err == nil {
+		t.Fatal("Carrega não retornou erro com o arquivo de notas ausente")
+	}
+
+	if err := os.WriteFile(diretorio+"/NOTAS.TXT", []byte("cabeçalho\n00001;MAT01;P1;8\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dados, err := layout.Carrega(diretorio)
+	if err != nil {
+		t.Fatalf("Carrega retornou erro: %v", err)
+	}
+	if dados.DominioEmail != "legado.escola.br" {
+		t.Errorf("domínio = %q, esperado %q", dados.DominioEmail, "legado.escola.br")
+	}
+	if len(dados.Registros[ArquivoAlunos]) != 1 || len(dados.Registros[ArquivoNotas]) != 1 {
+		t.Errorf("registros = %+v, esperado um aluno e uma nota", dados.Registros)
+	}
+}
@@ -15,6 +15,69 @@ import (
 // Retorna um erro HTTP 401 para tokens inválidos ou dados formatados incorretamente
 // Define o ID do professor no contexto Gin para solicitações autorizadas
 func Autenticado(ctx *gin.Context) {
+	claims, ok := leToken(ctx)
+	if !ok {
+		return
+	}
+
+	professorId, ok := idDoToken(claims, "professor")
+	if !ok {
+		restErr := utils.NewRestErr(http.StatusUnauthorized, "Token sem Id do professor", nil)
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.Set("professor", professorId)
+}
+
+// AlunoAutenticado valida o token JWT do cabeçalho 'Authorization' da solicitação e identifica o ID do aluno
+//
+// Retorna um erro HTTP 401 para tokens inválidos ou que não pertençam a um aluno
+// Define o ID do aluno no contexto Gin para solicitações autorizadas
+func AlunoAutenticado(ctx *gin.Context) {
+	claims, ok := leToken(ctx)
+	if !ok {
+		return
+	}
+
+	alunoId, ok := idDoToken(claims, "aluno")
+	if !ok {
+		restErr := utils.NewRestErr(http.StatusUnauthorized, "Token sem Id do aluno", nil)
+		utils.RespondRestErr(restErr, ctx)
+		return
+	}
+
+	ctx.Set("aluno", alunoId)
+}
+
+// UsuarioAutenticado valida o token JWT do cabeçalho 'Authorization' aceitando tanto professores quanto alunos
+//
+// Retorna um erro HTTP 401 para tokens inválidos ou sem identificação de usuário
+// Define o ID do professor ou do aluno no contexto Gin, conforme o dono do token
+func UsuarioAutenticado(ctx *gin.Context) {
+	claims, ok := leToken(ctx)
+	if !ok {
+		return
+	}
+
+	if professorId, ok := idDoToken(claims, "professor"); ok {
+		ctx.Set("professor", professorId)
+		return
+	}
+
+	if alunoId, ok := idDoToken(claims, "aluno"); ok {
+		ctx.Set("aluno", alunoId)
+		return
+	}
+
+	restErr := utils.NewRestErr(http.StatusUnauthorized, "Token sem Id do usuário", nil)
+	utils.RespondRestErr(restErr, ctx)
+}
+
+// leToken valida o token JWT do cabeçalho 'Authorization' e retorna suas claims
+//
+// Em caso de token inválido, responde com erro HTTP 401 e retorna false
+func leToken(ctx *gin.Context) (jwt.MapClaims, bool) {
 	secret := os.Getenv("JWT_SECRET")
 	tokenValue := removePrefixoBearer(ctx.Request.Header.Get("Authorization"))
 
@@ -28,25 +91,34 @@ func Autenticado(ctx *gin.Context) {
 	if err != nil {
 		restErr := utils.NewRestErr(http.StatusUnauthorized, "Token inválido", err)
 		utils.RespondRestErr(restErr, ctx)
-		return
+		return nil, false
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
-	professorData, ok := claims["professor"].(map[string]interface{})
 	if !ok {
 		restErr := utils.NewRestErr(http.StatusUnauthorized, "Formato inválido do token", nil)
 		utils.RespondRestErr(restErr, ctx)
-		return
+		return nil, false
 	}
 
-	professorId, exists := professorData["id"].(string)
-	if !exists {
-		restErr := utils.NewRestErr(http.StatusUnauthorized, "Token sem Id do professor", nil)
-		utils.RespondRestErr(restErr, ctx)
-		return
+	return claims, true
+}
+
+// idDoToken extrai o ID do usuário armazenado na claim informada ("professor" ou "aluno")
+//
+// Retorna false se a claim não existir ou não possuir um ID válido
+func idDoToken(claims jwt.MapClaims, claim string) (string, bool) {
+	dados, ok := claims[claim].(map[string]interface{})
+	if !ok {
+		return "", false
 	}
 
-	ctx.Set("professor", professorId)
+	id, ok := dados["id"].(string)
+	if !ok || id == "" {
+		return "", false
+	}
+
+	return id, true
 }
 
 // removePrefixoBearer remove o prefixo "Bearer " de uma string recebida por parâmetro
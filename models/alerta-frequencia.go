@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Tipos de alerta de frequência
+const (
+	AlertaRiscoFrequencia     = "risco"
+	AlertaReprovadoFrequencia = "reprovado"
+)
+
+// AlertaFrequencia representa uma notificação enviada a um aluno em risco de reprovação por falta
+//
+// É gerado quando a frequência máxima que o aluno ainda pode alcançar fica abaixo da frequência mínima da disciplina
+// somada a uma margem configurada (tipo "risco") ou abaixo da própria frequência mínima (tipo "reprovado"). Cada
+// combinação de aluno, disciplina, tipo e margem gera no máximo um alerta
+type AlertaFrequencia struct {
+	Id                       string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
+	AlunoId                  string    `json:"aluno_id" gorm:"not null;column:aluno_id;type:varchar(36);uniqueIndex:idx_alerta_frequencia_unico"`
+	DisciplinaId             string    `json:"disciplina_id" gorm:"not null;column:disciplina_id;type:varchar(36);uniqueIndex:idx_alerta_frequencia_unico"`
+	Tipo                     string    `json:"tipo" gorm:"not null;column:tipo;uniqueIndex:idx_alerta_frequencia_unico"`
+	Margem                   float64   `json:"margem" gorm:"not null;column:margem;uniqueIndex:idx_alerta_frequencia_unico"`
+	FrequenciaAtual          float64   `json:"frequencia_atual" gorm:"not null;column:frequencia_atual"`
+	FrequenciaMaximaPossivel float64   `json:"frequencia_maxima_possivel" gorm:"not null;column:frequencia_maxima_possivel"`
+	Mensagem                 string    `json:"mensagem" gorm:"not null;column:mensagem"`
+	Lido                     bool      `json:"lido" gorm:"not null;column:lido;default:false"`
+	EmailEnviado             bool      `json:"email_enviado" gorm:"not null;column:email_enviado;default:false"`
+	CreatedAt                time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt                time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Aluno      *Aluno      `json:"aluno,omitempty" gorm:"foreignKey:AlunoId;constraint:OnDelete:CASCADE"`
+	Disciplina *Disciplina `json:"disciplina,omitempty" gorm:"foreignKey:DisciplinaId;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura AlertaFrequencia
+func (AlertaFrequencia) TableName() string {
+	return "alertas_frequencia"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de um AlertaFrequencia ser criado
+func (a *AlertaFrequencia) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	a.Id = uuidStr
+	return
+}
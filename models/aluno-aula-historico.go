@@ -0,0 +1,60 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Tipos de autor que podem alterar registros acadêmicos
+const (
+	AutorProfessor = "professor"
+	AutorAluno     = "aluno"
+	AutorSistema   = "sistema"
+)
+
+// AlunoAulaHistorico registra cada alteração feita na presença de um aluno em uma aula
+//
+// Guarda o valor anterior e o novo valor da presença e das horas presentes, quem fez a alteração, quando e o motivo informado.
+// PresencaAnterior nulo indica que não havia registro de presença; PresencaNova nulo indica que o registro foi removido
+type AlunoAulaHistorico struct {
+	Id               string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
+	AulaId           string    `json:"aula_id" gorm:"not null;column:aula_id;type:varchar(36);index"`
+	AlunoId          string    `json:"aluno_id" gorm:"not null;column:aluno_id;type:varchar(36);index"`
+	PresencaAnterior *bool     `json:"presenca_anterior" gorm:"column:presenca_anterior"`
+	PresencaNova     *bool     `json:"presenca_nova" gorm:"column:presenca_nova"`
+	HorasAnteriores  *int      `json:"horas_anteriores" gorm:"column:horas_anteriores"`
+	HorasNovas       *int      `json:"horas_novas" gorm:"column:horas_novas"`
+	AutorId          string    `json:"autor_id" gorm:"not null;column:autor_id;type:varchar(36)"`
+	AutorTipo        string    `json:"autor_tipo" gorm:"not null;column:autor_tipo"`
+	Motivo           string    `json:"motivo" gorm:"not null;column:motivo"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura AlunoAulaHistorico
+func (AlunoAulaHistorico) TableName() string {
+	return "aluno_aula_historico"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de um AlunoAulaHistorico ser criado
+func (h *AlunoAulaHistorico) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	h.Id = uuidStr
+	return
+}
+
+// PresencaAluno representa a presença de um aluno informada em uma correção de chamada
+//
+// HorasPresentes e Atraso são opcionais e seguem as mesmas regras de AlunoAula
+type PresencaAluno struct {
+	AlunoId        string `json:"aluno_id" binding:"required"`
+	Presenca       *bool  `json:"presenca" binding:"required"`
+	HorasPresentes int    `json:"horas_presentes" binding:"omitempty,gte=0"`
+	Atraso         bool   `json:"atraso"`
+}
+
+// CorrecaoPresenca representa o corpo da requisição de marcação ou correção de presenças em uma aula já cadastrada
+type CorrecaoPresenca struct {
+	Presencas []PresencaAluno `json:"presencas" binding:"required,min=1,dive"`
+	Motivo    string          `json:"motivo" binding:"required,min=1,max=500"`
+}
@@ -8,14 +8,18 @@ import (
 
 // AlunoAula representa a presença ou ausência de um aluno em uma aula específica
 //
-// É usado para calcular a frequência do aluno na disciplina
+// É usado para calcular a frequência do aluno na disciplina. HorasPresentes permite registrar presença parcial: quando o
+// aluno está presente e o valor não é informado, considera-se a duração completa da aula. Atraso apenas sinaliza que o
+// aluno chegou após o início da aula
 type AlunoAula struct {
-	Id        string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
-	AulaId    string    `json:"aula_id" gorm:"not null;column:aula_id"`
-	AlunoId   string    `json:"aluno_id" gorm:"not null;column:aluno_id" binding:"required"`
-	Presenca  bool      `json:"presenca" gorm:"column:presenca;not null" binding:"required"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+	Id             string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
+	AulaId         string    `json:"aula_id" gorm:"not null;column:aula_id"`
+	AlunoId        string    `json:"aluno_id" gorm:"not null;column:aluno_id" binding:"required"`
+	Presenca       bool      `json:"presenca" gorm:"column:presenca;not null" binding:"required"`
+	HorasPresentes int       `json:"horas_presentes" gorm:"column:horas_presentes;not null;default:0" binding:"omitempty,gte=0"`
+	Atraso         bool      `json:"atraso" gorm:"column:atraso;not null;default:false"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
 
 	// Relacionamento
 	Aula  Aula  `json:"-" gorm:"foreignKey:AulaId"`
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ErrHistoricoImutavel é retornado ao tentar alterar ou apagar um registro do histórico de notas
+var ErrHistoricoImutavel = errors.New("registros do histórico de notas não podem ser alterados ou removidos")
+
+// AlunoAvaliacaoHistorico registra cada alteração feita na nota de um aluno em uma avaliação
+//
+// Guarda a nota anterior e a nova, quem fez a alteração, quando e a justificativa informada. NotaAnterior nulo indica
+// o primeiro lançamento; NotaNova nulo indica que a nota foi removida. Os registros são imutáveis
+type AlunoAvaliacaoHistorico struct {
+	Id            string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
+	AvaliacaoId   string    `json:"avaliacao_id" gorm:"not null;column:avaliacao_id;type:varchar(36);index"`
+	AlunoId       string    `json:"aluno_id" gorm:"not null;column:aluno_id;type:varchar(36);index"`
+	DisciplinaId  string    `json:"disciplina_id" gorm:"not null;column:disciplina_id;type:varchar(36);index"`
+	NotaAnterior  *float64  `json:"nota_anterior" gorm:"column:nota_anterior"`
+	NotaNova      *float64  `json:"nota_nova" gorm:"column:nota_nova"`
+	AutorId       string    `json:"autor_id" gorm:"not null;column:autor_id;type:varchar(36)"`
+	AutorTipo     string    `json:"autor_tipo" gorm:"not null;column:autor_tipo"`
+	Justificativa string    `json:"justificativa" gorm:"not null;column:justificativa"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura AlunoAvaliacaoHistorico
+func (AlunoAvaliacaoHistorico) TableName() string {
+	return "aluno_avaliacao_historico"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de um AlunoAvaliacaoHistorico ser criado
+func (h *AlunoAvaliacaoHistorico) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	h.Id = uuidStr
+	return
+}
+
+// BeforeUpdate impede que um registro do histórico de notas seja alterado
+func (h *AlunoAvaliacaoHistorico) BeforeUpdate(_ *gorm.DB) error {
+	return ErrHistoricoImutavel
+}
+
+// BeforeDelete impede que um registro do histórico de notas seja removido
+func (h *AlunoAvaliacaoHistorico) BeforeDelete(_ *gorm.DB) error {
+	return ErrHistoricoImutavel
+}
+
+// CorrecaoNota representa o corpo da requisição de correção da nota de um aluno em uma avaliação
+//
+// A nota deve ser informada na escala da disciplina, em `nota` ou em `nota_escala`, como no lançamento
+type CorrecaoNota struct {
+	Nota          *float64 `json:"nota" binding:"required_without=NotaEscala,omitempty,gte=0,lte=100"`
+	NotaEscala    *string  `json:"nota_escala" binding:"required_without=Nota,omitempty,max=10"`
+	Justificativa string   `json:"justificativa" binding:"required,min=1,max=500"`
+}
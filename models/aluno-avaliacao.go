@@ -3,18 +3,26 @@ package models
 import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"sistema-alunos-go/utils"
 	"time"
 )
 
 // AlunoAvaliacao representa a nota que um aluno tirou em uma determinada avaliação.
 //
-// Também referencia a disciplina à qual a avaliação pertence
+// Também referencia a disciplina à qual a avaliação pertence. Cada aluno possui no máximo uma nota por avaliação.
+//
+// A nota é lançada na escala da disciplina: em `nota` para escalas numéricas ou em `nota_escala` para letras e
+// conceitos; a nota zero em escalas numéricas é lançada em `nota_escala`, pois `nota` igual a zero equivale a nota não
+// informada. Nota guarda sempre o valor convertido para a escala interna de 0 a 10 e NotaEscala o valor na escala da
+// disciplina. Em trabalhos entregues com atraso, Penalidade é o percentual já descontado de Nota e NotaEscala
 type AlunoAvaliacao struct {
 	Id           string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
-	AlunoId      string    `json:"aluno_id" gorm:"type:varchar(36);not null" binding:"required"`
-	AvaliacaoId  string    `json:"avaliacao_id" gorm:"type:varchar(36);not null"`
+	AlunoId      string    `json:"aluno_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_aluno_avaliacao_unico" binding:"required"`
+	AvaliacaoId  string    `json:"avaliacao_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_aluno_avaliacao_unico"`
 	DisciplinaId string    `json:"disciplina_id" gorm:"type:varchar(36);not null"`
-	Nota         float64   `json:"nota" gorm:"not null" binding:"required,gte=0,lte=10"`
+	Nota         float64   `json:"nota" gorm:"not null" binding:"required_without=NotaEscala,omitempty,gte=0,lte=100"`
+	NotaEscala   string    `json:"nota_escala" gorm:"not null;column:nota_escala;default:''" binding:"omitempty,max=10"`
+	Penalidade   float64   `json:"penalidade" gorm:"not null;column:penalidade;default:0"`
 	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
 	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
 
@@ -34,3 +42,11 @@ func (aa *AlunoAvaliacao) BeforeCreate(_ *gorm.DB) (err error) {
 	aa.Id = uuidStr
 	return
 }
+
+// ResultadoNotas representa o resultado do lançamento de uma lista de notas
+//
+// Salvas contém as notas gravadas e Rejeitadas as linhas recusadas no modo parcial, com o motivo de cada uma
+type ResultadoNotas struct {
+	Salvas     []AlunoAvaliacao  `json:"salvas"`
+	Rejeitadas []utils.ErroAluno `json:"rejeitadas"`
+}
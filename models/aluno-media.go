@@ -6,18 +6,40 @@ import (
 	"time"
 )
 
+// Situações finais de um aluno em uma disciplina
+const (
+	SituacaoAprovado            = "aprovado"
+	SituacaoAprovadoRecuperacao = "aprovado_recuperacao"
+	SituacaoEmRecuperacao       = "em_recuperacao"
+	SituacaoReprovadoNota       = "reprovado_nota"
+	SituacaoReprovadoFalta      = "reprovado_falta"
+	SituacaoReprovadoNotaFalta  = "reprovado_nota_falta"
+	SituacaoTrancado            = "trancado"
+)
+
 // AlunoMedia armazena o resultado final de um aluno ao final de uma disciplina
 //
-// Inclui a média final, frequência e status de aprovação com base nos critérios da disciplina
+// Inclui a média final, frequência e status de aprovação com base nos critérios da disciplina. MediaSemestre guarda a
+// média calculada no fechamento do semestre; para alunos em recuperação, MediaFinal e Aprovado só são definitivos após
+// o fechamento da recuperação, que também registra a NotaRecuperacao. MediaConvertida é a média final na escala da
+// disciplina.
+//
+// Situacao detalha o resultado do aluno (aprovado, aprovado em recuperação, em recuperação, reprovado por nota, por
+// falta ou por ambos, ou trancado); Aprovado é mantido por compatibilidade e só é verdadeiro nas situações de aprovação
 type AlunoMedia struct {
-	Id           string    `json:"id" gorm:"primaryKey;column:id"`
-	AlunoId      string    `json:"aluno_id" gorm:"not null;column:aluno_id;index:idx_aluno_media_id"`
-	DisciplinaId string    `json:"disciplina_id" gorm:"not null;column:disciplina_id;index:idx_aluno_media_id"`
-	MediaFinal   float64   `json:"media_final" gorm:"column:media_final"`
-	Frequencia   float64   `json:"frequencia" gorm:"column:frequencia"`
-	Aprovado     bool      `json:"aprovado" gorm:"column:aprovado"`
-	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
-	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+	Id              string    `json:"id" gorm:"primaryKey;column:id"`
+	AlunoId         string    `json:"aluno_id" gorm:"not null;column:aluno_id;index:idx_aluno_media_id"`
+	DisciplinaId    string    `json:"disciplina_id" gorm:"not null;column:disciplina_id;index:idx_aluno_media_id"`
+	MediaSemestre   float64   `json:"media_semestre" gorm:"not null;column:media_semestre;default:0"`
+	MediaFinal      float64   `json:"media_final" gorm:"column:media_final"`
+	MediaConvertida string    `json:"media_convertida" gorm:"not null;column:media_convertida;default:''"`
+	Frequencia      float64   `json:"frequencia" gorm:"column:frequencia"`
+	Aprovado        bool      `json:"aprovado" gorm:"column:aprovado"`
+	Situacao        string    `json:"situacao" gorm:"not null;column:situacao;default:''"`
+	EmRecuperacao   bool      `json:"em_recuperacao" gorm:"not null;column:em_recuperacao;default:false"`
+	NotaRecuperacao *float64  `json:"nota_recuperacao" gorm:"column:nota_recuperacao"`
+	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
 
 	// Referências
 	Aluno      Aluno      `json:"-" gorm:"foreignKey:AlunoId;references:Id"`
@@ -35,3 +57,16 @@ func (am *AlunoMedia) BeforeCreate(_ *gorm.DB) (err error) {
 	am.Id = uuidStr
 	return
 }
+
+// ItemHistoricoEscolar representa o resultado de um aluno em uma disciplina no histórico escolar
+type ItemHistoricoEscolar struct {
+	DisciplinaId    string    `json:"disciplina_id"`
+	Disciplina      string    `json:"disciplina"`
+	AnoSemestre     string    `json:"ano_semestre"`
+	MediaFinal      float64   `json:"media_final"`
+	MediaConvertida string    `json:"media_convertida"`
+	Frequencia      float64   `json:"frequencia"`
+	Situacao        string    `json:"situacao"`
+	Aprovado        bool      `json:"aprovado"`
+	CreatedAt       time.Time `json:"created_at"`
+}
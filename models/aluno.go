@@ -9,11 +9,13 @@ import (
 // Aluno representa um estudante matriculado no sistema
 //
 // Contém dados básicos de identificação, status de matrícula e relacionamentos com disciplinas, avaliações e aulas.
+// A senha é opcional, definida por um professor, e permite que o aluno se autentique na API; nunca é serializada em JSON.
 type Aluno struct {
 	Id        string    `json:"id,omitempty" gorm:"primaryKey;column:id;type:varchar(36);not null"`
 	Nome      string    `json:"nome,omitempty" gorm:"type:varchar(60);column:nome;not null" binding:"required,min=1,max=60"`
 	Email     string    `json:"email,omitempty" gorm:"type:text;column:email;not null" binding:"required,email"`
 	Ativo     bool      `json:"ativo,omitempty" gorm:"column:ativo;not null"`
+	Senha     string    `json:"-" gorm:"column:senha"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
 
@@ -34,3 +36,9 @@ func (a *Aluno) BeforeCreate(_ *gorm.DB) (err error) {
 	a.Id = uuidStr
 	return
 }
+
+// DefinicaoSenha representa a senha de acesso definida para um aluno
+type DefinicaoSenha struct {
+	Senha          string `json:"senha" binding:"required,senha_forte"`
+	ConfirmarSenha string `json:"confirmar_senha" binding:"required"`
+}
@@ -8,11 +8,11 @@ import (
 
 // Aula representa um encontro presencial de uma disciplina
 //
-// Armazena o número da aula, data, duração e conteúdo abordado. Também relaciona os alunos presentes via registros de presença
+// Armazena o número da aula, único na disciplina, data, duração e conteúdo abordado. Também relaciona os alunos presentes via registros de presença
 type Aula struct {
 	Id              string    `json:"id" gorm:"primaryKey;column:id"`
-	DisciplinaId    string    `json:"disciplina_id" gorm:"not null;column:disciplina_id;index"` // FK
-	Numero          int       `json:"numero" gorm:"not null;column:numero" binding:"required,gte=1"`
+	DisciplinaId    string    `json:"disciplina_id" gorm:"not null;column:disciplina_id;index;uniqueIndex:idx_aula_numero"` // FK
+	Numero          int       `json:"numero" gorm:"not null;column:numero;uniqueIndex:idx_aula_numero" binding:"required,gte=1"`
 	Data            string    `json:"data" gorm:"not null;column:data" binding:"required,data_valida"`
 	QuantidadeHoras int       `json:"quantidade_horas" gorm:"not null;column:quantidade_horas" binding:"required,gte=1"`
 	Conteudo        string    `json:"conteudo" gorm:"not null;column:conteudo" binding:"required,min=1,max=1000"`
@@ -35,3 +35,14 @@ func (a *Aula) BeforeCreate(_ *gorm.DB) (err error) {
 	a.Id = uuidStr
 	return
 }
+
+// AtualizacaoAula representa os campos de uma aula que podem ser alterados após o cadastro
+//
+// Campos nulos são mantidos como estão. Quando AlunoAula é informado, substitui toda a lista de presenças da aula
+type AtualizacaoAula struct {
+	Numero          *int        `json:"numero" binding:"omitempty,gte=1"`
+	Data            *string     `json:"data" binding:"omitempty,data_valida"`
+	QuantidadeHoras *int        `json:"quantidade_horas" binding:"omitempty,gte=1"`
+	Conteudo        *string     `json:"conteudo" binding:"omitempty,min=1,max=1000"`
+	AlunoAula       []AlunoAula `json:"aluno_aula"`
+}
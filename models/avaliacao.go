@@ -6,14 +6,23 @@ import (
 	"time"
 )
 
+// Tipos de avaliação
+const (
+	AvaliacaoProva       = "P"
+	AvaliacaoTrabalho    = "T"
+	AvaliacaoRecuperacao = "R"
+)
+
 // Avaliacao representa uma prova ou trabalho relacionado a uma disciplina
 //
-// Cada avaliação possui um tipo ("P" para prova, "T" para trabalho), uma data, peso e pertence a uma disciplina específica
+// Cada avaliação possui um tipo ("P" para prova, "T" para trabalho), uma data, peso e pertence a uma disciplina específica.
+// Avaliações de recuperação ("R") não entram na média do semestre nem na verificação de pesos; suas notas só podem ser
+// lançadas durante a recuperação, para os alunos em recuperação
 type Avaliacao struct {
 	Id            string    `json:"id" gorm:"primaryKey;column:id"`
 	DisciplinaId  string    `json:"disciplina_id" gorm:"not null;column:disciplina_id;index:idx_avaliacao"` // FK
 	Nome          string    `json:"nome" gorm:"not null;column:nome" binding:"required,min=1,max=60"`
-	Tipo          string    `json:"tipo" gorm:"not null;column:tipo" binding:"required,oneof=P T"`
+	Tipo          string    `json:"tipo" gorm:"not null;column:tipo" binding:"required,oneof=P T R"`
 	DataAvaliacao string    `json:"data_avaliacao" gorm:"not null;column:data_avaliacao" binding:"required,data_valida"`
 	Peso          float64   `json:"peso" gorm:"not null;column:peso" binding:"required,gte=0,lte=1"`
 	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
@@ -24,6 +33,11 @@ type Avaliacao struct {
 	AlunoAvaliacoes []AlunoAvaliacao `json:"aluno_avaliacoes,omitempty" gorm:"foreignKey:AvaliacaoId;constraint:OnDelete:CASCADE"`
 }
 
+// Recuperacao indica se a avaliação é a avaliação de recuperação da disciplina
+func (a *Avaliacao) Recuperacao() bool {
+	return a.Tipo == AvaliacaoRecuperacao
+}
+
 // TableName especifica o nome da tabela do banco de dados para a estrutura Avaliacao
 func (Avaliacao) TableName() string {
 	return "avaliacoes"
@@ -35,3 +49,37 @@ func (a *Avaliacao) BeforeCreate(_ *gorm.DB) (err error) {
 	a.Id = uuidStr
 	return
 }
+
+// AtualizacaoAvaliacao representa os campos de uma avaliação que podem ser alterados após o cadastro
+//
+// Campos nulos são mantidos como estão
+type AtualizacaoAvaliacao struct {
+	Nome          *string  `json:"nome" binding:"omitempty,min=1,max=60"`
+	Tipo          *string  `json:"tipo" binding:"omitempty,oneof=P T R"`
+	DataAvaliacao *string  `json:"data_avaliacao" binding:"omitempty,data_valida"`
+	Peso          *float64 `json:"peso" binding:"omitempty,gte=0,lte=1"`
+}
+
+// PesoAvaliacao representa o peso de uma avaliação no relatório de consistência de pesos de uma disciplina
+//
+// PesoEfetivo é o peso realmente aplicado no cálculo da média, após a normalização quando ela estiver habilitada
+type PesoAvaliacao struct {
+	Id          string  `json:"id"`
+	Nome        string  `json:"nome"`
+	Tipo        string  `json:"tipo"`
+	Peso        float64 `json:"peso"`
+	PesoEfetivo float64 `json:"peso_efetivo"`
+}
+
+// ConsistenciaPesos representa o relatório de verificação dos pesos das avaliações de uma disciplina
+//
+// Os pesos são consistentes quando somam 1. Pesos inconsistentes só permitem fechar o semestre se a disciplina
+// tiver optado pela normalização
+type ConsistenciaPesos struct {
+	DisciplinaId    string          `json:"disciplina_id"`
+	SomaPesos       float64         `json:"soma_pesos"`
+	Consistente     bool            `json:"consistente"`
+	NormalizarPesos bool            `json:"normalizar_pesos"`
+	PodeFechar      bool            `json:"pode_fechar"`
+	Avaliacoes      []PesoAvaliacao `json:"avaliacoes"`
+}
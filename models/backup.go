@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Identificação do arquivo de backup gerado pelo sistema
+//
+// VersaoBackup deve ser incrementada sempre que o formato do arquivo ou as colunas exportadas mudarem de forma
+// incompatível com as versões anteriores
+const (
+	FormatoBackup = "sistema-alunos-go/backup"
+	VersaoBackup  = 1
+)
+
+// CabecalhoBackup é a primeira linha de um arquivo de backup
+//
+// Senhas indica se os hashes das senhas de professores e alunos foram incluídos
+type CabecalhoBackup struct {
+	Formato  string    `json:"formato"`
+	Versao   int       `json:"versao"`
+	GeradoEm time.Time `json:"gerado_em"`
+	Senhas   bool      `json:"senhas"`
+}
+
+// RegistroBackup é uma linha de um arquivo de backup, com um registro de uma tabela
+//
+// A última linha do arquivo não tem Tabela e traz em Totais a quantidade de registros exportados de cada tabela, para
+// que arquivos truncados sejam identificados na importação
+type RegistroBackup struct {
+	Tabela string                     `json:"tabela,omitempty"`
+	Dados  map[string]json.RawMessage `json:"dados,omitempty"`
+	Totais map[string]int             `json:"totais,omitempty"`
+}
+
+// ProblemaBackup representa um problema encontrado em um registro de um arquivo de backup que impede a importação
+//
+// Linha é o número da linha do arquivo em que o registro está, quando o problema é de um registro específico
+type ProblemaBackup struct {
+	Linha  int    `json:"linha,omitempty"`
+	Tabela string `json:"tabela,omitempty"`
+	Id     string `json:"id,omitempty"`
+	Coluna string `json:"coluna,omitempty"`
+	Motivo string `json:"motivo"`
+}
+
+// ResultadoImportacaoBackup representa o resultado da importação de um backup, com a quantidade de registros
+// importados em cada tabela
+type ResultadoImportacaoBackup struct {
+	Versao    int            `json:"versao"`
+	GeradoEm  time.Time      `json:"gerado_em"`
+	Senhas    bool           `json:"senhas"`
+	Registros map[string]int `json:"registros"`
+}
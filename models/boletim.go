@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Boletim representa o boletim de um aluno em um período letivo, com o desempenho em cada disciplina cursada
+//
+// É montado a partir dos registros acadêmicos e usado na geração do boletim em PDF
+type Boletim struct {
+	AlunoId     string              `json:"aluno_id"`
+	AlunoNome   string              `json:"aluno_nome"`
+	AlunoEmail  string              `json:"aluno_email"`
+	Periodo     string              `json:"periodo"`
+	EmitidoEm   time.Time           `json:"emitido_em"`
+	Disciplinas []BoletimDisciplina `json:"disciplinas"`
+}
+
+// BoletimDisciplina representa o desempenho de um aluno em uma disciplina no boletim
+//
+// Enquanto o semestre da disciplina não for fechado, Situacao fica vazia, MediaFinal é nula e Frequencia é a
+// frequência parcial, calculada sobre as aulas já registradas
+type BoletimDisciplina struct {
+	DisciplinaId     string             `json:"disciplina_id"`
+	Nome             string             `json:"nome"`
+	Professor        string             `json:"professor"`
+	NotaMinima       float64            `json:"nota_minima"`
+	FrequenciaMinima float64            `json:"frequencia_minima"`
+	Avaliacoes       []BoletimAvaliacao `json:"avaliacoes"`
+	MediaFinal       *float64           `json:"media_final"`
+	MediaConvertida  string             `json:"media_convertida"`
+	Frequencia       float64            `json:"frequencia"`
+	Situacao         string             `json:"situacao"`
+}
+
+// BoletimAvaliacao representa uma avaliação de uma disciplina no boletim e a nota do aluno, na escala da disciplina
+//
+// Nota fica vazia quando o aluno ainda não tem nota lançada na avaliação
+type BoletimAvaliacao struct {
+	Nome string  `json:"nome"`
+	Tipo string  `json:"tipo"`
+	Data string  `json:"data"`
+	Peso float64 `json:"peso"`
+	Nota string  `json:"nota"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Marcações da presença de um aluno em uma aula no diário de classe
+const (
+	MarcaPresente    = "P"
+	MarcaFalta       = "F"
+	MarcaAtraso      = "A"
+	MarcaAbonada     = "J"
+	MarcaSemRegistro = "-"
+)
+
+// CabecalhoDisciplina identifica a disciplina nos documentos oficiais emitidos para ela, como o diário de classe e a
+// ata final
+type CabecalhoDisciplina struct {
+	DisciplinaId         string    `json:"disciplina_id"`
+	Disciplina           string    `json:"disciplina"`
+	Professor            string    `json:"professor"`
+	AnoSemestre          string    `json:"ano_semestre"`
+	CargaHorariaPrevista int       `json:"carga_horaria_prevista"`
+	EmitidoEm            time.Time `json:"emitido_em"`
+}
+
+// AlunoDocumento identifica um aluno matriculado nos documentos de uma disciplina
+type AlunoDocumento struct {
+	AlunoId string `json:"aluno_id"`
+	Nome    string `json:"nome"`
+}
+
+// AvaliacaoDocumento identifica uma avaliação da disciplina nos documentos emitidos para ela
+type AvaliacaoDocumento struct {
+	Nome string  `json:"nome"`
+	Tipo string  `json:"tipo"`
+	Data string  `json:"data"`
+	Peso float64 `json:"peso"`
+}
+
+// DiarioClasse representa o diário de classe de uma disciplina: as aulas ministradas e a frequência de cada aluno
+//
+// É montado a partir das aulas e presenças registradas e usado na exportação do diário em PDF e CSV
+type DiarioClasse struct {
+	CabecalhoDisciplina
+	Alunos []AlunoDocumento `json:"alunos"`
+	Aulas  []DiarioAula     `json:"aulas"`
+}
+
+// DiarioAula representa uma aula no diário de classe com a marcação de presença de cada aluno
+//
+// Presencas segue a ordem de DiarioClasse.Alunos. Cada marcação é uma das constantes Marca*, exceto na presença
+// parcial, registrada como as horas assistidas sobre a duração da aula (por exemplo, "2/4")
+type DiarioAula struct {
+	Numero          int      `json:"numero"`
+	Data            string   `json:"data"`
+	QuantidadeHoras int      `json:"quantidade_horas"`
+	Conteudo        string   `json:"conteudo"`
+	Presencas       []string `json:"presencas"`
+}
+
+// AtaFinal representa a ata final de uma disciplina, com as notas e o resultado de cada aluno matriculado
+//
+// É montada a partir das avaliações, notas e resultados registrados e usada na exportação da ata em PDF e CSV
+type AtaFinal struct {
+	CabecalhoDisciplina
+	Avaliacoes []AvaliacaoDocumento `json:"avaliacoes"`
+	Alunos     []AtaAluno           `json:"alunos"`
+}
+
+// AtaAluno representa a linha de um aluno na ata final
+//
+// Notas segue a ordem de AtaFinal.Avaliacoes, na escala da disciplina, e fica vazia nas avaliações sem nota lançada.
+// Enquanto o semestre não for fechado, Situacao fica vazia, MediaFinal é nula e Frequencia é a frequência parcial
+type AtaAluno struct {
+	AlunoDocumento
+	Notas           []string `json:"notas"`
+	Frequencia      float64  `json:"frequencia"`
+	MediaFinal      *float64 `json:"media_final"`
+	MediaConvertida string   `json:"media_convertida"`
+	Situacao        string   `json:"situacao"`
+}
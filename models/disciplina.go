@@ -6,10 +6,42 @@ import (
 	"time"
 )
 
+// Situações possíveis do semestre de uma disciplina
+const (
+	DisciplinaAberta        = "aberta"
+	DisciplinaEmRecuperacao = "recuperacao"
+	DisciplinaFechada       = "fechada"
+)
+
+// Modos de cálculo da frequência de uma disciplina
+const (
+	FrequenciaPorHoras = "horas"
+	FrequenciaPorAulas = "aulas"
+)
+
+// Regras de cálculo da média final após a recuperação
+const (
+	RecuperacaoSubstitui = "substituir"
+	RecuperacaoMedia     = "media"
+	RecuperacaoLimita    = "limitar"
+)
+
 // Disciplina representa uma matéria ministrada por um professor
 //
 // Contém informações sobre carga horária, número de provas, critérios de aprovação e relacionamentos com alunos, aulas,
-// avaliações e o professor responsável.
+// avaliações e o professor responsável. ModoFrequencia define se a frequência é calculada sobre as horas assistidas em
+// relação à carga horária realizada ("horas") ou sobre o número de aulas assistidas ("aulas"). NormalizarPesos permite
+// fechar o semestre mesmo que os pesos das avaliações não somem 1, dividindo a média pela soma dos pesos. FormulaMedia,
+// quando definida, substitui a média ponderada por uma expressão do pacote formula que referencia as avaliações pelo nome.
+//
+// Se Recuperacao estiver habilitada, os alunos com frequência suficiente e média abaixo de NotaMinima, mas não inferior a
+// NotaMinimaRecuperacao, ficam em recuperação ao fechar o semestre. O resultado definitivo é calculado no fechamento da
+// recuperação conforme RegraRecuperacao: "substituir" usa a nota da recuperação, "media" faz a média entre ela e a média
+// do semestre e "limitar" usa a nota da recuperação limitada a NotaMinima. A média final nunca fica abaixo da média do
+// semestre.
+//
+// Escala define como as notas são lançadas e exibidas: "0-10", "0-100", "letras" (A a F) ou "conceitos" (MB, B, R e I).
+// Internamente, notas, médias e critérios de aprovação continuam de 0 a 10, convertidos pelas tabelas de TabelasEscala.
 type Disciplina struct {
 	Id                    string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
 	Nome                  string    `json:"nome" gorm:"not null;column:nome;index" binding:"required,min=1,max=60"`
@@ -22,6 +54,14 @@ type Disciplina struct {
 	CargaHorariaRealizada int       `json:"carga_horaria_realizada" gorm:"not null;column:carga_horaria_realizada;default:0"`
 	NotaMinima            float64   `json:"nota_minima" gorm:"not null;column:nota_minima" binding:"required,gte=5,lte=10"`
 	FrequenciaMinima      float64   `json:"frequencia_minima" gorm:"not null;column:frequencia_minima" binding:"required,gte=70,lte=100"`
+	NormalizarPesos       bool      `json:"normalizar_pesos" gorm:"not null;column:normalizar_pesos;default:false"`
+	ModoFrequencia        string    `json:"modo_frequencia" gorm:"not null;column:modo_frequencia;default:horas" binding:"omitempty,oneof=horas aulas"`
+	FormulaMedia          string    `json:"formula_media" gorm:"not null;column:formula_media;default:''" binding:"omitempty,max=500"`
+	Recuperacao           bool      `json:"recuperacao" gorm:"not null;column:recuperacao;default:false"`
+	NotaMinimaRecuperacao float64   `json:"nota_minima_recuperacao" gorm:"not null;column:nota_minima_recuperacao;default:0" binding:"omitempty,gte=0,ltfield=NotaMinima"`
+	RegraRecuperacao      string    `json:"regra_recuperacao" gorm:"not null;column:regra_recuperacao;default:substituir" binding:"omitempty,oneof=substituir media limitar"`
+	Escala                string    `json:"escala" gorm:"not null;column:escala;default:'0-10'" binding:"omitempty,oneof=0-10 0-100 letras conceitos"`
+	Situacao              string    `json:"situacao" gorm:"not null;column:situacao;default:aberta"`
 	CreatedAt             time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
 	UpdatedAt             time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
 
@@ -43,3 +83,26 @@ func (a *Disciplina) BeforeCreate(_ *gorm.DB) (err error) {
 	a.Id = uuidStr
 	return
 }
+
+// Aberta indica se o semestre da disciplina ainda permite alterações em aulas, avaliações e notas
+func (d *Disciplina) Aberta() bool {
+	return d.Situacao == DisciplinaAberta
+}
+
+// EmRecuperacao indica se o semestre da disciplina foi fechado e a recuperação ainda está em andamento
+func (d *Disciplina) EmRecuperacao() bool {
+	return d.Situacao == DisciplinaEmRecuperacao
+}
+
+// ConfiguracaoDisciplina representa os critérios de uma disciplina que podem ser alterados enquanto o semestre está aberto
+//
+// Campos nulos são mantidos como estão. Uma fórmula de média vazia volta a usar a média ponderada pelos pesos
+type ConfiguracaoDisciplina struct {
+	ModoFrequencia        *string  `json:"modo_frequencia" binding:"omitempty,oneof=horas aulas"`
+	NormalizarPesos       *bool    `json:"normalizar_pesos"`
+	FormulaMedia          *string  `json:"formula_media" binding:"omitempty,max=500"`
+	Recuperacao           *bool    `json:"recuperacao"`
+	NotaMinimaRecuperacao *float64 `json:"nota_minima_recuperacao" binding:"omitempty,gte=0,lte=10"`
+	RegraRecuperacao      *string  `json:"regra_recuperacao" binding:"omitempty,oneof=substituir media limitar"`
+	Escala                *string  `json:"escala" binding:"omitempty,oneof=0-10 0-100 letras conceitos"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"math"
+	"time"
+)
+
+// JanelaEntrega representa o período em que os alunos podem entregar um trabalho
+//
+// Entregas feitas após o Fim são marcadas como atrasadas e só são aceitas até AtrasoMaximoDias dias depois do prazo.
+// PenalidadeAtraso é o percentual descontado da nota por dia de atraso, contado a partir do primeiro segundo após o
+// prazo, até o limite de 100%
+type JanelaEntrega struct {
+	Id               string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	AvaliacaoId      string    `json:"avaliacao_id" gorm:"type:varchar(36);not null;uniqueIndex"`
+	Inicio           time.Time `json:"inicio" gorm:"not null" binding:"required"`
+	Fim              time.Time `json:"fim" gorm:"not null" binding:"required,gtfield=Inicio"`
+	AtrasoMaximoDias int       `json:"atraso_maximo_dias" gorm:"not null;default:0" binding:"gte=0,lte=30"`
+	PenalidadeAtraso float64   `json:"penalidade_atraso" gorm:"not null;default:0" binding:"gte=0,lte=100"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Avaliacao *Avaliacao `json:"-" gorm:"foreignKey:AvaliacaoId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura JanelaEntrega
+func (JanelaEntrega) TableName() string {
+	return "janelas_entrega"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma JanelaEntrega ser criada
+func (j *JanelaEntrega) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	j.Id = uuidStr
+	return
+}
+
+// DiasAtraso retorna quantos dias, arredondados para cima, o instante informado está além do prazo de entrega
+func (j *JanelaEntrega) DiasAtraso(instante time.Time) int {
+	if !instante.After(j.Fim) {
+		return 0
+	}
+	return int(math.Ceil(instante.Sub(j.Fim).Hours() / 24))
+}
+
+// Penalidade retorna o percentual descontado da nota para a quantidade de dias de atraso informada
+func (j *JanelaEntrega) Penalidade(diasAtraso int) float64 {
+	return math.Min(100, float64(diasAtraso)*j.PenalidadeAtraso)
+}
+
+// Entrega representa um arquivo entregue por um aluno para um trabalho
+//
+// Cada reenvio gera uma nova entrega com a Versao seguinte, preservando as anteriores como histórico; apenas a mais
+// recente fica marcada como Atual e é considerada na correção e na penalidade por atraso. Os índices únicos
+// idx_entrega_versao e idx_entrega_atual garantem uma única entrega por versão e uma única entrega atual por aluno
+type Entrega struct {
+	Id             string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	AvaliacaoId    string    `json:"avaliacao_id" gorm:"type:varchar(36);not null;index:idx_entrega_aluno;uniqueIndex:idx_entrega_versao;uniqueIndex:idx_entrega_atual,where:atual"`
+	AlunoId        string    `json:"aluno_id" gorm:"type:varchar(36);not null;index:idx_entrega_aluno;uniqueIndex:idx_entrega_versao;uniqueIndex:idx_entrega_atual,where:atual"`
+	DisciplinaId   string    `json:"disciplina_id" gorm:"type:varchar(36);not null"`
+	Versao         int       `json:"versao" gorm:"not null;uniqueIndex:idx_entrega_versao"`
+	Atual          bool      `json:"atual" gorm:"not null;default:true"`
+	Atrasada       bool      `json:"atrasada" gorm:"not null;default:false"`
+	DiasAtraso     int       `json:"dias_atraso" gorm:"not null;default:0"`
+	ArquivoNome    string    `json:"arquivo_nome" gorm:"not null"`
+	ArquivoTipo    string    `json:"arquivo_tipo" gorm:"not null"`
+	ArquivoTamanho int64     `json:"arquivo_tamanho" gorm:"not null"`
+	ArquivoChave   string    `json:"-" gorm:"not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+
+	// Relacionamentos
+	Aluno     *Aluno     `json:"aluno,omitempty" gorm:"foreignKey:AlunoId;references:Id;constraint:OnDelete:CASCADE"`
+	Avaliacao *Avaliacao `json:"-" gorm:"foreignKey:AvaliacaoId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura Entrega
+func (Entrega) TableName() string {
+	return "entregas"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma Entrega ser criada
+func (e *Entrega) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	e.Id = uuidStr
+	return
+}
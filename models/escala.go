@@ -0,0 +1,49 @@
+package models
+
+// Escalas de notas disponíveis para uma disciplina
+const (
+	EscalaDecimal    = "0-10"
+	EscalaCentesimal = "0-100"
+	EscalaLetras     = "letras"
+	EscalaConceitos  = "conceitos"
+)
+
+// FaixaEscala representa uma linha da tabela de conversão de uma escala de letras ou conceitos
+//
+// Uma nota interna (de 0 a 10) maior ou igual a NotaMinima, e menor que a NotaMinima da faixa anterior, é convertida
+// em Valor. No sentido inverso, Valor é convertido em NotaEquivalente
+type FaixaEscala struct {
+	Valor           string  `json:"valor"`
+	NotaMinima      float64 `json:"nota_minima"`
+	NotaEquivalente float64 `json:"nota_equivalente"`
+}
+
+// TabelaEscala representa uma escala de notas e sua tabela de conversão para a nota interna de 0 a 10
+//
+// Escalas numéricas não possuem faixas; Fator é o valor pelo qual a nota interna é multiplicada para obter a nota na
+// escala
+type TabelaEscala struct {
+	Escala string        `json:"escala"`
+	Fator  float64       `json:"fator,omitempty"`
+	Faixas []FaixaEscala `json:"faixas,omitempty"`
+}
+
+// TabelasEscala são as tabelas de conversão de cada escala, com as faixas em ordem decrescente de nota
+var TabelasEscala = map[string]TabelaEscala{
+	EscalaDecimal:    {Escala: EscalaDecimal, Fator: 1},
+	EscalaCentesimal: {Escala: EscalaCentesimal, Fator: 10},
+	EscalaLetras: {Escala: EscalaLetras, Faixas: []FaixaEscala{
+		{Valor: "A", NotaMinima: 9, NotaEquivalente: 9.5},
+		{Valor: "B", NotaMinima: 8, NotaEquivalente: 8.5},
+		{Valor: "C", NotaMinima: 7, NotaEquivalente: 7.5},
+		{Valor: "D", NotaMinima: 6, NotaEquivalente: 6.5},
+		{Valor: "E", NotaMinima: 5, NotaEquivalente: 5.5},
+		{Valor: "F", NotaMinima: 0, NotaEquivalente: 2.5},
+	}},
+	EscalaConceitos: {Escala: EscalaConceitos, Faixas: []FaixaEscala{
+		{Valor: "MB", NotaMinima: 9, NotaEquivalente: 9.5},
+		{Valor: "B", NotaMinima: 7, NotaEquivalente: 8},
+		{Valor: "R", NotaMinima: 5, NotaEquivalente: 6},
+		{Valor: "I", NotaMinima: 0, NotaEquivalente: 2.5},
+	}},
+}
@@ -0,0 +1,53 @@
+package models
+
+// FaixaHistograma representa uma faixa do histograma de distribuição de notas
+//
+// A faixa inclui o Inicio e exclui o Fim, exceto a última, que também inclui o valor máximo da escala
+type FaixaHistograma struct {
+	Inicio     float64 `json:"inicio"`
+	Fim        float64 `json:"fim"`
+	Quantidade int64   `json:"quantidade"`
+}
+
+// EstatisticasNotas representa as estatísticas descritivas de um conjunto de notas, médias ou frequências
+//
+// Os campos numéricos são nulos quando não há valores para calcular
+type EstatisticasNotas struct {
+	Quantidade      int64             `json:"quantidade"`
+	Media           *float64          `json:"media"`
+	Mediana         *float64          `json:"mediana"`
+	DesvioPadrao    *float64          `json:"desvio_padrao"`
+	Minima          *float64          `json:"minima"`
+	Maxima          *float64          `json:"maxima"`
+	PrimeiroQuartil *float64          `json:"primeiro_quartil"`
+	TerceiroQuartil *float64          `json:"terceiro_quartil"`
+	Histograma      []FaixaHistograma `json:"histograma"`
+}
+
+// EstatisticasAvaliacao representa as estatísticas das notas lançadas em uma avaliação, na escala interna de 0 a 10
+type EstatisticasAvaliacao struct {
+	AvaliacaoId string            `json:"avaliacao_id"`
+	Nome        string            `json:"nome"`
+	Tipo        string            `json:"tipo"`
+	Notas       EstatisticasNotas `json:"notas"`
+}
+
+// TaxaSituacao representa a quantidade e o percentual de alunos de uma disciplina em uma situação final
+type TaxaSituacao struct {
+	Situacao   string  `json:"situacao"`
+	Quantidade int64   `json:"quantidade"`
+	Percentual float64 `json:"percentual"`
+}
+
+// EstatisticasDisciplina representa as estatísticas dos resultados finais de uma disciplina
+//
+// As taxas são percentuais sobre todos os alunos com resultado registrado. Situacoes detalha os motivos de aprovação e
+// reprovação
+type EstatisticasDisciplina struct {
+	DisciplinaId   string            `json:"disciplina_id"`
+	Medias         EstatisticasNotas `json:"medias"`
+	Frequencias    EstatisticasNotas `json:"frequencias"`
+	TaxaAprovacao  float64           `json:"taxa_aprovacao"`
+	TaxaReprovacao float64           `json:"taxa_reprovacao"`
+	Situacoes      []TaxaSituacao    `json:"situacoes"`
+}
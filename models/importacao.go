@@ -0,0 +1,113 @@
+package models
+
+import "sistema-alunos-go/utils"
+
+// Ações da importação de planilhas sobre cada linha
+const (
+	ImportacaoCriar      = "criar"
+	ImportacaoAtualizar  = "atualizar"
+	ImportacaoInalterado = "inalterado"
+	ImportacaoIgnorar    = "ignorar"
+)
+
+// ImportacaoPlanilha representa as opções do envio de uma planilha para importação
+//
+// Sem Aplicar, a planilha é apenas validada e a resposta traz a prévia do que seria alterado
+type ImportacaoPlanilha struct {
+	Aplicar bool `form:"aplicar"`
+}
+
+// LinhaImportacaoAluno representa uma linha da planilha de importação de alunos e o que será feito com ela
+//
+// Linha é o número da linha na planilha, contando o cabeçalho. Acao indica se o aluno será criado, terá o nome
+// atualizado ou ficará inalterado, e Matricular, se ele será matriculado na disciplina informada. Linhas com Erros não
+// são importadas
+type LinhaImportacaoAluno struct {
+	Linha        int                     `json:"linha"`
+	Nome         string                  `json:"nome"`
+	Email        string                  `json:"email"`
+	DisciplinaId string                  `json:"disciplina_id,omitempty"`
+	AlunoId      string                  `json:"aluno_id,omitempty"`
+	Acao         string                  `json:"acao,omitempty"`
+	Matricular   bool                    `json:"matricular"`
+	Erros        []utils.ValidationError `json:"erros,omitempty"`
+}
+
+// ResultadoImportacaoAlunos representa o resultado da validação ou da aplicação de uma planilha de alunos
+//
+// Aplicada só é verdadeiro quando as alterações foram gravadas; na prévia, os totais indicam o que seria feito
+type ResultadoImportacaoAlunos struct {
+	Aplicada    bool                   `json:"aplicada"`
+	Validas     int                    `json:"validas"`
+	Invalidas   int                    `json:"invalidas"`
+	Criados     int                    `json:"criados"`
+	Atualizados int                    `json:"atualizados"`
+	Matriculas  int                    `json:"matriculas"`
+	Linhas      []LinhaImportacaoAluno `json:"linhas"`
+}
+
+// ImportacaoNotas representa as opções do envio de uma planilha de notas de uma avaliação
+//
+// A Justificativa é registrada no histórico das notas alteradas; se não informada, é usada a justificativa padrão do
+// lançamento de notas
+type ImportacaoNotas struct {
+	ImportacaoPlanilha
+	Justificativa string `form:"justificativa" binding:"max=500"`
+}
+
+// LinhaImportacaoNota representa uma linha da planilha de notas de uma avaliação e a diferença para a nota já lançada
+//
+// NotaAtual é a nota já lançada para o aluno, na escala da disciplina, e NotaNova, a nota da planilha após a penalidade
+// por atraso. Acao indica se a nota será lançada, atualizada ou ficará inalterada; linhas sem nota são ignoradas e
+// linhas com Erros não são importadas
+type LinhaImportacaoNota struct {
+	Linha     int                     `json:"linha"`
+	AlunoId   string                  `json:"aluno_id,omitempty"`
+	Nome      string                  `json:"nome,omitempty"`
+	NotaAtual string                  `json:"nota_atual,omitempty"`
+	NotaNova  string                  `json:"nota_nova,omitempty"`
+	Acao      string                  `json:"acao,omitempty"`
+	Erros     []utils.ValidationError `json:"erros,omitempty"`
+}
+
+// ResultadoImportacaoNotas representa o resultado da validação ou da aplicação de uma planilha de notas
+//
+// Aplicada só é verdadeiro quando as notas foram gravadas; na prévia, os totais indicam o que seria feito
+type ResultadoImportacaoNotas struct {
+	Aplicada    bool                  `json:"aplicada"`
+	Validas     int                   `json:"validas"`
+	Invalidas   int                   `json:"invalidas"`
+	Lancadas    int                   `json:"lancadas"`
+	Atualizadas int                   `json:"atualizadas"`
+	Inalteradas int                   `json:"inalteradas"`
+	Ignoradas   int                   `json:"ignoradas"`
+	Linhas      []LinhaImportacaoNota `json:"linhas"`
+}
+
+// ContagemImportacao representa quantos registros de um tipo foram criados, atualizados ou mantidos em uma importação
+type ContagemImportacao struct {
+	Criados     int `json:"criados"`
+	Atualizados int `json:"atualizados"`
+	Inalterados int `json:"inalterados"`
+}
+
+// RegistroLegadoRejeitado representa um registro dos arquivos do sistema antigo que não pôde ser importado
+type RegistroLegadoRejeitado struct {
+	Arquivo string `json:"arquivo"`
+	Linha   int    `json:"linha"`
+	Motivo  string `json:"motivo"`
+}
+
+// ResultadoImportacaoLegado representa o resultado da importação dos arquivos do sistema antigo
+//
+// Simulada indica que nada foi gravado e os totais mostram o que seria feito. Matriculas é a quantidade de matrículas
+// criadas para os alunos com notas em disciplinas em que ainda não estavam matriculados
+type ResultadoImportacaoLegado struct {
+	Simulada    bool                      `json:"simulada"`
+	Alunos      ContagemImportacao        `json:"alunos"`
+	Disciplinas ContagemImportacao        `json:"disciplinas"`
+	Avaliacoes  ContagemImportacao        `json:"avaliacoes"`
+	Notas       ContagemImportacao        `json:"notas"`
+	Matriculas  int                       `json:"matriculas"`
+	Rejeitados  []RegistroLegadoRejeitado `json:"rejeitados"`
+}
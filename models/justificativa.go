@@ -0,0 +1,83 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Situações possíveis de uma justificativa de faltas
+const (
+	JustificativaPendente  = "pendente"
+	JustificativaAprovada  = "aprovada"
+	JustificativaRejeitada = "rejeitada"
+)
+
+// Justificativa representa o pedido de abono de faltas de um aluno em uma ou mais aulas de uma disciplina
+//
+// Pode ser enviada pelo próprio aluno ou por um professor e sempre acompanha um documento comprobatório (atestado).
+// Justificativas aprovadas pelo professor da disciplina ou por um coordenador abonam as faltas no cálculo da frequência
+type Justificativa struct {
+	Id              string     `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
+	AlunoId         string     `json:"aluno_id" gorm:"not null;column:aluno_id;type:varchar(36);index"`
+	DisciplinaId    string     `json:"disciplina_id" gorm:"not null;column:disciplina_id;type:varchar(36);index"`
+	Motivo          string     `json:"motivo" gorm:"not null;column:motivo"`
+	Status          string     `json:"status" gorm:"not null;column:status;default:pendente"`
+	SolicitanteId   string     `json:"solicitante_id" gorm:"not null;column:solicitante_id;type:varchar(36)"`
+	SolicitanteTipo string     `json:"solicitante_tipo" gorm:"not null;column:solicitante_tipo"`
+	AvaliadorId     *string    `json:"avaliador_id" gorm:"column:avaliador_id;type:varchar(36)"`
+	Observacao      string     `json:"observacao,omitempty" gorm:"column:observacao"`
+	AvaliadaEm      *time.Time `json:"avaliada_em" gorm:"column:avaliada_em"`
+	DocumentoNome   string     `json:"documento_nome" gorm:"not null;column:documento_nome"`
+	DocumentoTipo   string     `json:"documento_tipo" gorm:"not null;column:documento_tipo"`
+	DocumentoChave  string     `json:"-" gorm:"not null;column:documento_chave"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Aluno      *Aluno              `json:"aluno,omitempty" gorm:"foreignKey:AlunoId;constraint:OnDelete:CASCADE"`
+	Disciplina *Disciplina         `json:"-" gorm:"foreignKey:DisciplinaId;constraint:OnDelete:CASCADE"`
+	Aulas      []JustificativaAula `json:"aulas" gorm:"foreignKey:JustificativaId;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura Justificativa
+func (Justificativa) TableName() string {
+	return "justificativas"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma Justificativa ser criada
+func (j *Justificativa) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	j.Id = uuidStr
+	return
+}
+
+// JustificativaAula associa uma justificativa a cada aula cujas faltas ela pretende abonar
+type JustificativaAula struct {
+	JustificativaId string `json:"-" gorm:"primaryKey;column:justificativa_id;type:varchar(36)"`
+	AulaId          string `json:"aula_id" gorm:"primaryKey;column:aula_id;type:varchar(36)"`
+
+	// Relacionamento
+	Aula *Aula `json:"aula,omitempty" gorm:"foreignKey:AulaId;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura JustificativaAula
+func (JustificativaAula) TableName() string {
+	return "justificativa_aula"
+}
+
+// SolicitacaoJustificativa representa os campos do formulário multipart de envio de uma justificativa
+//
+// O documento comprobatório é enviado no campo de arquivo "documento". AlunoId é obrigatório apenas quando a
+// justificativa é enviada por um professor
+type SolicitacaoJustificativa struct {
+	DisciplinaId string   `form:"disciplina_id" binding:"required"`
+	AlunoId      string   `form:"aluno_id"`
+	AulaIds      []string `form:"aula_ids" binding:"required,min=1"`
+	Motivo       string   `form:"motivo" binding:"required,min=1,max=1000"`
+}
+
+// AvaliacaoJustificativa representa a decisão de um professor sobre uma justificativa
+type AvaliacaoJustificativa struct {
+	Observacao string `json:"observacao" binding:"max=1000"`
+}
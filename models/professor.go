@@ -8,13 +8,15 @@ import (
 
 // Professor representa um professor do sistema
 //
-// Contém dados de identificação, autenticação e o relacionamento com as disciplinas que ministra
+// Contém dados de identificação, autenticação e o relacionamento com as disciplinas que ministra. Professores
+// coordenadores podem avaliar solicitações de todas as disciplinas; o papel é atribuído diretamente no banco de dados
 type Professor struct {
 	Id             string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
 	Nome           string    `json:"nome" gorm:"not null;column:nome" binding:"required,min=1,max=60"`
 	Email          string    `json:"email" gorm:"not null;column:email;uniqueIndex:idx_unique_email_prof" binding:"required,email"`
 	Senha          string    `json:"senha,omitempty" gorm:"not null;column:senha" binding:"required,senha_forte"`
 	ConfirmarSenha string    `json:"confirmar_senha,omitempty" gorm:"-" binding:"required"`
+	Coordenador    bool      `json:"coordenador" gorm:"not null;column:coordenador;default:false"`
 	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
 	UpdatedAt      time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
 
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Tipos de questão do banco de questões
+const (
+	QuestaoMultiplaEscolha = "multipla_escolha"
+	QuestaoVerdadeiroFalso = "verdadeiro_falso"
+	QuestaoNumerica        = "numerica"
+	QuestaoRespostaCurta   = "resposta_curta"
+)
+
+// Questao representa uma questão do banco de questões de uma matéria
+//
+// Como não há um catálogo de disciplinas, a matéria é identificada pelo nome da disciplina, sem diferenciar maiúsculas
+// de minúsculas, e o banco é compartilhado entre os semestres e professores da mesma matéria. O gabarito depende do
+// tipo: a alternativa marcada como correta, RespostaBooleana, RespostaNumerica com a Tolerancia aceita ou a lista de
+// RespostasAceitas, comparadas sem diferenciar maiúsculas, minúsculas e espaços extras
+type Questao struct {
+	Id               string               `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	Materia          string               `json:"materia" gorm:"not null;index" binding:"required,min=1,max=60"`
+	Tipo             string               `json:"tipo" gorm:"not null" binding:"required,oneof=multipla_escolha verdadeiro_falso numerica resposta_curta"`
+	Enunciado        string               `json:"enunciado" gorm:"not null" binding:"required,min=1,max=2000"`
+	Alternativas     []AlternativaQuestao `json:"alternativas,omitempty" gorm:"foreignKey:QuestaoId;constraint:OnDelete:CASCADE" binding:"omitempty,max=10,dive"`
+	RespostaBooleana *bool                `json:"resposta_booleana,omitempty" gorm:"column:resposta_booleana"`
+	RespostaNumerica *float64             `json:"resposta_numerica,omitempty" gorm:"column:resposta_numerica"`
+	Tolerancia       float64              `json:"tolerancia" gorm:"not null;default:0" binding:"gte=0"`
+	RespostasAceitas []string             `json:"respostas_aceitas,omitempty" gorm:"type:jsonb;serializer:json" binding:"omitempty,max=20,dive,min=1,max=200"`
+	ProfessorId      string               `json:"professor_id" gorm:"type:varchar(36);not null;index"`
+	CreatedAt        time.Time            `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt        time.Time            `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Professor *Professor `json:"-" gorm:"foreignKey:ProfessorId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura Questao
+func (Questao) TableName() string {
+	return "questoes"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma Questao ser criada
+func (q *Questao) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	q.Id = uuidStr
+	return
+}
+
+// AlternativaQuestao representa uma alternativa de uma questão de múltipla escolha
+type AlternativaQuestao struct {
+	Id        string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	QuestaoId string `json:"questao_id" gorm:"type:varchar(36);not null;index"`
+	Texto     string `json:"texto" gorm:"not null" binding:"required,min=1,max=500"`
+	Correta   bool   `json:"correta" gorm:"not null;default:false"`
+	Ordem     int    `json:"ordem" gorm:"not null;default:0"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura AlternativaQuestao
+func (AlternativaQuestao) TableName() string {
+	return "alternativas_questao"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma AlternativaQuestao ser criada
+func (a *AlternativaQuestao) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	a.Id = uuidStr
+	return
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Questionario representa uma avaliação aplicada online a partir do banco de questões
+//
+// Cada aluno pode fazer uma única tentativa, iniciada entre Inicio e Fim e com DuracaoMinutos minutos, sem ultrapassar
+// o Fim. Se QuantidadeQuestoes for menor que o total de questões, cada aluno recebe um sorteio dessa quantidade; a
+// ordem das questões e das alternativas também é sorteada por aluno
+type Questionario struct {
+	Id                 string                `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	AvaliacaoId        string                `json:"avaliacao_id" gorm:"type:varchar(36);not null;uniqueIndex"`
+	Inicio             time.Time             `json:"inicio" gorm:"not null"`
+	Fim                time.Time             `json:"fim" gorm:"not null"`
+	DuracaoMinutos     int                   `json:"duracao_minutos" gorm:"not null"`
+	QuantidadeQuestoes int                   `json:"quantidade_questoes" gorm:"not null"`
+	Questoes           []QuestionarioQuestao `json:"questoes" gorm:"foreignKey:QuestionarioId;constraint:OnDelete:CASCADE"`
+	CreatedAt          time.Time             `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt          time.Time             `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Avaliacao *Avaliacao `json:"-" gorm:"foreignKey:AvaliacaoId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura Questionario
+func (Questionario) TableName() string {
+	return "questionarios"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de um Questionario ser criado
+func (q *Questionario) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	q.Id = uuidStr
+	return
+}
+
+// QuestionarioQuestao associa uma questão do banco a um questionário, com os pontos que ela vale
+type QuestionarioQuestao struct {
+	QuestionarioId string  `json:"-" gorm:"primaryKey;type:varchar(36)"`
+	QuestaoId      string  `json:"questao_id" gorm:"primaryKey;type:varchar(36)"`
+	Pontos         float64 `json:"pontos" gorm:"not null"`
+	Ordem          int     `json:"ordem" gorm:"not null"`
+
+	// Relacionamento
+	Questao *Questao `json:"questao,omitempty" gorm:"foreignKey:QuestaoId;references:Id"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura QuestionarioQuestao
+func (QuestionarioQuestao) TableName() string {
+	return "questionario_questao"
+}
+
+// MontagemQuestionario representa os dados enviados pelo professor para montar o questionário de uma avaliação
+//
+// QuantidadeQuestoes igual a zero aplica todas as questões a todos os alunos
+type MontagemQuestionario struct {
+	Inicio             time.Time          `json:"inicio" binding:"required"`
+	Fim                time.Time          `json:"fim" binding:"required,gtfield=Inicio"`
+	DuracaoMinutos     int                `json:"duracao_minutos" binding:"required,gte=1,lte=600"`
+	QuantidadeQuestoes int                `json:"quantidade_questoes" binding:"gte=0"`
+	Questoes           []ItemQuestionario `json:"questoes" binding:"required,min=1,max=100,dive"`
+}
+
+// ItemQuestionario representa uma questão escolhida para o questionário e quantos pontos ela vale
+type ItemQuestionario struct {
+	QuestaoId string  `json:"questao_id" binding:"required"`
+	Pontos    float64 `json:"pontos" binding:"required,gt=0,lte=100"`
+}
+
+// Tentativa representa a realização de um questionário por um aluno
+//
+// A Semente determina o sorteio e a ordem das questões e alternativas apresentadas ao aluno. Após a finalização,
+// pelo aluno ou pelo fim do tempo, a tentativa é corrigida automaticamente e a nota é lançada na avaliação
+type Tentativa struct {
+	Id             string              `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	QuestionarioId string              `json:"questionario_id" gorm:"type:varchar(36);not null;index"`
+	AvaliacaoId    string              `json:"avaliacao_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_tentativa_aluno"`
+	AlunoId        string              `json:"aluno_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_tentativa_aluno"`
+	Semente        int64               `json:"-" gorm:"not null"`
+	IniciadaEm     time.Time           `json:"iniciada_em" gorm:"not null"`
+	ExpiraEm       time.Time           `json:"expira_em" gorm:"not null"`
+	FinalizadaEm   *time.Time          `json:"finalizada_em"`
+	Pontos         float64             `json:"pontos" gorm:"not null;default:0"`
+	PontosTotal    float64             `json:"pontos_total" gorm:"not null;default:0"`
+	Nota           float64             `json:"nota" gorm:"not null;default:0"`
+	NotaEscala     string              `json:"nota_escala" gorm:"not null;default:''"`
+	Respostas      []RespostaTentativa `json:"respostas,omitempty" gorm:"foreignKey:TentativaId;constraint:OnDelete:CASCADE"`
+
+	// Relacionamentos
+	Aluno        *Aluno        `json:"aluno,omitempty" gorm:"foreignKey:AlunoId;references:Id;constraint:OnDelete:CASCADE"`
+	Questionario *Questionario `json:"-" gorm:"foreignKey:QuestionarioId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura Tentativa
+func (Tentativa) TableName() string {
+	return "tentativas"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma Tentativa ser criada
+func (t *Tentativa) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	t.Id = uuidStr
+	return
+}
+
+// Finalizada indica se a tentativa já foi encerrada e corrigida
+func (t *Tentativa) Finalizada() bool {
+	return t.FinalizadaEm != nil
+}
+
+// RespostaTentativa representa a resposta de um aluno a uma questão durante uma tentativa
+//
+// Correta e Pontos são preenchidos na correção automática da tentativa
+type RespostaTentativa struct {
+	Id          string  `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TentativaId string  `json:"tentativa_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_resposta_tentativa"`
+	QuestaoId   string  `json:"questao_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_resposta_tentativa"`
+	Resposta    string  `json:"resposta" gorm:"not null"`
+	Correta     bool    `json:"correta" gorm:"not null;default:false"`
+	Pontos      float64 `json:"pontos" gorm:"not null;default:0"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura RespostaTentativa
+func (RespostaTentativa) TableName() string {
+	return "respostas_tentativa"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma RespostaTentativa ser criada
+func (r *RespostaTentativa) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	r.Id = uuidStr
+	return
+}
+
+// RespostaQuestao representa a resposta enviada por um aluno para uma questão
+//
+// Em múltipla escolha, a resposta é o ID da alternativa; em verdadeiro ou falso, "verdadeiro" ou "falso"; nas demais,
+// o valor ou texto respondido
+type RespostaQuestao struct {
+	QuestaoId string `json:"questao_id" binding:"required"`
+	Resposta  string `json:"resposta" binding:"max=500"`
+}
+
+// ProvaAluno representa o questionário como apresentado a um aluno, sem o gabarito
+//
+// Nota e NotaEscala só são preenchidas após a finalização da tentativa
+type ProvaAluno struct {
+	TentativaId  string         `json:"tentativa_id"`
+	AvaliacaoId  string         `json:"avaliacao_id"`
+	IniciadaEm   time.Time      `json:"iniciada_em"`
+	ExpiraEm     time.Time      `json:"expira_em"`
+	FinalizadaEm *time.Time     `json:"finalizada_em"`
+	Pontos       *float64       `json:"pontos,omitempty"`
+	PontosTotal  float64        `json:"pontos_total"`
+	Nota         *float64       `json:"nota,omitempty"`
+	NotaEscala   string         `json:"nota_escala,omitempty"`
+	Questoes     []QuestaoAluno `json:"questoes"`
+}
+
+// QuestaoAluno representa uma questão apresentada ao aluno, com a resposta já enviada por ele
+type QuestaoAluno struct {
+	Id           string             `json:"id"`
+	Tipo         string             `json:"tipo"`
+	Enunciado    string             `json:"enunciado"`
+	Pontos       float64            `json:"pontos"`
+	Alternativas []AlternativaAluno `json:"alternativas,omitempty"`
+	Resposta     string             `json:"resposta"`
+}
+
+// AlternativaAluno representa uma alternativa apresentada ao aluno, sem a indicação da correta
+type AlternativaAluno struct {
+	Id    string `json:"id"`
+	Texto string `json:"texto"`
+}
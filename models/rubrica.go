@@ -0,0 +1,152 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Rubrica representa os critérios de correção de um trabalho
+//
+// Cada critério possui níveis de desempenho com a pontuação atribuída a cada um. A nota do aluno na avaliação é
+// calculada a partir dos níveis escolhidos em cada critério, proporcionalmente a PontuacaoMaxima, que é a soma dos
+// maiores pontos de cada critério. Cada avaliação possui no máximo uma rubrica
+type Rubrica struct {
+	Id              string            `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	AvaliacaoId     string            `json:"avaliacao_id" gorm:"type:varchar(36);not null;uniqueIndex"`
+	DisciplinaId    string            `json:"disciplina_id" gorm:"type:varchar(36);not null;index"`
+	PontuacaoMaxima float64           `json:"pontuacao_maxima" gorm:"not null"`
+	Criterios       []CriterioRubrica `json:"criterios" gorm:"foreignKey:RubricaId;constraint:OnDelete:CASCADE" binding:"required,min=1,max=20,dive"`
+	CreatedAt       time.Time         `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt       time.Time         `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Avaliacao *Avaliacao `json:"-" gorm:"foreignKey:AvaliacaoId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura Rubrica
+func (Rubrica) TableName() string {
+	return "rubricas"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma Rubrica ser criada
+func (r *Rubrica) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	r.Id = uuidStr
+	return
+}
+
+// CriterioRubrica representa um critério de correção de uma rubrica e seus níveis de desempenho
+type CriterioRubrica struct {
+	Id        string         `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	RubricaId string         `json:"rubrica_id" gorm:"type:varchar(36);not null;index"`
+	Nome      string         `json:"nome" gorm:"not null" binding:"required,min=1,max=60"`
+	Descricao string         `json:"descricao" gorm:"not null;default:''" binding:"max=500"`
+	Ordem     int            `json:"ordem" gorm:"not null;default:0"`
+	Niveis    []NivelRubrica `json:"niveis" gorm:"foreignKey:CriterioId;constraint:OnDelete:CASCADE" binding:"required,min=2,max=10,dive"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura CriterioRubrica
+func (CriterioRubrica) TableName() string {
+	return "criterios_rubrica"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de um CriterioRubrica ser criado
+func (c *CriterioRubrica) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	c.Id = uuidStr
+	return
+}
+
+// NivelRubrica representa um nível de desempenho de um critério e os pontos atribuídos a ele
+type NivelRubrica struct {
+	Id         string  `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	CriterioId string  `json:"criterio_id" gorm:"type:varchar(36);not null;index"`
+	Nome       string  `json:"nome" gorm:"not null" binding:"required,min=1,max=60"`
+	Descricao  string  `json:"descricao" gorm:"not null;default:''" binding:"max=500"`
+	Pontos     float64 `json:"pontos" gorm:"not null" binding:"gte=0,lte=1000"`
+	Ordem      int     `json:"ordem" gorm:"not null;default:0"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura NivelRubrica
+func (NivelRubrica) TableName() string {
+	return "niveis_rubrica"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de um NivelRubrica ser criado
+func (n *NivelRubrica) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	n.Id = uuidStr
+	return
+}
+
+// AlunoRubrica representa o nível que um aluno alcançou em um critério da rubrica de uma avaliação
+//
+// Guarda os pontos do nível no momento da correção e o comentário do professor sobre o critério. Cada aluno possui no
+// máximo um registro por critério
+type AlunoRubrica struct {
+	Id          string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	AlunoId     string    `json:"aluno_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_aluno_rubrica_unico"`
+	AvaliacaoId string    `json:"avaliacao_id" gorm:"type:varchar(36);not null;index"`
+	CriterioId  string    `json:"criterio_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_aluno_rubrica_unico"`
+	NivelId     string    `json:"nivel_id" gorm:"type:varchar(36);not null"`
+	Pontos      float64   `json:"pontos" gorm:"not null"`
+	Feedback    string    `json:"feedback" gorm:"not null;default:''"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Aluno    *Aluno           `json:"-" gorm:"foreignKey:AlunoId;references:Id;constraint:OnDelete:CASCADE"`
+	Criterio *CriterioRubrica `json:"-" gorm:"foreignKey:CriterioId;references:Id;constraint:OnDelete:CASCADE"`
+	Nivel    *NivelRubrica    `json:"-" gorm:"foreignKey:NivelId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura AlunoRubrica
+func (AlunoRubrica) TableName() string {
+	return "aluno_rubrica"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de um AlunoRubrica ser criado
+func (ar *AlunoRubrica) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	ar.Id = uuidStr
+	return
+}
+
+// CorrecaoRubrica representa a correção de um aluno pela rubrica da avaliação
+//
+// Todos os critérios da rubrica devem ser informados, cada um uma única vez. A justificativa é registrada no
+// histórico de notas
+type CorrecaoRubrica struct {
+	Criterios     []CorrecaoCriterio `json:"criterios" binding:"required,min=1,dive"`
+	Justificativa string             `json:"justificativa" binding:"max=500"`
+}
+
+// CorrecaoCriterio representa o nível escolhido para um aluno em um critério e o comentário sobre o critério
+type CorrecaoCriterio struct {
+	CriterioId string `json:"criterio_id" binding:"required"`
+	NivelId    string `json:"nivel_id" binding:"required"`
+	Feedback   string `json:"feedback" binding:"max=1000"`
+}
+
+// FeedbackRubrica representa a correção de um aluno por rubrica, com a nota calculada e o retorno de cada critério
+type FeedbackRubrica struct {
+	AvaliacaoId     string             `json:"avaliacao_id"`
+	AlunoId         string             `json:"aluno_id"`
+	Nota            float64            `json:"nota"`
+	NotaEscala      string             `json:"nota_escala"`
+	Pontos          float64            `json:"pontos"`
+	PontuacaoMaxima float64            `json:"pontuacao_maxima"`
+	Criterios       []FeedbackCriterio `json:"criterios"`
+}
+
+// FeedbackCriterio representa o resultado de um aluno em um critério da rubrica
+type FeedbackCriterio struct {
+	CriterioId     string  `json:"criterio_id"`
+	Criterio       string  `json:"criterio"`
+	Nivel          string  `json:"nivel"`
+	DescricaoNivel string  `json:"descricao_nivel"`
+	Pontos         float64 `json:"pontos"`
+	PontosMaximos  float64 `json:"pontos_maximos"`
+	Feedback       string  `json:"feedback"`
+}
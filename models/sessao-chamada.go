@@ -0,0 +1,91 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// SessaoChamada representa um período em que os alunos podem registrar a própria presença em uma aula
+//
+// Enquanto aberta, a sessão gera um código curto que muda a cada IntervaloSegundos a partir do segredo da sessão.
+// Ao expirar ou ser encerrada pelo professor, os alunos que não fizeram check-in são marcados como ausentes
+type SessaoChamada struct {
+	Id                string     `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
+	AulaId            string     `json:"aula_id" gorm:"not null;column:aula_id;type:varchar(36);index"`
+	ProfessorId       string     `json:"professor_id" gorm:"not null;column:professor_id;type:varchar(36)"`
+	Segredo           string     `json:"-" gorm:"not null;column:segredo"`
+	IntervaloSegundos int        `json:"intervalo_segundos" gorm:"not null;column:intervalo_segundos"`
+	ExpiraEm          time.Time  `json:"expira_em" gorm:"not null;column:expira_em;index"`
+	EncerradaEm       *time.Time `json:"encerrada_em" gorm:"column:encerrada_em"`
+	CreatedAt         time.Time  `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt         time.Time  `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Aula     *Aula            `json:"-" gorm:"foreignKey:AulaId;constraint:OnDelete:CASCADE"`
+	CheckIns []CheckInChamada `json:"check_ins,omitempty" gorm:"foreignKey:SessaoId;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura SessaoChamada
+func (SessaoChamada) TableName() string {
+	return "sessoes_chamada"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma SessaoChamada ser criada
+func (s *SessaoChamada) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	s.Id = uuidStr
+	return
+}
+
+// CheckInChamada registra que um aluno informou um código válido durante uma sessão de chamada
+type CheckInChamada struct {
+	SessaoId  string    `json:"sessao_id" gorm:"primaryKey;column:sessao_id;type:varchar(36)"`
+	AlunoId   string    `json:"aluno_id" gorm:"primaryKey;column:aluno_id;type:varchar(36)"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura CheckInChamada
+func (CheckInChamada) TableName() string {
+	return "check_in_chamada"
+}
+
+// FalhasCheckIn conta os códigos inválidos informados por um aluno em uma sessão de chamada
+//
+// Ao atingir o limite de falhas, o aluno não consegue mais fazer check-in na sessão, o que impede a descoberta do
+// código por tentativa e erro
+type FalhasCheckIn struct {
+	SessaoId  string    `json:"sessao_id" gorm:"primaryKey;column:sessao_id;type:varchar(36)"`
+	AlunoId   string    `json:"aluno_id" gorm:"primaryKey;column:aluno_id;type:varchar(36)"`
+	Falhas    int       `json:"falhas" gorm:"not null;column:falhas;default:0"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Sessao *SessaoChamada `json:"-" gorm:"foreignKey:SessaoId;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura FalhasCheckIn
+func (FalhasCheckIn) TableName() string {
+	return "falhas_check_in"
+}
+
+// AberturaChamada representa os parâmetros de abertura de uma sessão de chamada
+//
+// IntervaloSegundos é opcional e define de quanto em quanto tempo o código muda (padrão de 30 segundos)
+type AberturaChamada struct {
+	DuracaoMinutos    int `json:"duracao_minutos" binding:"required,gte=1,lte=240"`
+	IntervaloSegundos int `json:"intervalo_segundos" binding:"omitempty,gte=10,lte=300"`
+}
+
+// CheckIn representa o código informado por um aluno para registrar sua presença
+type CheckIn struct {
+	Codigo string `json:"codigo" binding:"required,len=6,numeric"`
+}
+
+// CodigoChamada representa o código vigente de uma sessão de chamada aberta
+type CodigoChamada struct {
+	SessaoId  string    `json:"sessao_id"`
+	Codigo    string    `json:"codigo"`
+	ValidoAte time.Time `json:"valido_ate"`
+	ExpiraEm  time.Time `json:"expira_em"`
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Eventos que podem ser assinados por webhooks
+const (
+	EventoAlunoCadastrado  = "aluno.cadastrado"
+	EventoAlunoMatriculado = "aluno.matriculado"
+	EventoAulaRegistrada   = "aula.registrada"
+	EventoNotaLancada      = "nota.lancada"
+	EventoSemestreFechado  = "semestre.fechado"
+)
+
+// Situações de um envio de webhook
+const (
+	EnvioPendente   = "pendente"
+	EnvioEntregue   = "entregue"
+	EnvioAbandonado = "abandonado"
+)
+
+// AssinaturaWebhook representa a inscrição de um sistema externo para receber eventos por webhook
+//
+// Cada evento assinado é enviado por POST à URL, assinado com HMAC-SHA256 usando o segredo. O segredo só é
+// retornado no cadastro; assinaturas inativas não recebem novos eventos. ProfessorId identifica o coordenador que
+// cadastrou a assinatura e fica nulo se ele for removido, sem interromper as entregas
+type AssinaturaWebhook struct {
+	Id          string    `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
+	Url         string    `json:"url" gorm:"not null;column:url;type:text"`
+	Segredo     string    `json:"segredo,omitempty" gorm:"not null;column:segredo"`
+	Eventos     []string  `json:"eventos" gorm:"not null;column:eventos;type:jsonb;serializer:json"`
+	Descricao   string    `json:"descricao" gorm:"not null;column:descricao;default:''"`
+	Ativa       bool      `json:"ativa" gorm:"not null;column:ativa;default:true"`
+	ProfessorId *string   `json:"professor_id" gorm:"column:professor_id;type:varchar(36)"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Professor *Professor `json:"-" gorm:"foreignKey:ProfessorId;constraint:OnDelete:SET NULL"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura AssinaturaWebhook
+func (AssinaturaWebhook) TableName() string {
+	return "assinaturas_webhook"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de uma AssinaturaWebhook ser criada
+func (a *AssinaturaWebhook) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	a.Id = uuidStr
+	return
+}
+
+// Assina indica se a assinatura está ativa e inclui o evento informado
+func (a *AssinaturaWebhook) Assina(evento string) bool {
+	if !a.Ativa {
+		return false
+	}
+	for _, assinado := range a.Eventos {
+		if assinado == evento {
+			return true
+		}
+	}
+	return false
+}
+
+// CadastroAssinaturaWebhook representa os dados para inscrever um sistema externo em eventos por webhook
+//
+// Se o segredo não for informado, um segredo aleatório é gerado
+type CadastroAssinaturaWebhook struct {
+	Url       string   `json:"url" binding:"required,url,max=2000"`
+	Eventos   []string `json:"eventos" binding:"required,min=1,dive,oneof=aluno.cadastrado aluno.matriculado aula.registrada nota.lancada semestre.fechado"`
+	Segredo   string   `json:"segredo" binding:"omitempty,min=16,max=200"`
+	Descricao string   `json:"descricao" binding:"max=200"`
+}
+
+// AtualizacaoAssinaturaWebhook representa a alteração parcial de uma assinatura de webhook
+//
+// Apenas os campos informados são alterados
+type AtualizacaoAssinaturaWebhook struct {
+	Url       *string  `json:"url" binding:"omitempty,url,max=2000"`
+	Eventos   []string `json:"eventos" binding:"omitempty,min=1,dive,oneof=aluno.cadastrado aluno.matriculado aula.registrada nota.lancada semestre.fechado"`
+	Descricao *string  `json:"descricao" binding:"omitempty,max=200"`
+	Ativa     *bool    `json:"ativa"`
+}
+
+// EnvioWebhook representa a entrega de um evento a uma assinatura de webhook e compõe o registro de entregas
+//
+// EventoId identifica o evento e é o mesmo nos envios de todas as assinaturas e nos reenvios. Enquanto pendente,
+// o envio é tentado em ProximaTentativa; após esgotar as tentativas, é abandonado. UltimoStatus e UltimoErro
+// descrevem a última tentativa. ReenvioDe aponta para o envio original quando o envio foi solicitado manualmente
+type EnvioWebhook struct {
+	Id               string     `json:"id" gorm:"primaryKey;column:id;type:varchar(36)"`
+	AssinaturaId     string     `json:"assinatura_id" gorm:"not null;column:assinatura_id;type:varchar(36);index"`
+	EventoId         string     `json:"evento_id" gorm:"not null;column:evento_id;type:varchar(36);index"`
+	Evento           string     `json:"evento" gorm:"not null;column:evento"`
+	Corpo            string     `json:"corpo" gorm:"not null;column:corpo;type:text"`
+	Situacao         string     `json:"situacao" gorm:"not null;column:situacao;index:idx_envio_webhook_pendente"`
+	Tentativas       int        `json:"tentativas" gorm:"not null;column:tentativas;default:0"`
+	ProximaTentativa *time.Time `json:"proxima_tentativa" gorm:"column:proxima_tentativa;index:idx_envio_webhook_pendente"`
+	UltimoStatus     int        `json:"ultimo_status" gorm:"not null;column:ultimo_status;default:0"`
+	UltimoErro       string     `json:"ultimo_erro" gorm:"not null;column:ultimo_erro;default:''"`
+	UltimaResposta   string     `json:"ultima_resposta" gorm:"not null;column:ultima_resposta;default:''"`
+	EntregueEm       *time.Time `json:"entregue_em" gorm:"column:entregue_em"`
+	ReenvioDe        *string    `json:"reenvio_de,omitempty" gorm:"column:reenvio_de;type:varchar(36)"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime;column:created_at;not null"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime;column:updated_at;not null"`
+
+	// Relacionamentos
+	Assinatura *AssinaturaWebhook `json:"-" gorm:"foreignKey:AssinaturaId;constraint:OnDelete:CASCADE"`
+}
+
+// TableName especifica o nome da tabela do banco de dados para a estrutura EnvioWebhook
+func (EnvioWebhook) TableName() string {
+	return "envios_webhook"
+}
+
+// BeforeCreate é usado para o GORM que gera e atribui uma nova string UUID ao campo Id antes de um EnvioWebhook ser criado
+func (e *EnvioWebhook) BeforeCreate(_ *gorm.DB) (err error) {
+	uuidStr := uuid.New().String()
+	e.Id = uuidStr
+	return
+}
+
+// EventoWebhook é o corpo JSON enviado em cada entrega de webhook
+//
+// Dados traz o registro afetado pelo evento: o aluno em aluno.cadastrado, a matrícula em aluno.matriculado, a aula
+// com as presenças em aula.registrada, um NotaLancada em nota.lancada e um SemestreFechado em semestre.fechado
+type EventoWebhook struct {
+	Id         string      `json:"id"`
+	Evento     string      `json:"evento"`
+	OcorridoEm time.Time   `json:"ocorrido_em"`
+	Dados      interface{} `json:"dados"`
+}
+
+// NotaLancada representa os dados do evento nota.lancada
+//
+// NotaAnterior é nula quando a nota foi lançada pela primeira vez
+type NotaLancada struct {
+	AlunoAvaliacao
+	NotaAnterior *float64 `json:"nota_anterior"`
+	AutorTipo    string   `json:"autor_tipo"`
+}
+
+// SemestreFechado representa os dados do evento semestre.fechado
+//
+// É enviado ao fechar o semestre e, se houver recuperação, novamente ao fechar a recuperação, com Situacao indicando a
+// nova situação da disciplina e Resultados apenas com as médias calculadas em cada etapa
+type SemestreFechado struct {
+	DisciplinaId string       `json:"disciplina_id"`
+	Disciplina   string       `json:"disciplina"`
+	AnoSemestre  string       `json:"ano_semestre"`
+	Situacao     string       `json:"situacao"`
+	Resultados   []AlunoMedia `json:"resultados"`
+}
@@ -0,0 +1,265 @@
+// Package planilhas lê e escreve as planilhas trocadas com os usuários, em CSV ou XLSX, como listas de linhas de texto
+//
+// O conteúdo é interpretado sem depender do banco de dados; a validação de cada linha fica a cargo de quem usa o pacote
+package planilhas
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Formatos de planilha aceitos
+const (
+	FormatoCsv  = "csv"
+	FormatoXlsx = "xlsx"
+)
+
+// caracteresFormula são os caracteres que, no início de uma célula, levam os programas de planilha a interpretá-la
+// como fórmula
+const caracteresFormula = "=+-@\t\r"
+
+// ErrFormatoInvalido indica que o arquivo não é uma planilha CSV ou XLSX
+var ErrFormatoInvalido = errors.New("a planilha deve ser um arquivo .csv ou .xlsx")
+
+// Formato retorna o formato de uma planilha a partir da extensão do nome do arquivo
+//
+// Retorna ErrFormatoInvalido se a extensão não for .csv nem .xlsx
+func Formato(nomeArquivo string) (string, error) {
+	switch strings.ToLower(filepath.Ext(nomeArquivo)) {
+	case ".csv":
+		return FormatoCsv, nil
+	case ".xlsx":
+		return FormatoXlsx, nil
+	}
+	return "", ErrFormatoInvalido
+}
+
+// Le lê todas as linhas de uma planilha no formato informado
+//
+// Planilhas CSV podem usar vírgula ou ponto e vírgula como separador, identificado pela primeira linha, e o BOM do
+// UTF-8 no início é ignorado, assim como o apóstrofo incluído por Escreve antes de valores que seriam fórmulas. De
+// planilhas XLSX, apenas a primeira aba é lida. Os valores são retornados sem espaços nas pontas
+func Le(formato string, origem io.Reader) ([][]string, error) {
+	var linhas [][]string
+	var err error
+
+	switch formato {
+	case FormatoCsv:
+		linhas, err = leCsv(origem)
+	case FormatoXlsx:
+		linhas, err = leXlsx(origem)
+	default:
+		return nil, ErrFormatoInvalido
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, linha := range linhas {
+		for i := range linha {
+			linha[i] = strings.TrimSpace(linha[i])
+			if formato == FormatoCsv {
+				linha[i] = desprotegeCelula(linha[i])
+			}
+		}
+	}
+	return linhas, nil
+}
+
+// Escreve grava as linhas informadas em uma planilha no formato informado, com a primeira linha como cabeçalho
+//
+// Planilhas CSV usam ponto e vírgula como separador e começam com o BOM do UTF-8, para que as planilhas em português
+// reconheçam as colunas e os acentos, e os valores que seriam interpretados como fórmula são protegidos por
+// ProtegeCelula. Em planilhas XLSX, as linhas são gravadas como texto em uma aba com o nome informado, com o cabeçalho
+// em negrito e fixo no topo
+func Escreve(formato string, aba string, linhas [][]string, destino io.Writer) error {
+	switch formato {
+	case FormatoCsv:
+		return escreveCsv(linhas, destino)
+	case FormatoXlsx:
+		return escreveXlsx(aba, linhas, destino)
+	}
+	return ErrFormatoInvalido
+}
+
+// ProtegeCelula prefixa com um apóstrofo os valores de CSV que seriam interpretados como fórmula ao abrir o arquivo
+// em um programa de planilha
+//
+// Valores de um único caractere, como o traço usado em campos vazios, não formam fórmulas e são mantidos
+func ProtegeCelula(valor string) string {
+	if len(valor) > 1 && strings.ContainsRune(caracteresFormula, rune(valor[0])) {
+		return "'" + valor
+	}
+	return valor
+}
+
+// desprotegeCelula remove o apóstrofo incluído por ProtegeCelula
+func desprotegeCelula(valor string) string {
+	if len(valor) > 2 && valor[0] == '\'' && strings.ContainsRune(caracteresFormula, rune(valor[1])) {
+		return valor[1:]
+	}
+	return valor
+}
+
+// Colunas localiza as colunas de uma planilha pelo cabeçalho
+//
+// `nomes` associa cada coluna aos títulos aceitos para ela; a comparação ignora maiúsculas, minúsculas e espaços
+// extras. Retorna a posição de cada coluna encontrada
+func Colunas(cabecalho []string, nomes map[string][]string) map[string]int {
+	posicoes := make(map[string]int, len(nomes))
+	for i, titulo := range cabecalho {
+		titulo = normalizaTitulo(titulo)
+		for coluna, aceitos := range nomes {
+			for _, aceito := range aceitos {
+				if _, ok := posicoes[coluna]; !ok && titulo == normalizaTitulo(aceito) {
+					posicoes[coluna] = i
+				}
+			}
+		}
+	}
+	return posicoes
+}
+
+// Valor retorna o valor de uma coluna em uma linha, ou uma string vazia se a coluna não existir ou a linha for curta
+func Valor(linha []string, colunas map[string]int, coluna string) string {
+	posicao, ok := colunas[coluna]
+	if !ok || posicao >= len(linha) {
+		return ""
+	}
+	return linha[posicao]
+}
+
+// Vazia indica se todos os valores de uma linha estão em branco
+func Vazia(linha []string) bool {
+	for _, valor := range linha {
+		if strings.TrimSpace(valor) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// leCsv lê uma planilha CSV, identificando o separador pela primeira linha
+func leCsv(origem io.Reader) ([][]string, error) {
+	conteudo, err := io.ReadAll(origem)
+	if err != nil {
+		return nil, err
+	}
+	conteudo = bytes.TrimPrefix(conteudo, []byte("\ufeff"))
+
+	primeira, _, _ := bytes.Cut(conteudo, []byte("\n"))
+	leitor := csv.NewReader(bytes.NewReader(conteudo))
+	leitor.FieldsPerRecord = -1
+	if bytes.Count(primeira, []byte(";")) > bytes.Count(primeira, []byte(",")) {
+		leitor.Comma = ';'
+	}
+
+	return leitor.ReadAll()
+}
+
+// leXlsx lê a primeira aba de uma planilha XLSX
+func leXlsx(origem io.Reader) ([][]string, error) {
+	arquivo, err := excelize.OpenReader(origem)
+	if err != nil {
+		return nil, err
+	}
+	defer arquivo.Close()
+
+	abas := arquivo.GetSheetList()
+	if len(abas) == 0 {
+		return nil, nil
+	}
+	return arquivo.GetRows(abas[0])
+}
+
+// escreveCsv grava as linhas em CSV separado por ponto e vírgula, precedido do BOM do UTF-8, protegendo os valores
+// que seriam interpretados como fórmula
+func escreveCsv(linhas [][]string, destino io.Writer) error {
+	if _, err := io.WriteString(destino, "\ufeff"); err != nil {
+		return err
+	}
+
+	escritor := csv.NewWriter(destino)
+	escritor.Comma = ';'
+	for _, linha := range linhas {
+		protegida := make([]string, len(linha))
+		for i, valor := range linha {
+			protegida[i] = ProtegeCelula(valor)
+		}
+		if err := escritor.Write(protegida); err != nil {
+			return err
+		}
+	}
+	escritor.Flush()
+	return escritor.Error()
+}
+
+// escreveXlsx grava as linhas como texto em uma planilha XLSX de uma única aba
+func escreveXlsx(aba string, linhas [][]string, destino io.Writer) error {
+	arquivo := excelize.NewFile()
+	defer arquivo.Close()
+
+	if err := arquivo.SetSheetName(arquivo.GetSheetName(0), aba); err != nil {
+		return err
+	}
+
+	larguras := make([]int, 0)
+	for i, linha := range linhas {
+		celula, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+
+		valores := make([]interface{}, len(linha))
+		for j, valor := range linha {
+			valores[j] = valor
+			if j >= len(larguras) {
+				larguras = append(larguras, 0)
+			}
+			larguras[j] = max(larguras[j], len([]rune(valor)))
+		}
+		if err := arquivo.SetSheetRow(aba, celula, &valores); err != nil {
+			return err
+		}
+	}
+
+	for j, largura := range larguras {
+		coluna, err := excelize.ColumnNumberToName(j + 1)
+		if err != nil {
+			return err
+		}
+		if err := arquivo.SetColWidth(aba, coluna, coluna, float64(min(largura, 60)+2)); err != nil {
+			return err
+		}
+	}
+
+	if len(linhas) > 0 {
+		negrito, err := arquivo.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+		if err != nil {
+			return err
+		}
+		ultima, err := excelize.CoordinatesToCellName(max(len(linhas[0]), 1), 1)
+		if err != nil {
+			return err
+		}
+		if err := arquivo.SetCellStyle(aba, "A1", ultima, negrito); err != nil {
+			return err
+		}
+		err = arquivo.SetPanes(aba, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+		if err != nil {
+			return err
+		}
+	}
+
+	return arquivo.Write(destino)
+}
+
+// normalizaTitulo prepara o título de uma coluna para comparação, ignorando maiúsculas, minúsculas e espaços extras
+func normalizaTitulo(titulo string) string {
+	return strings.ToLower(strings.Join(strings.Fields(titulo), " "))
+}
@@ -0,0 +1,243 @@
+package planilhas
+
+import (
+	"bytes"
+	"errors"
+	"github.com/xuri/excelize/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormato(t *testing.T) {
+	casos := []struct {
+		nome    string
+		formato string
+		erro    error
+	}{
+		{"alunos.csv", FormatoCsv, nil},
+		{"Notas.XLSX", FormatoXlsx, nil},
+		{"dir.v2/notas.csv", FormatoCsv, nil},
+		{"notas.xls", "", ErrFormatoInvalido},
+		{"notas", "", ErrFormatoInvalido},
+	}
+
+	for _, caso := range casos {
+		formato, err := Formato(caso.nome)
+		if formato != caso.formato || !errors.Is(err, caso.erro) {
+			t.Errorf("Formato(%q) = %q, %v; esperado %q, %v", caso.nome, formato, err, caso.formato, caso.erro)
+		}
+	}
+}
+
+func TestLeCsv(t *testing.T) {
+	casos := []struct {
+		nome      string
+		conteudo  string
+		esperadas [][]string
+	}{
+		{
+			nome:      "separado por vírgula",
+			conteudo:  "nome,email\nAna,ana@escola.br\n",
+			esperadas: [][]string{{"nome", "email"}, {"Ana", "ana@escola.br"}},
+		},
+		{
+			nome:      "separado por ponto e vírgula com BOM",
+			conteudo:  "\ufeffnome;email\r\nAna;ana@escola.br\r\n",
+			esperadas: [][]string{{"nome", "email"}, {"Ana", "ana@escola.br"}},
+		},
+		{
+			nome:      "vírgula decimal com ponto e vírgula no cabeçalho",
+			conteudo:  "aluno;nota\nAna;7,5\n",
+			esperadas: [][]string{{"aluno", "nota"}, {"Ana", "7,5"}},
+		},
+		{
+			nome:      "separador identificado apenas pela primeira linha",
+			conteudo:  "nome,email\n\"Silva; Ana\",ana@escola.br\n",
+			esperadas: [][]string{{"nome", "email"}, {"Silva; Ana", "ana@escola.br"}},
+		},
+		{
+			nome:      "espaços nas pontas e linhas com tamanhos diferentes",
+			conteudo:  " nome ; email \n Ana \nBia;bia@escola.br;extra\n",
+			esperadas: [][]string{{"nome", "email"}, {"Ana"}, {"Bia", "bia@escola.br", "extra"}},
+		},
+		{
+			nome:      "vazia",
+			conteudo:  "",
+			esperadas: nil,
+		},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			linhas, err := Le(FormatoCsv, strings.NewReader(caso.conteudo))
+			if err != nil {
+				t.Fatalf("Le retornou erro: %v", err)
+			}
+			if !reflect.DeepEqual(linhas, caso.esperadas) {
+				t.Errorf("linhas = %q, esperado %q", linhas, caso.esperadas)
+			}
+		})
+	}
+}
+
+func TestLeFormatoInvalido(t *testing.T) {
+	if _, err := Le("ods", strings.NewReader("")); !errors.Is(err, ErrFormatoInvalido) {
+		t.Errorf("Le com formato ods retornou %v, esperado ErrFormatoInvalido", err)
+	}
+	if _, err := Le(FormatoXlsx, strings.NewReader("nome;email\n")); err == nil {
+		t.Error("Le não retornou erro para um CSV enviado como XLSX")
+	}
+	if _, err := Le(FormatoCsv, strings.NewReader("nome,email\n\"Ana,ana@escola.br\n")); err == nil {
+		t.Error("Le não retornou erro para um CSV com aspas sem fechamento")
+	}
+}
+
+func TestEscreveCsv(t *testing.T) {
+	var destino bytes.Buffer
+	linhas := [][]string{{"Aluno", "Nota"}, {"José; da Silva", "7,5"}, {"Ana", ""}}
+	if err := Escreve(FormatoCsv, "Notas", linhas, &destino); err != nil {
+		t.Fatalf("Escreve retornou erro: %v", err)
+	}
+
+	esperado := "\ufeffAluno;Nota\n\"José; da Silva\";7,5\nAna;\n"
+	if destino.String() != esperado {
+		t.Errorf("CSV = %q, esperado %q", destino.String(), esperado)
+	}
+
+	lidas, err := Le(FormatoCsv, &destino)
+	if err != nil {
+		t.Fatalf("Le retornou erro: %v", err)
+	}
+	if !reflect.DeepEqual(lidas, linhas) {
+		t.Errorf("linhas lidas = %q, esperado %q", lidas, linhas)
+	}
+}
+
+func TestProtegeCelula(t *testing.T) {
+	casos := []struct {
+		valor     string
+		protegido string
+	}{
+		{"=HYPERLINK(\"http://exemplo.com\")", "'=HYPERLINK(\"http://exemplo.com\")"},
+		{"+55 11 99999-0000", "'+55 11 99999-0000"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"-", "-"},
+		{"", ""},
+		{"Ana", "Ana"},
+		{"7,5", "7,5"},
+		{"a=b", "a=b"},
+	}
+
+	for _, caso := range casos {
+		if protegido := ProtegeCelula(caso.valor); protegido != caso.protegido {
+			t.Errorf("ProtegeCelula(%q) = %q, esperado %q", caso.valor, protegido, caso.protegido)
+		}
+	}
+}
+
+func TestEscreveCsvProtegeFormulas(t *testing.T) {
+	var destino bytes.Buffer
+	linhas := [][]string{{"Aluno", "Nota"}, {"=1+1", "-"}, {"@Ana", "'nota"}}
+	if err := Escreve(FormatoCsv, "Notas", linhas, &destino); err != nil {
+		t.Fatalf("Escreve retornou erro: %v", err)
+	}
+
+	esperado := "\ufeffAluno;Nota\n'=1+1;-\n'@Ana;'nota\n"
+	if destino.String() != esperado {
+		t.Errorf("CSV = %q, esperado %q", destino.String(), esperado)
+	}
+
+	lidas, err := Le(FormatoCsv, &destino)
+	if err != nil {
+		t.Fatalf("Le retornou erro: %v", err)
+	}
+	if !reflect.DeepEqual(lidas, linhas) {
+		t.Errorf("linhas lidas = %q, esperado %q", lidas, linhas)
+	}
+}
+
+func TestEscreveLeXlsx(t *testing.T) {
+	var destino bytes.Buffer
+	linhas := [][]string{{"Aluno", "E-mail", "Nota"}, {"José", "jose@escola.br", "007"}, {"Ana", "", "7,5"}}
+	if err := Escreve(FormatoXlsx, "Notas", linhas, &destino); err != nil {
+		t.Fatalf("Escreve retornou erro: %v", err)
+	}
+
+	arquivo, err := excelize.OpenReader(bytes.NewReader(destino.Bytes()))
+	if err != nil {
+		t.Fatalf("a planilha gravada não pôde ser aberta: %v", err)
+	}
+	defer arquivo.Close()
+	if abas := arquivo.GetSheetList(); !reflect.DeepEqual(abas, []string{"Notas"}) {
+		t.Errorf("abas = %q, esperado [Notas]", abas)
+	}
+
+	lidas, err := Le(FormatoXlsx, &destino)
+	if err != nil {
+		t.Fatalf("Le retornou erro: %v", err)
+	}
+	// Os valores são gravados como texto: zeros à esquerda e vírgulas decimais são preservados
+	if !reflect.DeepEqual(lidas, linhas) {
+		t.Errorf("linhas lidas = %q, esperado %q", lidas, linhas)
+	}
+}
+
+func TestLeXlsxPrimeiraAba(t *testing.T) {
+	arquivo := excelize.NewFile()
+	defer arquivo.Close()
+	if err := arquivo.SetCellValue("Sheet1", "A1", " Nome "); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := arquivo.NewSheet("Outra"); err != nil {
+		t.Fatal(err)
+	}
+	if err := arquivo.SetCellValue("Outra", "A1", "ignorada"); err != nil {
+		t.Fatal(err)
+	}
+
+	var conteudo bytes.Buffer
+	if err := arquivo.Write(&conteudo); err != nil {
+		t.Fatal(err)
+	}
+
+	linhas, err := Le(FormatoXlsx, &conteudo)
+	if err != nil {
+		t.Fatalf("Le retornou erro: %v", err)
+	}
+	if !reflect.DeepEqual(linhas, [][]string{{"Nome"}}) {
+		t.Errorf("linhas = %q, esperado [[Nome]]", linhas)
+	}
+}
+
+func TestColunasValorVazia(t *testing.T) {
+	nomes := map[string][]string{
+		"nome":  {"Nome", "Aluno"},
+		"email": {"E-mail", "Email"},
+		"nota":  {"Nota"},
+	}
+	colunas := Colunas([]string{" ALUNO ", "e-mail", "Nome"}, nomes)
+	if !reflect.DeepEqual(colunas, map[string]int{"nome": 0, "email": 1}) {
+		t.Errorf("colunas = %v, esperado nome na 0 e email na 1", colunas)
+	}
+
+	linha := []string{"Ana"}
+	if valor := Valor(linha, colunas, "nome"); valor != "Ana" {
+		t.Errorf("Valor(nome) = %q, esperado Ana", valor)
+	}
+	if valor := Valor(linha, colunas, "email"); valor != "" {
+		t.Errorf("Valor(email) em linha curta = %q, esperado vazio", valor)
+	}
+	if valor := Valor(linha, colunas, "nota"); valor != "" {
+		t.Errorf("Valor(nota) sem a coluna = %q, esperado vazio", valor)
+	}
+
+	if !Vazia([]string{"", "  "}) || !Vazia(nil) {
+		t.Error("Vazia não reconheceu uma linha em branco")
+	}
+	if Vazia([]string{"", "x"}) {
+		t.Error("Vazia considerou em branco uma linha com valor")
+	}
+}
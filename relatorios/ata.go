@@ -0,0 +1,149 @@
+package relatorios
+
+import (
+	"github.com/go-pdf/fpdf"
+	"io"
+	"sistema-alunos-go/models"
+	"strconv"
+)
+
+// Dimensões, em milímetros, das colunas fixas da ata final
+const (
+	larguraNumeroAta     = 8.0
+	larguraAlunoAta      = 60.0
+	larguraFrequenciaAta = 18.0
+	larguraMediaAta      = 16.0
+	larguraSituacaoAta   = 42.0
+	larguraMaximaNotaAta = 22.0
+)
+
+// GeraAtaFinal gera a ata final da disciplina em PDF e o grava em `destino`
+//
+// O documento, em paisagem, traz uma linha por aluno com as notas em cada avaliação, a frequência, a média final e a
+// situação, seguida das linhas de assinatura. Frequências de alunos sem resultado registrado são marcadas como
+// parciais
+func GeraAtaFinal(ata *models.AtaFinal, destino io.Writer) error {
+	pdf, tr := novoDocumento("L", "Ata final - "+ata.Disciplina, ata.EmitidoEm)
+
+	pdf.AddPage()
+	cabecalhoDocumento(pdf, tr, "Ata Final", &ata.CabecalhoDisciplina,
+		[2]string{"Avaliações", strconv.Itoa(len(ata.Avaliacoes))},
+		[2]string{"Alunos matriculados", strconv.Itoa(len(ata.Alunos))},
+	)
+
+	tituloSecao(pdf, tr, "Resultados")
+	tabelaAta(pdf, tr, ata)
+
+	for _, aluno := range ata.Alunos {
+		if aluno.Situacao == "" {
+			pdf.Ln(2)
+			pdf.SetFont("Helvetica", "I", 7)
+			pdf.MultiCell(0, 4, tr("* Frequência parcial: o semestre da disciplina ainda não foi fechado para o aluno."), "", "L", false)
+			break
+		}
+	}
+
+	assinaturas(pdf, tr, ata.Professor)
+
+	return pdf.Output(destino)
+}
+
+// GeraAtaFinalCsv gera a ata final da disciplina em CSV e o grava em `destino`
+//
+// Cada linha corresponde a um aluno, com a matrícula, o nome, uma coluna por avaliação com a nota na escala da
+// disciplina, a frequência, a média final e a situação. Notas não lançadas e médias ainda não calculadas ficam vazias
+func GeraAtaFinalCsv(ata *models.AtaFinal, destino io.Writer) error {
+	escritor, err := novoCsv(destino)
+	if err != nil {
+		return err
+	}
+
+	cabecalho := []string{"Matrícula", "Aluno"}
+	for _, avaliacao := range ata.Avaliacoes {
+		cabecalho = append(cabecalho, avaliacao.Nome)
+	}
+	cabecalho = append(cabecalho, "Frequência (%)", "Média final", "Situação")
+	if err := escritor.Write(cabecalho); err != nil {
+		return err
+	}
+
+	for _, aluno := range ata.Alunos {
+		linha := []string{aluno.AlunoId, aluno.Nome}
+		linha = append(linha, aluno.Notas...)
+		media := ""
+		if aluno.MediaFinal != nil {
+			media = aluno.MediaConvertida
+		}
+		linha = append(linha, formataDecimal(aluno.Frequencia, 1), media, descreveSituacao(aluno.Situacao))
+		if err := escritor.Write(linha); err != nil {
+			return err
+		}
+	}
+
+	escritor.Flush()
+	return escritor.Error()
+}
+
+// tabelaAta escreve a tabela de resultados da ata, repetindo o cabeçalho da tabela a cada página
+//
+// As colunas das avaliações dividem a largura restante da página, limitadas a larguraMaximaNotaAta
+func tabelaAta(pdf *fpdf.Fpdf, tr func(string) string, ata *models.AtaFinal) {
+	fixas := larguraNumeroAta + larguraAlunoAta + larguraFrequenciaAta + larguraMediaAta + larguraSituacaoAta
+	larguraNota := larguraMaximaNotaAta
+	if len(ata.Avaliacoes) > 0 {
+		larguraNota = min(larguraMaximaNotaAta, (larguraUtil(pdf)-fixas)/float64(len(ata.Avaliacoes)))
+	}
+
+	cabecalho := func() {
+		pdf.SetFillColor(225, 230, 240)
+		pdf.SetFont("Helvetica", "B", 7)
+		pdf.CellFormat(larguraNumeroAta, alturaLinha, tr("Nº"), "1", 0, "C", true, 0, "")
+		pdf.CellFormat(larguraAlunoAta, alturaLinha, tr("Aluno"), "1", 0, "L", true, 0, "")
+		for _, avaliacao := range ata.Avaliacoes {
+			pdf.CellFormat(larguraNota, alturaLinha, tr(ajustaTexto(pdf, tr, avaliacao.Nome, larguraNota-1)), "1", 0, "C", true, 0, "")
+		}
+		pdf.CellFormat(larguraFrequenciaAta, alturaLinha, tr("Frequência"), "1", 0, "C", true, 0, "")
+		pdf.CellFormat(larguraMediaAta, alturaLinha, tr("Média"), "1", 0, "C", true, 0, "")
+		pdf.CellFormat(larguraSituacaoAta, alturaLinha, tr("Situação"), "1", 1, "C", true, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+	}
+	cabecalho()
+
+	if len(ata.Alunos) == 0 {
+		pdf.CellFormat(0, alturaLinha, tr("Nenhum aluno matriculado"), "1", 1, "C", false, 0, "")
+		return
+	}
+
+	for i := range ata.Alunos {
+		aluno := &ata.Alunos[i]
+		if !cabeDocumento(pdf, alturaLinha) {
+			pdf.AddPage()
+			cabecalho()
+		}
+
+		frequencia := formataDecimal(aluno.Frequencia, 1) + "%"
+		if aluno.Situacao == "" {
+			frequencia += "*"
+		}
+
+		pdf.CellFormat(larguraNumeroAta, alturaLinha, strconv.Itoa(i+1), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(larguraAlunoAta, alturaLinha, tr(ajustaTexto(pdf, tr, aluno.Nome, larguraAlunoAta-2)), "1", 0, "L", false, 0, "")
+		for _, nota := range aluno.Notas {
+			if nota == "" {
+				nota = "-"
+			}
+			pdf.CellFormat(larguraNota, alturaLinha, tr(nota), "1", 0, "C", false, 0, "")
+		}
+		pdf.CellFormat(larguraFrequenciaAta, alturaLinha, frequencia, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(larguraMediaAta, alturaLinha, tr(mediaAta(aluno)), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(larguraSituacaoAta, alturaLinha, tr(descreveSituacao(aluno.Situacao)), "1", 1, "L", false, 0, "")
+	}
+}
+
+// mediaAta retorna a média final do aluno na escala da disciplina, ou "-" se ela ainda não foi calculada
+func mediaAta(aluno *models.AtaAluno) string {
+	if aluno.MediaFinal == nil {
+		return "-"
+	}
+	return aluno.MediaConvertida
+}
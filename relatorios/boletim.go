@@ -0,0 +1,209 @@
+// Package relatorios gera os documentos emitidos pela API: o boletim escolar em PDF e o diário de classe e a ata
+// final das disciplinas em PDF e CSV
+//
+// Os documentos são gerados em Go puro, sem depender de programas externos, e o resultado depende apenas dos dados
+// recebidos, inclusive a data de emissão, para que o layout possa ser verificado por testes
+package relatorios
+
+import (
+	"github.com/go-pdf/fpdf"
+	"io"
+	"sistema-alunos-go/models"
+	"strconv"
+	"strings"
+)
+
+// Dimensões, em milímetros, das linhas do boletim
+const (
+	alturaTitulo      = 8.0
+	alturaLinha       = 6.0
+	margemInferior    = 15.0
+	espacoDisciplinas = 5.0
+)
+
+// colunasAvaliacao são os títulos e larguras, em milímetros, das colunas da tabela de avaliações
+var colunasAvaliacao = []struct {
+	titulo  string
+	largura float64
+	alinha  string
+}{
+	{"Avaliação", 88, "L"},
+	{"Tipo", 30, "C"},
+	{"Data", 26, "C"},
+	{"Peso", 20, "C"},
+	{"Nota", 26, "C"},
+}
+
+// tiposAvaliacao traduz os tipos de avaliação para o texto exibido no boletim
+var tiposAvaliacao = map[string]string{
+	models.AvaliacaoProva:       "Prova",
+	models.AvaliacaoTrabalho:    "Trabalho",
+	models.AvaliacaoRecuperacao: "Recuperação",
+}
+
+// situacoes traduz as situações finais dos alunos para o texto exibido no boletim
+var situacoes = map[string]string{
+	models.SituacaoAprovado:            "Aprovado",
+	models.SituacaoAprovadoRecuperacao: "Aprovado em recuperação",
+	models.SituacaoEmRecuperacao:       "Em recuperação",
+	models.SituacaoReprovadoNota:       "Reprovado por nota",
+	models.SituacaoReprovadoFalta:      "Reprovado por falta",
+	models.SituacaoReprovadoNotaFalta:  "Reprovado por nota e falta",
+	models.SituacaoTrancado:            "Trancado",
+}
+
+// GeraBoletim gera o boletim do aluno em PDF e o grava em `destino`
+//
+// Cada disciplina é apresentada em um bloco com suas avaliações, notas, frequência, média final e situação. Um bloco
+// só é dividido entre páginas quando não cabe sozinho em uma página inteira
+func GeraBoletim(boletim *models.Boletim, destino io.Writer) error {
+	pdf, tr := novoDocumento("P", "Boletim escolar - "+boletim.Periodo, boletim.EmitidoEm)
+
+	pdf.AddPage()
+	cabecalhoBoletim(pdf, tr, boletim)
+
+	if len(boletim.Disciplinas) == 0 {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, alturaLinha, tr("Nenhuma disciplina cursada no período."), "", 1, "L", false, 0, "")
+	}
+
+	for i := range boletim.Disciplinas {
+		blocoDisciplina(pdf, tr, &boletim.Disciplinas[i])
+	}
+
+	return pdf.Output(destino)
+}
+
+// cabecalhoBoletim escreve o título do boletim e a identificação do aluno e do período
+func cabecalhoBoletim(pdf *fpdf.Fpdf, tr func(string) string, boletim *models.Boletim) {
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, 10, tr("Boletim Escolar"), "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+
+	pdf.SetFont("Helvetica", "", 10)
+	campos := [][2]string{
+		{"Aluno", boletim.AlunoNome},
+		{"E-mail", boletim.AlunoEmail},
+		{"Matrícula", boletim.AlunoId},
+		{"Período", boletim.Periodo},
+	}
+	for _, campo := range campos {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(25, alturaLinha, tr(campo[0]+":"), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, alturaLinha, tr(campo[1]), "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(2)
+	x, y := pdf.GetXY()
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.Line(x, y, x+190, y)
+	pdf.Ln(espacoDisciplinas)
+}
+
+// blocoDisciplina escreve o bloco de uma disciplina, iniciando uma nova página se ele não couber na atual
+func blocoDisciplina(pdf *fpdf.Fpdf, tr func(string) string, disciplina *models.BoletimDisciplina) {
+	linhas := len(disciplina.Avaliacoes)
+	if linhas == 0 {
+		linhas = 1
+	}
+	altura := alturaTitulo + alturaLinha*float64(linhas+1) + alturaTitulo
+	_, alturaPagina := pdf.GetPageSize()
+	if pdf.GetY()+altura > alturaPagina-margemInferior && pdf.GetY() > 40 {
+		pdf.AddPage()
+	}
+
+	pdf.SetFillColor(40, 70, 120)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(120, alturaTitulo, tr(ajustaTexto(pdf, tr, disciplina.Nome, 118)), "", 0, "L", true, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.CellFormat(70, alturaTitulo, tr(ajustaTexto(pdf, tr, "Prof. "+disciplina.Professor, 68)), "", 1, "R", true, 0, "")
+
+	pdf.SetFillColor(225, 230, 240)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", 9)
+	for _, coluna := range colunasAvaliacao {
+		pdf.CellFormat(coluna.largura, alturaLinha, tr(coluna.titulo), "1", 0, coluna.alinha, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 9)
+	if len(disciplina.Avaliacoes) == 0 {
+		pdf.CellFormat(190, alturaLinha, tr("Nenhuma avaliação cadastrada"), "1", 1, "C", false, 0, "")
+	}
+	for _, avaliacao := range disciplina.Avaliacoes {
+		nota := avaliacao.Nota
+		if nota == "" {
+			nota = "-"
+		}
+		valores := []string{
+			ajustaTexto(pdf, tr, avaliacao.Nome, colunasAvaliacao[0].largura-2),
+			tiposAvaliacao[avaliacao.Tipo],
+			formataData(avaliacao.Data),
+			formataDecimal(avaliacao.Peso, 2),
+			nota,
+		}
+		for i, coluna := range colunasAvaliacao {
+			pdf.CellFormat(coluna.largura, alturaLinha, tr(valores[i]), "1", 0, coluna.alinha, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	media := "-"
+	if disciplina.MediaFinal != nil {
+		media = disciplina.MediaConvertida
+	}
+	frequencia := formataDecimal(disciplina.Frequencia, 1) + "%"
+	situacao := "Em andamento"
+	if disciplina.Situacao != "" {
+		situacao = descreveSituacao(disciplina.Situacao)
+	} else {
+		frequencia += " (parcial)"
+	}
+
+	pdf.SetFillColor(245, 245, 245)
+	resumo := [][2]string{
+		{"Média final: ", media},
+		{"Frequência: ", frequencia},
+		{"Situação: ", situacao},
+	}
+	larguras := []float64{50, 60, 80}
+	for i, item := range resumo {
+		pdf.SetFont("Helvetica", "B", 9)
+		largura := pdf.GetStringWidth(tr(item[0])) + 2
+		pdf.CellFormat(largura, alturaTitulo, tr(item[0]), "LTB", 0, "L", true, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(larguras[i]-largura, alturaTitulo, tr(item[1]), "RTB", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.Ln(espacoDisciplinas)
+}
+
+// ajustaTexto encurta um texto com reticências para que ele caiba na largura informada, na fonte atual
+func ajustaTexto(pdf *fpdf.Fpdf, tr func(string) string, texto string, largura float64) string {
+	if pdf.GetStringWidth(tr(texto)) <= largura {
+		return texto
+	}
+
+	runas := []rune(texto)
+	for len(runas) > 0 && pdf.GetStringWidth(tr(string(runas)+"...")) > largura {
+		runas = runas[:len(runas)-1]
+	}
+	return strings.TrimSpace(string(runas)) + "..."
+}
+
+// formataDecimal formata um número com a quantidade de casas informada e vírgula como separador decimal
+func formataDecimal(valor float64, casas int) string {
+	return strings.Replace(strconv.FormatFloat(valor, 'f', casas, 64), ".", ",", 1)
+}
+
+// formataData converte uma data no formato yyyy-MM-dd para dd/MM/yyyy, mantendo outros formatos como estão
+func formataData(data string) string {
+	partes := strings.Split(data, "-")
+	if len(partes) != 3 {
+		return data
+	}
+	return partes[2] + "/" + partes[1] + "/" + partes[0]
+}
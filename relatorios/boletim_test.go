@@ -0,0 +1,152 @@
+package relatorios
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sistema-alunos-go/models"
+	"testing"
+	"time"
+)
+
+// atualizar regrava os arquivos de referência com a saída atual: go test ./relatorios -atualizar
+var atualizar = flag.Bool("atualizar", false, "regrava os arquivos de referência dos testes de relatórios")
+
+// boletimTeste monta um boletim com uma disciplina aprovada em recuperação, uma reprovada por falta, uma em
+// andamento e uma sem avaliações
+func boletimTeste() *models.Boletim {
+	media := 6.0
+	reprovado := 3.25
+
+	return &models.Boletim{
+		AlunoId:    "0f8fad5b-d9cb-469f-a165-70867728950e",
+		AlunoNome:  "João da Conceição Araújo",
+		AlunoEmail: "joao.araujo@example.com",
+		Periodo:    "2025-01",
+		EmitidoEm:  time.Date(2025, time.July, 10, 14, 30, 0, 0, time.UTC),
+		Disciplinas: []models.BoletimDisciplina{
+			{
+				Nome:             "Cálculo Diferencial e Integral I",
+				Professor:        "Maria Inês Gonçalves",
+				NotaMinima:       6,
+				FrequenciaMinima: 75,
+				Avaliacoes: []models.BoletimAvaliacao{
+					{Nome: "P1", Tipo: models.AvaliacaoProva, Data: "2025-03-20", Peso: 0.4, Nota: "4,5"},
+					{Nome: "P2", Tipo: models.AvaliacaoProva, Data: "2025-05-15", Peso: 0.4, Nota: "5"},
+					{Nome: "Lista de exercícios sobre limites, derivadas e aplicações em otimização", Tipo: models.AvaliacaoTrabalho, Data: "2025-04-10", Peso: 0.2, Nota: "8,75"},
+					{Nome: "Recuperação", Tipo: models.AvaliacaoRecuperacao, Data: "2025-06-30", Peso: 0, Nota: "7"},
+				},
+				MediaFinal:      &media,
+				MediaConvertida: "6",
+				Frequencia:      87.5,
+				Situacao:        models.SituacaoAprovadoRecuperacao,
+			},
+			{
+				Nome:             "Programação Orientada a Objetos",
+				Professor:        "Carlos Eduardo Lima",
+				NotaMinima:       7,
+				FrequenciaMinima: 75,
+				Avaliacoes: []models.BoletimAvaliacao{
+					{Nome: "Projeto final", Tipo: models.AvaliacaoTrabalho, Data: "2025-06-12", Peso: 1, Nota: "D"},
+				},
+				MediaFinal:      &reprovado,
+				MediaConvertida: "D",
+				Frequencia:      62.5,
+				Situacao:        models.SituacaoReprovadoFalta,
+			},
+			{
+				Nome:             "Estruturas de Dados",
+				Professor:        "Ana Paula Souza",
+				NotaMinima:       6,
+				FrequenciaMinima: 75,
+				Avaliacoes: []models.BoletimAvaliacao{
+					{Nome: "P1", Tipo: models.AvaliacaoProva, Data: "2025-04-02", Peso: 0.5, Nota: "9,25"},
+					{Nome: "P2", Tipo: models.AvaliacaoProva, Data: "2025-06-25", Peso: 0.5},
+				},
+				Frequencia: 91.66666,
+			},
+			{
+				Nome:             "Seminários",
+				Professor:        "Ana Paula Souza",
+				NotaMinima:       5,
+				FrequenciaMinima: 70,
+				Avaliacoes:       []models.BoletimAvaliacao{},
+				Frequencia:       100,
+			},
+		},
+	}
+}
+
+func TestGeraBoletim(t *testing.T) {
+	casos := []struct {
+		nome    string
+		boletim func() *models.Boletim
+	}{
+		{"boletim", boletimTeste},
+		{"boletim_varias_paginas", func() *models.Boletim {
+			boletim := boletimTeste()
+			base := boletim.Disciplinas
+			for i := 0; i < 5; i++ {
+				for _, d := range base {
+					d.Nome = fmt.Sprintf("%s %d", d.Nome, i+2)
+					boletim.Disciplinas = append(boletim.Disciplinas, d)
+				}
+			}
+			return boletim
+		}},
+		{"boletim_vazio", func() *models.Boletim {
+			boletim := boletimTeste()
+			boletim.Disciplinas = nil
+			return boletim
+		}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			var saida bytes.Buffer
+			if err := GeraBoletim(c.boletim(), &saida); err != nil {
+				t.Fatalf("GeraBoletim retornou erro inesperado: %v", err)
+			}
+
+			comparaReferencia(t, c.nome+".golden.pdf", saida.Bytes())
+		})
+	}
+}
+
+func TestGeraBoletimDeterministico(t *testing.T) {
+	var primeira, segunda bytes.Buffer
+	if err := GeraBoletim(boletimTeste(), &primeira); err != nil {
+		t.Fatalf("GeraBoletim retornou erro inesperado: %v", err)
+	}
+	if err := GeraBoletim(boletimTeste(), &segunda); err != nil {
+		t.Fatalf("GeraBoletim retornou erro inesperado: %v", err)
+	}
+
+	if !bytes.Equal(primeira.Bytes(), segunda.Bytes()) {
+		t.Error("duas gerações do mesmo boletim produziram PDFs diferentes")
+	}
+}
+
+// comparaReferencia compara a saída de um relatório com o arquivo de referência em testdata, regravando-o antes se o
+// teste for executado com -atualizar
+func comparaReferencia(t *testing.T, arquivo string, saida []byte) {
+	t.Helper()
+
+	referencia := filepath.Join("testdata", arquivo)
+	if *atualizar {
+		if err := os.WriteFile(referencia, saida, 0o644); err != nil {
+			t.Fatalf("erro ao gravar %s: %v", referencia, err)
+		}
+	}
+
+	esperado, err := os.ReadFile(referencia)
+	if err != nil {
+		t.Fatalf("erro ao ler %s: %v (rode com -atualizar para gerá-lo)", referencia, err)
+	}
+
+	if !bytes.Equal(saida, esperado) {
+		t.Errorf("a saída gerada difere de %s; confira o layout e rode com -atualizar se a mudança for intencional", referencia)
+	}
+}
@@ -0,0 +1,214 @@
+package relatorios
+
+import (
+	"github.com/go-pdf/fpdf"
+	"io"
+	"sistema-alunos-go/models"
+	"strconv"
+)
+
+// Dimensões, em milímetros, da grade de frequência do diário de classe
+const (
+	larguraAlunoGrade  = 62.0
+	larguraAulaGrade   = 8.0
+	larguraFaltasGrade = 14.0
+	alturaLinhaGrade   = 5.0
+)
+
+// colunasAulas são os títulos, larguras, em milímetros, e alinhamentos das colunas do registro de aulas
+var colunasAulas = []struct {
+	titulo  string
+	largura float64
+	alinha  string
+}{
+	{"Aula", 14, "C"},
+	{"Data", 24, "C"},
+	{"Horas", 16, "C"},
+	{"Conteúdo", 223, "L"},
+}
+
+// legendaPresencas descreve as marcações usadas na grade de frequência
+const legendaPresencas = "P: presente   A: presente com atraso   F: falta   J: falta abonada   " +
+	"h/t: presença parcial (horas assistidas/duração da aula)   -: sem registro"
+
+// GeraDiarioClasse gera o diário de classe da disciplina em PDF e o grava em `destino`
+//
+// O documento, em paisagem, traz o registro das aulas com data, duração e conteúdo, a grade de frequência dos alunos,
+// dividida em blocos de aulas quando não cabe na largura da página, e as linhas de assinatura
+func GeraDiarioClasse(diario *models.DiarioClasse, destino io.Writer) error {
+	pdf, tr := novoDocumento("L", "Diário de classe - "+diario.Disciplina, diario.EmitidoEm)
+
+	var horas int
+	for _, aula := range diario.Aulas {
+		horas += aula.QuantidadeHoras
+	}
+
+	pdf.AddPage()
+	cabecalhoDocumento(pdf, tr, "Diário de Classe", &diario.CabecalhoDisciplina,
+		[2]string{"Carga horária", strconv.Itoa(horas) + "h realizadas de " + strconv.Itoa(diario.CargaHorariaPrevista) + "h previstas"},
+		[2]string{"Aulas registradas", strconv.Itoa(len(diario.Aulas))},
+		[2]string{"Alunos matriculados", strconv.Itoa(len(diario.Alunos))},
+	)
+
+	registroAulas(pdf, tr, diario.Aulas)
+	pdf.AddPage()
+	gradeFrequencia(pdf, tr, diario)
+	assinaturas(pdf, tr, diario.Professor)
+
+	return pdf.Output(destino)
+}
+
+// GeraDiarioClasseCsv gera o diário de classe da disciplina em CSV e o grava em `destino`
+//
+// Cada linha corresponde a uma aula, com número, data, duração e conteúdo, seguidos de uma coluna por aluno com a
+// marcação de presença, na mesma ordem da grade de frequência do PDF
+func GeraDiarioClasseCsv(diario *models.DiarioClasse, destino io.Writer) error {
+	escritor, err := novoCsv(destino)
+	if err != nil {
+		return err
+	}
+
+	cabecalho := []string{"Aula", "Data", "Horas", "Conteúdo"}
+	for _, aluno := range diario.Alunos {
+		cabecalho = append(cabecalho, aluno.Nome)
+	}
+	if err := escritor.Write(cabecalho); err != nil {
+		return err
+	}
+
+	for _, aula := range diario.Aulas {
+		linha := []string{strconv.Itoa(aula.Numero), formataData(aula.Data), strconv.Itoa(aula.QuantidadeHoras), aula.Conteudo}
+		linha = append(linha, aula.Presencas...)
+		if err := escritor.Write(linha); err != nil {
+			return err
+		}
+	}
+
+	escritor.Flush()
+	return escritor.Error()
+}
+
+// registroAulas escreve a tabela com as aulas registradas, repetindo o cabeçalho da tabela a cada página
+func registroAulas(pdf *fpdf.Fpdf, tr func(string) string, aulas []models.DiarioAula) {
+	tituloSecao(pdf, tr, "Registro de aulas")
+
+	larguras := make([]float64, len(colunasAulas))
+	alinhamentos := make([]string, len(colunasAulas))
+	titulos := make([]string, len(colunasAulas))
+	for i, coluna := range colunasAulas {
+		larguras[i] = coluna.largura
+		alinhamentos[i] = coluna.alinha
+		titulos[i] = tr(coluna.titulo)
+	}
+
+	cabecalho := func() {
+		pdf.SetFillColor(225, 230, 240)
+		pdf.SetFont("Helvetica", "B", 9)
+		linhaTabela(pdf, larguras, alinhamentos, titulos, true)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	cabecalho()
+
+	if len(aulas) == 0 {
+		pdf.CellFormat(0, alturaLinha, tr("Nenhuma aula registrada"), "1", 1, "C", false, 0, "")
+		return
+	}
+
+	for _, aula := range aulas {
+		textos := []string{
+			strconv.Itoa(aula.Numero),
+			formataData(aula.Data),
+			strconv.Itoa(aula.QuantidadeHoras) + "h",
+			tr(aula.Conteudo),
+		}
+		if !cabeDocumento(pdf, alturaLinhaTabela(pdf, larguras, textos)) {
+			pdf.AddPage()
+			cabecalho()
+		}
+		linhaTabela(pdf, larguras, alinhamentos, textos, false)
+	}
+}
+
+// gradeFrequencia escreve a grade com a marcação de presença de cada aluno em cada aula
+//
+// As aulas são divididas em blocos que cabem na largura da página; o último bloco traz também o total de faltas de
+// cada aluno. O cabeçalho do bloco é repetido quando os alunos ocupam mais de uma página
+func gradeFrequencia(pdf *fpdf.Fpdf, tr func(string) string, diario *models.DiarioClasse) {
+	tituloSecao(pdf, tr, "Frequência")
+
+	if len(diario.Alunos) == 0 || len(diario.Aulas) == 0 {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(0, alturaLinha, tr("Nenhuma presença registrada"), "1", 1, "C", false, 0, "")
+		return
+	}
+
+	faltas := make([]int, len(diario.Alunos))
+	for _, aula := range diario.Aulas {
+		for i, marca := range aula.Presencas {
+			if marca == models.MarcaFalta {
+				faltas[i]++
+			}
+		}
+	}
+
+	porBloco := int((larguraUtil(pdf) - larguraAlunoGrade - larguraFaltasGrade) / larguraAulaGrade)
+	for inicio := 0; inicio < len(diario.Aulas); inicio += porBloco {
+		fim := min(inicio+porBloco, len(diario.Aulas))
+		ultimo := fim == len(diario.Aulas)
+		bloco := diario.Aulas[inicio:fim]
+
+		cabecalho := func() {
+			pdf.SetFillColor(225, 230, 240)
+			pdf.SetFont("Helvetica", "B", 7)
+			pdf.CellFormat(larguraAlunoGrade, alturaLinhaGrade*2, tr("Aluno"), "1", 0, "L", true, 0, "")
+			x, y := pdf.GetXY()
+			for i, aula := range bloco {
+				pdf.SetXY(x+float64(i)*larguraAulaGrade, y)
+				pdf.CellFormat(larguraAulaGrade, alturaLinhaGrade, strconv.Itoa(aula.Numero), "1", 0, "C", true, 0, "")
+				pdf.SetXY(x+float64(i)*larguraAulaGrade, y+alturaLinhaGrade)
+				pdf.SetFont("Helvetica", "", 6)
+				pdf.CellFormat(larguraAulaGrade, alturaLinhaGrade, diaMes(aula.Data), "1", 0, "C", true, 0, "")
+				pdf.SetFont("Helvetica", "B", 7)
+			}
+			if ultimo {
+				pdf.SetXY(x+float64(len(bloco))*larguraAulaGrade, y)
+				pdf.CellFormat(larguraFaltasGrade, alturaLinhaGrade*2, tr("Faltas"), "1", 0, "C", true, 0, "")
+			}
+			pdf.SetXY(x-larguraAlunoGrade, y+alturaLinhaGrade*2)
+			pdf.SetFont("Helvetica", "", 7)
+		}
+
+		if !cabeDocumento(pdf, alturaLinhaGrade*3) {
+			pdf.AddPage()
+		}
+		cabecalho()
+
+		for i, aluno := range diario.Alunos {
+			if !cabeDocumento(pdf, alturaLinhaGrade) {
+				pdf.AddPage()
+				cabecalho()
+			}
+			pdf.CellFormat(larguraAlunoGrade, alturaLinhaGrade, tr(ajustaTexto(pdf, tr, aluno.Nome, larguraAlunoGrade-2)), "1", 0, "L", false, 0, "")
+			for _, aula := range bloco {
+				pdf.CellFormat(larguraAulaGrade, alturaLinhaGrade, aula.Presencas[i], "1", 0, "C", false, 0, "")
+			}
+			if ultimo {
+				pdf.CellFormat(larguraFaltasGrade, alturaLinhaGrade, strconv.Itoa(faltas[i]), "1", 0, "C", false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+		pdf.Ln(espacoDisciplinas)
+	}
+
+	pdf.SetFont("Helvetica", "I", 7)
+	pdf.MultiCell(0, 4, tr(legendaPresencas), "", "L", false)
+}
+
+// diaMes converte uma data no formato yyyy-MM-dd para dd/MM, mantendo outros formatos como estão
+func diaMes(data string) string {
+	completa := formataData(data)
+	if len(completa) != len("02/01/2006") {
+		return completa
+	}
+	return completa[:5]
+}
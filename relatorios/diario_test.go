@@ -0,0 +1,162 @@
+package relatorios
+
+import (
+	"bytes"
+	"fmt"
+	"sistema-alunos-go/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cabecalhoTeste identifica a disciplina usada nos testes do diário de classe e da ata final
+func cabecalhoTeste() models.CabecalhoDisciplina {
+	return models.CabecalhoDisciplina{
+		DisciplinaId:         "6f1c3a52-8a1e-4c8e-9a57-1b2f7b0d4e21",
+		Disciplina:           "Cálculo Diferencial e Integral I",
+		Professor:            "Maria Inês Gonçalves",
+		AnoSemestre:          "2025-01",
+		CargaHorariaPrevista: 60,
+		EmitidoEm:            time.Date(2025, time.July, 10, 14, 30, 0, 0, time.UTC),
+	}
+}
+
+// alunosTeste são os alunos matriculados na disciplina dos testes
+var alunosTeste = []models.AlunoDocumento{
+	{AlunoId: "0f8fad5b-d9cb-469f-a165-70867728950e", Nome: "Ana Beatriz Conceição"},
+	{AlunoId: "7c9e6679-7425-40de-944b-e07fc1f90ae7", Nome: "João da Conceição Araújo"},
+	{AlunoId: "16fd2706-8baf-433b-82eb-8c7fada847da", Nome: "Pedro Henrique de Albuquerque Cavalcanti Figueiredo"},
+}
+
+// diarioTeste monta um diário de classe com aulas de conteúdo curto e longo e todas as marcações de presença
+func diarioTeste() *models.DiarioClasse {
+	return &models.DiarioClasse{
+		CabecalhoDisciplina: cabecalhoTeste(),
+		Alunos:              alunosTeste,
+		Aulas: []models.DiarioAula{
+			{Numero: 1, Data: "2025-03-03", QuantidadeHoras: 4, Conteudo: "Apresentação da disciplina e revisão de funções",
+				Presencas: []string{models.MarcaPresente, models.MarcaPresente, models.MarcaAtraso}},
+			{Numero: 2, Data: "2025-03-10", QuantidadeHoras: 4, Conteudo: strings.Repeat("Limites laterais, limites no infinito e continuidade; ", 6),
+				Presencas: []string{models.MarcaPresente, models.MarcaFalta, "2/4"}},
+			{Numero: 3, Data: "2025-03-17", QuantidadeHoras: 2, Conteudo: "Derivadas: definição e regras de derivação",
+				Presencas: []string{models.MarcaAbonada, models.MarcaFalta, models.MarcaSemRegistro}},
+		},
+	}
+}
+
+// ataTeste monta uma ata final com alunos aprovados, reprovados e com o semestre em andamento
+func ataTeste() *models.AtaFinal {
+	aprovado := 7.5
+	reprovado := 4.25
+
+	return &models.AtaFinal{
+		CabecalhoDisciplina: cabecalhoTeste(),
+		Avaliacoes: []models.AvaliacaoDocumento{
+			{Nome: "P1", Tipo: models.AvaliacaoProva, Data: "2025-04-02", Peso: 0.4},
+			{Nome: "P2", Tipo: models.AvaliacaoProva, Data: "2025-06-25", Peso: 0.4},
+			{Nome: "Lista de exercícios", Tipo: models.AvaliacaoTrabalho, Data: "2025-05-10", Peso: 0.2},
+		},
+		Alunos: []models.AtaAluno{
+			{AlunoDocumento: alunosTeste[0], Notas: []string{"7", "8", "7,5"}, Frequencia: 100,
+				MediaFinal: &aprovado, MediaConvertida: "7,5", Situacao: models.SituacaoAprovado},
+			{AlunoDocumento: alunosTeste[1], Notas: []string{"3", "5", ""}, Frequencia: 62.5,
+				MediaFinal: &reprovado, MediaConvertida: "4,25", Situacao: models.SituacaoReprovadoNotaFalta},
+			{AlunoDocumento: alunosTeste[2], Notas: []string{"9,25", "", ""}, Frequencia: 83.3333},
+		},
+	}
+}
+
+func TestGeraDiarioClasse(t *testing.T) {
+	casos := []struct {
+		nome   string
+		diario func() *models.DiarioClasse
+	}{
+		{"diario", diarioTeste},
+		{"diario_varias_paginas", func() *models.DiarioClasse {
+			diario := diarioTeste()
+			for i := 0; i < 30; i++ {
+				diario.Alunos = append(diario.Alunos, models.AlunoDocumento{
+					AlunoId: fmt.Sprintf("aluno-%02d", i),
+					Nome:    fmt.Sprintf("Aluno de teste %02d", i),
+				})
+			}
+			base := diario.Aulas
+			diario.Aulas = nil
+			for i := 0; i < 40; i++ {
+				aula := base[i%len(base)]
+				aula.Numero = i + 1
+				aula.Presencas = make([]string, len(diario.Alunos))
+				for j := range aula.Presencas {
+					aula.Presencas[j] = []string{models.MarcaPresente, models.MarcaFalta}[(i+j)%2]
+				}
+				diario.Aulas = append(diario.Aulas, aula)
+			}
+			return diario
+		}},
+		{"diario_vazio", func() *models.DiarioClasse {
+			return &models.DiarioClasse{CabecalhoDisciplina: cabecalhoTeste()}
+		}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			var pdf, csv bytes.Buffer
+			if err := GeraDiarioClasse(c.diario(), &pdf); err != nil {
+				t.Fatalf("GeraDiarioClasse retornou erro inesperado: %v", err)
+			}
+			if err := GeraDiarioClasseCsv(c.diario(), &csv); err != nil {
+				t.Fatalf("GeraDiarioClasseCsv retornou erro inesperado: %v", err)
+			}
+
+			comparaReferencia(t, c.nome+".golden.pdf", pdf.Bytes())
+			comparaReferencia(t, c.nome+".golden.csv", csv.Bytes())
+		})
+	}
+}
+
+func TestGeraAtaFinal(t *testing.T) {
+	casos := []struct {
+		nome string
+		ata  func() *models.AtaFinal
+	}{
+		{"ata", ataTeste},
+		{"ata_vazia", func() *models.AtaFinal {
+			return &models.AtaFinal{CabecalhoDisciplina: cabecalhoTeste()}
+		}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			var pdf, csv bytes.Buffer
+			if err := GeraAtaFinal(c.ata(), &pdf); err != nil {
+				t.Fatalf("GeraAtaFinal retornou erro inesperado: %v", err)
+			}
+			if err := GeraAtaFinalCsv(c.ata(), &csv); err != nil {
+				t.Fatalf("GeraAtaFinalCsv retornou erro inesperado: %v", err)
+			}
+
+			comparaReferencia(t, c.nome+".golden.pdf", pdf.Bytes())
+			comparaReferencia(t, c.nome+".golden.csv", csv.Bytes())
+		})
+	}
+}
+
+func TestGeraCsvProtegeFormulas(t *testing.T) {
+	diario := diarioTeste()
+	diario.Alunos = []models.AlunoDocumento{{AlunoId: alunosTeste[0].AlunoId, Nome: "@Ana"}}
+	diario.Aulas = []models.DiarioAula{
+		{Numero: 1, Data: "2025-03-03", QuantidadeHoras: 2, Conteudo: "=HYPERLINK(\"http://exemplo.com\")",
+			Presencas: []string{models.MarcaSemRegistro}},
+	}
+
+	var csv bytes.Buffer
+	if err := GeraDiarioClasseCsv(diario, &csv); err != nil {
+		t.Fatalf("GeraDiarioClasseCsv retornou erro inesperado: %v", err)
+	}
+
+	esperado := "\ufeffAula;Data;Horas;Conteúdo;'@Ana\n1;03/03/2025;2;\"'=HYPERLINK(\"\"http://exemplo.com\"\")\";" +
+		models.MarcaSemRegistro + "\n"
+	if csv.String() != esperado {
+		t.Errorf("CSV = %q, esperado %q", csv.String(), esperado)
+	}
+}
@@ -0,0 +1,213 @@
+package relatorios
+
+import (
+	"encoding/csv"
+	"github.com/go-pdf/fpdf"
+	"io"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/planilhas"
+	"strconv"
+	"time"
+)
+
+// Dimensões, em milímetros, dos elementos comuns aos documentos
+const (
+	alturaTextoTabela = 4.5
+	alturaAssinaturas = 30.0
+)
+
+// novoDocumento cria um PDF A4 na orientação informada ("P" ou "L"), com o título e as datas de criação iguais à data
+// de emissão e um rodapé com a data de emissão e a numeração das páginas
+//
+// Retorna o PDF e a função que converte textos UTF-8 para a codificação das fontes padrão
+func novoDocumento(orientacao string, titulo string, emitidoEm time.Time) (*fpdf.Fpdf, func(string) string) {
+	pdf := fpdf.New(orientacao, "mm", "A4", "")
+	pdf.SetCreationDate(emitidoEm)
+	pdf.SetModificationDate(emitidoEm)
+	pdf.SetCatalogSort(true)
+	pdf.SetTitle(titulo, true)
+	pdf.SetAutoPageBreak(true, margemInferior)
+	pdf.AliasNbPages("")
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	emissao := emitidoEm.Format("02/01/2006 15:04")
+	metade := larguraUtil(pdf) / 2
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-margemInferior + 3)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(metade, 5, tr("Emitido em "+emissao), "", 0, "L", false, 0, "")
+		pdf.CellFormat(metade, 5, tr("Página "+strconv.Itoa(pdf.PageNo())+" de {nb}"), "", 0, "R", false, 0, "")
+	})
+
+	return pdf, tr
+}
+
+// larguraUtil retorna a largura da página descontadas as margens laterais
+func larguraUtil(pdf *fpdf.Fpdf) float64 {
+	largura, _ := pdf.GetPageSize()
+	esquerda, _, direita, _ := pdf.GetMargins()
+	return largura - esquerda - direita
+}
+
+// cabeDocumento indica se um bloco com a altura informada cabe no restante da página atual
+func cabeDocumento(pdf *fpdf.Fpdf, altura float64) bool {
+	_, alturaPagina := pdf.GetPageSize()
+	return pdf.GetY()+altura <= alturaPagina-margemInferior
+}
+
+// cabecalhoDocumento escreve o título de um documento da disciplina e a identificação da disciplina, do professor e
+// do período, seguidos dos campos adicionais informados
+func cabecalhoDocumento(pdf *fpdf.Fpdf, tr func(string) string, titulo string, cabecalho *models.CabecalhoDisciplina, extras ...[2]string) {
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, 10, tr(titulo), "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+
+	campos := append([][2]string{
+		{"Disciplina", cabecalho.Disciplina},
+		{"Professor(a)", cabecalho.Professor},
+		{"Período", cabecalho.AnoSemestre},
+	}, extras...)
+	for _, campo := range campos {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(45, alturaLinha, tr(campo[0]+":"), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, alturaLinha, tr(campo[1]), "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(2)
+	x, y := pdf.GetXY()
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.Line(x, y, x+larguraUtil(pdf), y)
+	pdf.Ln(espacoDisciplinas)
+}
+
+// tituloSecao escreve o título de uma seção do documento
+func tituloSecao(pdf *fpdf.Fpdf, tr func(string) string, titulo string) {
+	pdf.SetFillColor(40, 70, 120)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, alturaTitulo, tr(titulo), "", 1, "L", true, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// linhaTabela escreve uma linha de tabela com as larguras e alinhamentos informados, quebrando os textos que não
+// cabem em suas colunas em várias linhas
+//
+// Os textos já devem estar convertidos com a função de tradução do documento. Retorna a altura da linha escrita
+func linhaTabela(pdf *fpdf.Fpdf, larguras []float64, alinhamentos []string, textos []string, preenche bool) float64 {
+	linhas := make([][]string, len(textos))
+	quantidade := 1
+	for i, texto := range textos {
+		linhas[i] = []string{texto}
+		if pdf.GetStringWidth(texto) > larguras[i]-2 {
+			linhas[i] = pdf.SplitText(texto, larguras[i]-2)
+		}
+		quantidade = max(quantidade, len(linhas[i]))
+	}
+
+	altura := max(alturaLinha, float64(quantidade)*alturaTextoTabela+1.5)
+	inicio, y := pdf.GetXY()
+	x := inicio
+	for i := range textos {
+		estilo := "D"
+		if preenche {
+			estilo = "FD"
+		}
+		pdf.Rect(x, y, larguras[i], altura, estilo)
+
+		if len(linhas[i]) == 1 {
+			pdf.SetXY(x, y)
+			pdf.CellFormat(larguras[i], altura, linhas[i][0], "", 0, alinhamentos[i], false, 0, "")
+		} else {
+			for j, linha := range linhas[i] {
+				pdf.SetXY(x, y+0.75+float64(j)*alturaTextoTabela)
+				pdf.CellFormat(larguras[i], alturaTextoTabela, linha, "", 0, alinhamentos[i], false, 0, "")
+			}
+		}
+		x += larguras[i]
+	}
+	pdf.SetXY(inicio, y+altura)
+
+	return altura
+}
+
+// alturaLinhaTabela calcula a altura que uma linha de tabela ocupará, sem escrevê-la
+func alturaLinhaTabela(pdf *fpdf.Fpdf, larguras []float64, textos []string) float64 {
+	quantidade := 1
+	for i, texto := range textos {
+		if pdf.GetStringWidth(texto) > larguras[i]-2 {
+			quantidade = max(quantidade, len(pdf.SplitText(texto, larguras[i]-2)))
+		}
+	}
+	return max(alturaLinha, float64(quantidade)*alturaTextoTabela+1.5)
+}
+
+// assinaturas escreve as linhas de assinatura do professor responsável e da coordenação ao final do documento,
+// iniciando uma nova página se elas não couberem na atual
+func assinaturas(pdf *fpdf.Fpdf, tr func(string) string, professor string) {
+	if !cabeDocumento(pdf, alturaAssinaturas) {
+		pdf.AddPage()
+	}
+
+	pdf.Ln(alturaAssinaturas - 12)
+	largura := larguraUtil(pdf)
+	esquerda, _, _, _ := pdf.GetMargins()
+	_, y := pdf.GetXY()
+	linha := largura / 3
+
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.Line(esquerda+largura/12, y, esquerda+largura/12+linha, y)
+	pdf.Line(esquerda+largura*7/12, y, esquerda+largura*7/12+linha, y)
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetXY(esquerda+largura/12, y+1)
+	pdf.CellFormat(linha, 5, tr(professor), "", 0, "C", false, 0, "")
+	pdf.SetXY(esquerda+largura*7/12, y+1)
+	pdf.CellFormat(linha, 5, tr("Coordenação"), "", 1, "C", false, 0, "")
+
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.SetX(esquerda + largura/12)
+	pdf.CellFormat(linha, 4, tr("Professor(a) responsável"), "", 0, "C", false, 0, "")
+}
+
+// escritorCsv grava as linhas dos documentos em CSV, protegendo com planilhas.ProtegeCelula os valores que seriam
+// interpretados como fórmula, como nomes e conteúdos de aula digitados pelos usuários
+type escritorCsv struct {
+	*csv.Writer
+}
+
+// Write grava uma linha do CSV com os valores protegidos
+func (e escritorCsv) Write(linha []string) error {
+	protegida := make([]string, len(linha))
+	for i, valor := range linha {
+		protegida[i] = planilhas.ProtegeCelula(valor)
+	}
+	return e.Writer.Write(protegida)
+}
+
+// novoCsv cria um escritor de CSV no formato esperado pelas planilhas em português: campos separados por ponto e
+// vírgula e o BOM do UTF-8 no início, para que os acentos sejam reconhecidos
+func novoCsv(destino io.Writer) (escritorCsv, error) {
+	if _, err := io.WriteString(destino, "\ufeff"); err != nil {
+		return escritorCsv{}, err
+	}
+
+	escritor := csv.NewWriter(destino)
+	escritor.Comma = ';'
+	return escritorCsv{Writer: escritor}, nil
+}
+
+// descreveSituacao retorna o texto exibido para a situação final de um aluno, ou "Em andamento" se o semestre ainda
+// não foi fechado
+func descreveSituacao(situacao string) string {
+	if situacao == "" {
+		return "Em andamento"
+	}
+	if descricao, ok := situacoes[situacao]; ok {
+		return descricao
+	}
+	return situacao
+}
@@ -10,12 +10,40 @@ import (
 func RegistraRotas(router *gin.Engine) {
 	api := router.Group("")
 
+	{
+		admin := api.Group("/admin")
+		admin.GET("/backup", middleware.Autenticado, controllers.ExportarBackup)
+		admin.POST("/backup", middleware.Autenticado, controllers.ImportarBackup)
+		admin.POST("/webhook", middleware.Autenticado, controllers.CadastrarWebhook)
+		admin.GET("/webhook", middleware.Autenticado, controllers.ListarWebhooks)
+		admin.PATCH("/webhook/:id", middleware.Autenticado, controllers.AtualizarWebhook)
+		admin.DELETE("/webhook/:id", middleware.Autenticado, controllers.RemoverWebhook)
+		admin.GET("/webhook/:id/envios", middleware.Autenticado, controllers.ListarEnviosWebhook)
+		admin.POST("/webhook/:id/envios/:envioId/reenviar", middleware.Autenticado, controllers.ReenviarWebhook)
+	}
+
 	{
 		aluno := api.Group("/aluno")
 		aluno.POST("/", middleware.Autenticado, controllers.CadastrarAluno)
+		aluno.POST("/importar", middleware.Autenticado, controllers.ImportarAlunos)
+		aluno.POST("/login", controllers.LoginAluno)
+		aluno.PUT("/senha/:id", middleware.Autenticado, controllers.DefinirSenhaAluno)
 		aluno.GET("/desativar/:id", middleware.Autenticado, controllers.DesativarAluno)
 		aluno.GET("/reativar/:id", middleware.Autenticado, controllers.ReativarAluno)
 		aluno.DELETE("/:id", middleware.Autenticado, controllers.RemoverAluno)
+		aluno.GET("/:id/presenca/historico", middleware.Autenticado, controllers.ListarHistoricoPresencaAluno)
+		aluno.GET("/:id/notas/historico", middleware.Autenticado, controllers.ListarHistoricoNotasAluno)
+		aluno.GET("/:id/historico", middleware.Autenticado, controllers.ListarHistoricoEscolar)
+		aluno.GET("/:id/boletim", middleware.Autenticado, controllers.GerarBoletim)
+		aluno.GET("/alertas", middleware.AlunoAutenticado, controllers.ListarAlertasAluno)
+		aluno.PATCH("/alertas/:id/lido", middleware.AlunoAutenticado, controllers.MarcarAlertaLido)
+		aluno.GET("/rubrica/:avaliacaoId", middleware.AlunoAutenticado, controllers.GetFeedbackRubricaAluno)
+		aluno.POST("/entrega/:avaliacaoId", middleware.AlunoAutenticado, controllers.EnviarEntrega)
+		aluno.GET("/entrega/:avaliacaoId", middleware.AlunoAutenticado, controllers.ListarEntregasAluno)
+		aluno.GET("/questionario/:avaliacaoId", middleware.AlunoAutenticado, controllers.GetTentativaAluno)
+		aluno.POST("/questionario/:avaliacaoId/iniciar", middleware.AlunoAutenticado, controllers.IniciarTentativa)
+		aluno.PUT("/questionario/:avaliacaoId/resposta", middleware.AlunoAutenticado, controllers.ResponderQuestao)
+		aluno.POST("/questionario/:avaliacaoId/finalizar", middleware.AlunoAutenticado, controllers.FinalizarTentativa)
 	}
 
 	{
@@ -23,6 +51,20 @@ func RegistraRotas(router *gin.Engine) {
 		aula.POST("/:disciplinaId", middleware.Autenticado, controllers.CadastrarAula)
 		aula.GET("/disciplina/:disciplinaId", middleware.Autenticado, controllers.ListarAulasDisciplina)
 		aula.GET("/:id", middleware.Autenticado, controllers.GetAula)
+		aula.PUT("/:id", middleware.Autenticado, controllers.SubstituirAula)
+		aula.PATCH("/:id", middleware.Autenticado, controllers.AtualizarAula)
+		aula.DELETE("/:id", middleware.Autenticado, controllers.RemoverAula)
+		aula.PUT("/:id/presenca", middleware.Autenticado, controllers.CorrigirPresenca)
+		aula.GET("/:id/presenca/historico", middleware.Autenticado, controllers.ListarHistoricoPresencaAula)
+	}
+
+	{
+		chamada := api.Group("/chamada")
+		chamada.POST("/:aulaId", middleware.Autenticado, controllers.AbrirChamada)
+		chamada.GET("/:aulaId", middleware.Autenticado, controllers.GetCodigoChamada)
+		chamada.GET("/:aulaId/qrcode", middleware.Autenticado, controllers.GetQRCodeChamada)
+		chamada.DELETE("/:aulaId", middleware.Autenticado, controllers.EncerrarChamada)
+		chamada.POST("/:aulaId/checkin", middleware.AlunoAutenticado, controllers.RegistrarCheckIn)
 	}
 
 	{
@@ -31,8 +73,53 @@ func RegistraRotas(router *gin.Engine) {
 		disciplina.POST("/matricular", middleware.Autenticado, controllers.MatricularAluno)
 		disciplina.POST("/avaliacao/:disciplinaId", middleware.Autenticado, controllers.AdicionarAvaliacao)
 		disciplina.POST("/avaliacao/:disciplinaId/nota/:avaliacaoId", middleware.Autenticado, controllers.AdicionarNotaAvaliacao)
+		disciplina.PUT("/avaliacao/:disciplinaId/:avaliacaoId", middleware.Autenticado, controllers.SubstituirAvaliacao)
+		disciplina.PATCH("/avaliacao/:disciplinaId/:avaliacaoId", middleware.Autenticado, controllers.AtualizarAvaliacao)
+		disciplina.DELETE("/avaliacao/:disciplinaId/:avaliacaoId", middleware.Autenticado, controllers.RemoverAvaliacao)
+		disciplina.PATCH("/avaliacao/:disciplinaId/:avaliacaoId/nota/:alunoId", middleware.Autenticado, controllers.CorrigirNota)
+		disciplina.GET("/avaliacao/:disciplinaId/:avaliacaoId/historico", middleware.Autenticado, controllers.ListarHistoricoNotasAvaliacao)
+		disciplina.GET("/avaliacao/:disciplinaId/:avaliacaoId/estatisticas", middleware.Autenticado, controllers.GetEstatisticasAvaliacao)
+		disciplina.GET("/avaliacao/:disciplinaId/:avaliacaoId/planilha", middleware.Autenticado, controllers.BaixarPlanilhaNotas)
+		disciplina.POST("/avaliacao/:disciplinaId/:avaliacaoId/planilha", middleware.Autenticado, controllers.ImportarNotasAvaliacao)
+		disciplina.PUT("/avaliacao/:disciplinaId/:avaliacaoId/rubrica", middleware.Autenticado, controllers.DefinirRubrica)
+		disciplina.GET("/avaliacao/:disciplinaId/:avaliacaoId/rubrica", middleware.Autenticado, controllers.GetRubrica)
+		disciplina.PUT("/avaliacao/:disciplinaId/:avaliacaoId/rubrica/:alunoId", middleware.Autenticado, controllers.CorrigirRubrica)
+		disciplina.GET("/avaliacao/:disciplinaId/:avaliacaoId/rubrica/:alunoId", middleware.Autenticado, controllers.GetFeedbackRubrica)
+		disciplina.PUT("/avaliacao/:disciplinaId/:avaliacaoId/entrega", middleware.Autenticado, controllers.DefinirJanelaEntrega)
+		disciplina.GET("/avaliacao/:disciplinaId/:avaliacaoId/entregas", middleware.Autenticado, controllers.ListarEntregasAvaliacao)
+		disciplina.GET("/avaliacao/:disciplinaId/:avaliacaoId/entregas/zip", middleware.Autenticado, controllers.BaixarEntregas)
+		disciplina.PUT("/avaliacao/:disciplinaId/:avaliacaoId/questionario", middleware.Autenticado, controllers.MontarQuestionario)
+		disciplina.GET("/avaliacao/:disciplinaId/:avaliacaoId/questionario", middleware.Autenticado, controllers.GetQuestionario)
+		disciplina.GET("/avaliacao/:disciplinaId/:avaliacaoId/tentativas", middleware.Autenticado, controllers.ListarTentativas)
 		disciplina.GET("/", middleware.Autenticado, controllers.ListarDisciplinas)
 		disciplina.GET("/fechar-semestre/:disciplinaId", middleware.Autenticado, controllers.FecharSemestre)
+		disciplina.GET("/fechar-recuperacao/:disciplinaId", middleware.Autenticado, controllers.FecharRecuperacao)
+		disciplina.GET("/resultados/:disciplinaId", middleware.Autenticado, controllers.ListarResultadosDisciplina)
+		disciplina.GET("/estatisticas/:disciplinaId", middleware.Autenticado, controllers.GetEstatisticasDisciplina)
+		disciplina.PATCH("/configuracao/:disciplinaId", middleware.Autenticado, controllers.ConfigurarDisciplina)
+		disciplina.GET("/alertas/:disciplinaId", middleware.Autenticado, controllers.ListarAlertasDisciplina)
+		disciplina.GET("/pesos/:disciplinaId", middleware.Autenticado, controllers.VerificarPesos)
+		disciplina.GET("/escala/:disciplinaId", middleware.Autenticado, controllers.GetEscalaDisciplina)
+		disciplina.GET("/diario/:disciplinaId", middleware.Autenticado, controllers.ExportarDiarioClasse)
+		disciplina.GET("/ata/:disciplinaId", middleware.Autenticado, controllers.ExportarAtaFinal)
+	}
+
+	{
+		justificativa := api.Group("/justificativa")
+		justificativa.POST("/", middleware.UsuarioAutenticado, controllers.EnviarJustificativa)
+		justificativa.GET("/aluno", middleware.AlunoAutenticado, controllers.ListarJustificativasAluno)
+		justificativa.GET("/disciplina/:disciplinaId", middleware.Autenticado, controllers.ListarJustificativasDisciplina)
+		justificativa.GET("/:id", middleware.UsuarioAutenticado, controllers.GetJustificativa)
+		justificativa.GET("/:id/documento", middleware.UsuarioAutenticado, controllers.BaixarDocumentoJustificativa)
+		justificativa.POST("/:id/aprovar", middleware.Autenticado, controllers.AprovarJustificativa)
+		justificativa.POST("/:id/rejeitar", middleware.Autenticado, controllers.RejeitarJustificativa)
+	}
+
+	{
+		questao := api.Group("/questao")
+		questao.POST("/", middleware.Autenticado, controllers.CadastrarQuestao)
+		questao.GET("/", middleware.Autenticado, controllers.ListarQuestoes)
+		questao.DELETE("/:id", middleware.Autenticado, controllers.RemoverQuestao)
 	}
 
 	{
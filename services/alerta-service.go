@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// margensAlertaPadrao são as margens, em pontos percentuais acima da frequência mínima, usadas quando a variável de
+// ambiente ALERTA_FREQUENCIA_MARGENS não é definida
+var margensAlertaPadrao = []float64{10, 5}
+
+// filaAvaliacaoFrequencia recebe os IDs das disciplinas cuja frequência dos alunos deve ser reavaliada
+//
+// É criada por IniciaAvaliadorFrequencia; enquanto for nula, nenhuma avaliação é agendada
+var filaAvaliacaoFrequencia chan string
+
+// IniciaAvaliadorFrequencia inicia o processamento em segundo plano das avaliações de risco de reprovação por falta
+//
+// Deve ser chamada uma única vez, na inicialização da aplicação
+func IniciaAvaliadorFrequencia() {
+	filaAvaliacaoFrequencia = make(chan string, 100)
+
+	go func() {
+		for disciplinaId := range filaAvaliacaoFrequencia {
+			if restErr := avaliaRiscoFrequencia(disciplinaId); restErr != nil {
+				log.Printf("Erro ao avaliar frequência da disciplina %s: %v", disciplinaId, restErr.Err)
+			}
+		}
+	}()
+}
+
+// ListarAlertasAluno retorna os alertas de frequência de um aluno, do mais recente para o mais antigo
+//
+// Se `apenasNaoLidos` for verdadeiro, apenas os alertas ainda não lidos são retornados
+func ListarAlertasAluno(alunoId string, apenasNaoLidos bool) ([]models.AlertaFrequencia, *utils.RestErr) {
+	query := database.DB.Preload("Disciplina").Where("aluno_id = ?", alunoId)
+	if apenasNaoLidos {
+		query = query.Where("lido = false")
+	}
+
+	var alertas []models.AlertaFrequencia
+	if err := query.Order("created_at DESC").Find(&alertas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alertas", err)
+	}
+
+	return alertas, nil
+}
+
+// ListarAlertasDisciplina retorna os alertas de frequência emitidos para os alunos de uma disciplina
+func ListarAlertasDisciplina(disciplinaId string) ([]models.AlertaFrequencia, *utils.RestErr) {
+	if _, restErr := buscaDisciplina(disciplinaId); restErr != nil {
+		return nil, restErr
+	}
+
+	var alertas []models.AlertaFrequencia
+	err := database.DB.Preload("Aluno").Where("disciplina_id = ?", disciplinaId).Order("created_at DESC").Find(&alertas).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alertas", err)
+	}
+
+	return alertas, nil
+}
+
+// MarcarAlertaLido marca um alerta de frequência do aluno como lido
+//
+// Retorna o alerta atualizado ou erro caso não exista ou pertença a outro aluno
+func MarcarAlertaLido(id string, alunoId string) (*models.AlertaFrequencia, *utils.RestErr) {
+	var alerta models.AlertaFrequencia
+	if err := database.DB.Where("id = ?", id).First(&alerta).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "Alerta não encontrado", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alerta", err)
+	}
+
+	if alerta.AlunoId != alunoId {
+		return nil, utils.NewRestErr(http.StatusForbidden, "Alerta pertence a outro aluno", nil)
+	}
+
+	alerta.Lido = true
+	if err := database.DB.Model(&alerta).Update("lido", true).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar alerta", err)
+	}
+
+	return &alerta, nil
+}
+
+// agendaAvaliacaoFrequencia solicita a reavaliação do risco de reprovação por falta dos alunos de uma disciplina
+//
+// Não bloqueia o chamador: se a fila estiver cheia, a solicitação é descartada e registrada no log
+func agendaAvaliacaoFrequencia(disciplinaId string) {
+	if filaAvaliacaoFrequencia == nil {
+		return
+	}
+
+	select {
+	case filaAvaliacaoFrequencia <- disciplinaId:
+	default:
+		log.Printf("Fila de avaliação de frequência cheia; disciplina %s ignorada", disciplinaId)
+	}
+}
+
+// avaliaRiscoFrequencia projeta a frequência máxima alcançável de cada aluno ativo de uma disciplina aberta e emite
+// os alertas cujas margens foram ultrapassadas
+//
+// Alertas já emitidos não são repetidos. Se o envio de e-mail estiver configurado, cada novo alerta também é enviado
+// ao e-mail do aluno
+func avaliaRiscoFrequencia(disciplinaId string) *utils.RestErr {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return restErr
+	}
+
+	if !disciplina.Aberta() {
+		return nil
+	}
+
+	var aulas []models.Aula
+	if err := database.DB.Where("disciplina_id = ?", disciplinaId).Find(&aulas).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar aulas da disciplina", err)
+	}
+
+	var alunos []models.Aluno
+	err := database.DB.
+		Joins("JOIN aluno_disciplina ON aluno_disciplina.aluno_id = alunos.id").
+		Where("aluno_disciplina.disciplina_id = ? AND alunos.ativo = true", disciplinaId).
+		Find(&alunos).Error
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos da disciplina", err)
+	}
+
+	margens := margensAlerta()
+	for _, aluno := range alunos {
+		assistido, restErr := contabilizaPresenca(disciplina, aulas, aluno.Id)
+		if restErr != nil {
+			return restErr
+		}
+
+		atual, maxima := projetaFrequencia(disciplina, aulas, assistido)
+
+		var alertas []models.AlertaFrequencia
+		if maxima < disciplina.FrequenciaMinima {
+			alertas = append(alertas, models.AlertaFrequencia{
+				Tipo: models.AlertaReprovadoFrequencia,
+				Mensagem: fmt.Sprintf("Mesmo comparecendo a todas as aulas restantes, sua frequência máxima em %s "+
+					"será de %.1f%%, abaixo do mínimo de %.1f%%.", disciplina.Nome, maxima, disciplina.FrequenciaMinima),
+			})
+		} else {
+			for _, margem := range margens {
+				if maxima < disciplina.FrequenciaMinima+margem {
+					alertas = append(alertas, models.AlertaFrequencia{
+						Tipo:   models.AlertaRiscoFrequencia,
+						Margem: margem,
+						Mensagem: fmt.Sprintf("Sua frequência máxima possível em %s é de %.1f%%, a menos de %.0f pontos "+
+							"do mínimo de %.1f%%.", disciplina.Nome, maxima, margem, disciplina.FrequenciaMinima),
+					})
+				}
+			}
+		}
+
+		for _, alerta := range alertas {
+			alerta.AlunoId = aluno.Id
+			alerta.DisciplinaId = disciplina.Id
+			alerta.FrequenciaAtual = atual
+			alerta.FrequenciaMaximaPossivel = maxima
+
+			if restErr := emiteAlerta(&alerta, aluno.Email); restErr != nil {
+				return restErr
+			}
+		}
+	}
+
+	return nil
+}
+
+// emiteAlerta grava o alerta caso ele ainda não tenha sido emitido e, se possível, o envia por e-mail ao aluno
+func emiteAlerta(alerta *models.AlertaFrequencia, email string) *utils.RestErr {
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(alerta)
+	if result.Error != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar alerta", result.Error)
+	}
+
+	if result.RowsAffected == 0 || !utils.EmailConfigurado() {
+		return nil
+	}
+
+	if err := utils.EnviaEmail(email, "Alerta de frequência", alerta.Mensagem); err != nil {
+		log.Printf("Erro ao enviar alerta %s por e-mail: %v", alerta.Id, err)
+		return nil
+	}
+
+	if err := database.DB.Model(alerta).Update("email_enviado", true).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar alerta", err)
+	}
+
+	return nil
+}
+
+// projetaFrequencia calcula a frequência atual e a frequência máxima que o aluno ainda pode alcançar
+//
+// A projeção considera que o aluno comparecerá integralmente às aulas restantes até completar a carga horária
+// prevista. No modo "aulas", o número de aulas restantes é estimado pela duração média das aulas já registradas
+func projetaFrequencia(disciplina *models.Disciplina, aulas []models.Aula, assistido int) (float64, float64) {
+	var atual float64
+	if total := totalFrequencia(disciplina, aulas); total > 0 {
+		atual = float64(assistido) / float64(total) * 100
+	}
+
+	horasRestantes := disciplina.CargaHorariaPrevista - disciplina.CargaHorariaRealizada
+	if horasRestantes < 0 {
+		horasRestantes = 0
+	}
+
+	realizado, restante := disciplina.CargaHorariaRealizada, horasRestantes
+	if disciplina.ModoFrequencia == models.FrequenciaPorAulas {
+		realizado, restante = len(aulas), 0
+		if horasRestantes > 0 {
+			duracaoMedia := 1.0
+			if len(aulas) > 0 && disciplina.CargaHorariaRealizada > 0 {
+				duracaoMedia = float64(disciplina.CargaHorariaRealizada) / float64(len(aulas))
+			}
+			restante = int(math.Ceil(float64(horasRestantes) / duracaoMedia))
+		}
+	}
+
+	if realizado+restante == 0 {
+		return atual, 100
+	}
+
+	return atual, float64(assistido+restante) / float64(realizado+restante) * 100
+}
+
+// margensAlerta lê as margens de alerta da variável de ambiente ALERTA_FREQUENCIA_MARGENS
+//
+// O valor é uma lista de números separados por vírgula, em pontos percentuais acima da frequência mínima (por exemplo,
+// "10,5"). Valores inválidos são ignorados e, se nenhum for válido, são usadas as margens padrão
+func margensAlerta() []float64 {
+	valor := os.Getenv("ALERTA_FREQUENCIA_MARGENS")
+	if valor == "" {
+		return margensAlertaPadrao
+	}
+
+	var margens []float64
+	for _, parte := range strings.Split(valor, ",") {
+		margem, err := strconv.ParseFloat(strings.TrimSpace(parte), 64)
+		if err == nil && margem > 0 {
+			margens = append(margens, margem)
+		}
+	}
+
+	if len(margens) == 0 {
+		return margensAlertaPadrao
+	}
+
+	sort.Sort(sort.Reverse(sort.Float64Slice(margens)))
+	return margens
+}
@@ -2,17 +2,20 @@ package services
 
 import (
 	"errors"
+	"github.com/golang-jwt/jwt"
 	"gorm.io/gorm"
 	"net/http"
+	"os"
 	"sistema-alunos-go/database"
 	"sistema-alunos-go/models"
 	"sistema-alunos-go/utils"
+	"time"
 )
 
 // CadastrarAluno insere um novo aluno no banco.
 //
 // Ele verifica se já existe um aluno com o mesmo e-mail.
-// Se não houver, ativa o aluno e o salva.
+// Se não houver, ativa o aluno e o salva, publicando o evento aluno.cadastrado para os webhooks.
 //
 // Retorna o aluno cadastrado ou um erro, caso ocorra falha na verificação ou na criação.
 func CadastrarAluno(aluno models.Aluno) (*models.Aluno, *utils.RestErr) {
@@ -26,7 +29,21 @@ func CadastrarAluno(aluno models.Aluno) (*models.Aluno, *utils.RestErr) {
 	}
 
 	aluno.Ativo = true
-	if err := database.DB.Create(&aluno).Error; err != nil {
+	var restErrTx *utils.RestErr
+	err := transacaoEventos(func(tx *gorm.DB) error {
+		if err := tx.Create(&aluno).Error; err != nil {
+			return err
+		}
+
+		if restErrTx = publicaEvento(tx, models.EventoAlunoCadastrado, aluno); restErrTx != nil {
+			return restErrTx.Err
+		}
+		return nil
+	})
+	if restErrTx != nil {
+		return nil, restErrTx
+	}
+	if err != nil {
 		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar aluno", err)
 	}
 	return &aluno, nil
@@ -101,6 +118,109 @@ func RemoverAluno(id string) *utils.RestErr {
 	return nil
 }
 
+// DefinirSenhaAluno define a senha de acesso de um aluno
+//
+// Apenas coordenadores e professores de alguma disciplina em que o aluno está matriculado podem definir a senha. A
+// senha é criptografada antes de ser salva. Alunos com senha definida podem se autenticar pelo LoginAluno
+//
+// Retorna erro caso o aluno não exista, o professor não tenha permissão ou ocorra falha ao salvar
+func DefinirSenhaAluno(alunoId string, senha string, professorId string) *utils.RestErr {
+	aluno, restErr := buscaAluno(alunoId)
+	if restErr != nil {
+		return restErr
+	}
+
+	if restErr := verificaProfessorAluno(professorId, alunoId); restErr != nil {
+		return restErr
+	}
+
+	hash, err := utils.CriptografaSenha(senha)
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao criptografar senha", err)
+	}
+
+	if err := database.DB.Model(aluno).Update("senha", hash).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao definir senha do aluno", err)
+	}
+	return nil
+}
+
+// LoginAluno autentica um aluno com base no e-mail e senha fornecidos
+//
+// Apenas alunos ativos e com senha definida podem se autenticar. Gera um token JWT com o ID do aluno. E-mail não
+// cadastrado e senha incorreta recebem o mesmo erro, para não revelar quais e-mails pertencem a alunos
+//
+// Retorna o token gerado e os dados do aluno ou erro em caso de credenciais inválidas ou falha de autenticação
+func LoginAluno(login models.Login) (string, *models.Aluno, *utils.RestErr) {
+	var aluno models.Aluno
+	if err := database.DB.Where("email = ?", login.Email).First(&aluno).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil, utils.NewRestErr(http.StatusUnauthorized, "E-mail ou senha incorretos", nil)
+		}
+		return "", nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar aluno", err)
+	}
+
+	if aluno.Senha == "" || !utils.ComparaSenha(login.Senha, aluno.Senha) {
+		return "", nil, utils.NewRestErr(http.StatusUnauthorized, "E-mail ou senha incorretos", nil)
+	}
+
+	if !aluno.Ativo {
+		return "", nil, utils.NewRestErr(http.StatusForbidden, "Aluno com matrícula trancada", nil)
+	}
+
+	token, err := geraTokenAluno(&aluno)
+	if err != nil {
+		return "", nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao gerar token", err)
+	}
+	return token, &aluno, nil
+}
+
+// geraTokenAluno cria um token JWT com o ID e o e-mail do aluno autenticado com um tempo de expiração de 24 horas
+//
+// Retorna o token JWT como string ou erro em caso de falha ao assinar
+func geraTokenAluno(aluno *models.Aluno) (string, error) {
+	secret := os.Getenv("JWT_SECRET")
+
+	claims := jwt.MapClaims{
+		"aluno": map[string]interface{}{ // Dados do aluno
+			"id":    aluno.Id,
+			"email": aluno.Email,
+		},
+		"exp": time.Now().Add(time.Hour * 24).Unix(), // Expiração do token (1 dia)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(secret))
+}
+
+// verificaProfessorAluno confere se o professor pode administrar o acesso do aluno: coordenadores podem administrar
+// qualquer aluno, e os demais professores, apenas os alunos matriculados em alguma de suas disciplinas
+//
+// Retorna erro 403 caso o professor não tenha permissão ou erro caso ele não exista ou a consulta falhe
+func verificaProfessorAluno(professorId string, alunoId string) *utils.RestErr {
+	professor, restErr := buscaProfessor(professorId)
+	if restErr != nil {
+		return restErr
+	}
+	if professor.Coordenador {
+		return nil
+	}
+
+	var matriculas int64
+	err := database.DB.Model(&models.AlunoDisciplina{}).
+		Joins("JOIN disciplinas ON disciplinas.id = aluno_disciplina.disciplina_id").
+		Where("aluno_disciplina.aluno_id = ? AND disciplinas.professor_id = ?", alunoId, professorId).
+		Count(&matriculas).Error
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar disciplinas do aluno", err)
+	}
+
+	if matriculas == 0 {
+		return utils.NewRestErr(http.StatusForbidden, "O aluno não está matriculado em nenhuma disciplina do professor", nil)
+	}
+	return nil
+}
+
 // buscaAluno busca um aluno pelo ID
 //
 // Retorna o aluno encontrado ou erro caso não exista ou a consulta falhe
@@ -2,18 +2,25 @@ package services
 
 import (
 	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"net/http"
 	"sistema-alunos-go/database"
 	"sistema-alunos-go/models"
 	"sistema-alunos-go/utils"
 )
 
+// mensagemNumeroAulaRepetido é a mensagem de erro para aulas com número já cadastrado na disciplina
+const mensagemNumeroAulaRepetido = "Aula com esse número já cadastrada para a disciplina"
+
 // CadastrarAula registra uma nova aula para uma disciplina
 //
 // A função recebe os dados da aula e o ID da disciplina à qual ela pertence
-// Antes de cadastrar, verifica se já existe uma aula com o mesmo número naquela disciplina
-// Também atualiza a carga horária realizada da disciplina
+// Antes de cadastrar, verifica se o semestre da disciplina ainda está aberto e se já existe uma aula com o mesmo número
+// naquela disciplina. A lista de presenças é validada contra os alunos matriculados, completando os ausentes da lista
+// com a presença padrão. Também atualiza a carga horária realizada da disciplina e publica o evento aula.registrada
+// para os webhooks na mesma transação, e agenda a avaliação do risco de reprovação por falta dos alunos
 //
 // Retorna a aula cadastrada ou um erro, caso haja falha de validação ou de persistência
 func CadastrarAula(aula *models.Aula, disciplinaId string) (*models.Aula, *utils.RestErr) {
@@ -24,28 +31,178 @@ func CadastrarAula(aula *models.Aula, disciplinaId string) (*models.Aula, *utils
 		return nil, restErr
 	}
 
-	disciplina.CargaHorariaRealizada += aula.QuantidadeHoras
-
-	if err := database.DB.Save(disciplina).Error; err != nil {
-		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar disciplina", err)
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
 	}
 
-	var aulaExist models.Aula
-	err := database.DB.Where("disciplina_id = ? AND numero = ?", aula.DisciplinaId, aula.Numero).First(&aulaExist).Error
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar aulas", err)
+	if restErr := verificaNumeroAula(aula.DisciplinaId, aula.Numero, ""); restErr != nil {
+		return nil, restErr
 	}
-	if aulaExist.Id != "" {
-		return nil, utils.NewRestErr(http.StatusBadRequest, "Aula com esse número já cadastrada para a disciplina", nil)
+
+	aula.AlunoAula, restErr = validaChamada(aula.DisciplinaId, aula.QuantidadeHoras, aula.AlunoAula)
+	if restErr != nil {
+		return nil, restErr
 	}
 
-	if err := database.DB.Create(aula).Error; err != nil {
+	var restErrTx *utils.RestErr
+	err := transacaoEventos(func(tx *gorm.DB) error {
+		if err := tx.Create(aula).Error; err != nil {
+			restErrTx = erroGravacaoAula(err, "Erro ao criar aula")
+			return err
+		}
+
+		if restErrTx = recalculaCargaHoraria(tx, aula.DisciplinaId); restErrTx != nil {
+			return restErrTx.Err
+		}
+
+		if restErrTx = publicaEvento(tx, models.EventoAulaRegistrada, aula); restErrTx != nil {
+			return restErrTx.Err
+		}
+		return nil
+	})
+	if restErrTx != nil {
+		return nil, restErrTx
+	}
+	if err != nil {
 		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao criar aula", err)
 	}
 
+	agendaAvaliacaoFrequencia(aula.DisciplinaId)
 	return aula, nil
 }
 
+// AtualizarAula altera os dados de uma aula já cadastrada
+//
+// Apenas os campos informados em `dados` são alterados. Se a lista de presenças for enviada, ela substitui todos os
+// registros de presença da aula após ser validada contra os alunos matriculados, e cada diferença é registrada no
+// histórico de presenças. Se apenas a duração da aula mudar, as horas presentes dos alunos são ajustadas à nova
+// duração. A unicidade do número da aula na disciplina é mantida e a carga horária realizada da disciplina é
+// recalculada a partir das aulas cadastradas
+//
+// Retorna a aula atualizada ou erro caso a aula não exista, a disciplina esteja fechada ou a persistência falhe
+func AtualizarAula(id string, dados models.AtualizacaoAula, professorId string) (*models.Aula, *utils.RestErr) {
+	aula, restErr := buscaAula(id)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	disciplina, restErr := buscaDisciplina(aula.DisciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	if dados.Numero != nil && *dados.Numero != aula.Numero {
+		if restErr := verificaNumeroAula(aula.DisciplinaId, *dados.Numero, aula.Id); restErr != nil {
+			return nil, restErr
+		}
+		aula.Numero = *dados.Numero
+	}
+	if dados.Data != nil {
+		aula.Data = *dados.Data
+	}
+	if dados.Conteudo != nil {
+		aula.Conteudo = *dados.Conteudo
+	}
+
+	horasAnteriores := aula.QuantidadeHoras
+	if dados.QuantidadeHoras != nil {
+		aula.QuantidadeHoras = *dados.QuantidadeHoras
+	}
+
+	if dados.AlunoAula != nil {
+		dados.AlunoAula, restErr = validaChamada(aula.DisciplinaId, aula.QuantidadeHoras, dados.AlunoAula)
+		if restErr != nil {
+			return nil, restErr
+		}
+	} else if aula.QuantidadeHoras != horasAnteriores {
+		dados.AlunoAula, restErr = ajustaHorasPresencas(aula.Id, horasAnteriores, aula.QuantidadeHoras)
+		if restErr != nil {
+			return nil, restErr
+		}
+	}
+
+	var restErrTx *utils.RestErr
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(aula).Error; err != nil {
+			restErrTx = erroGravacaoAula(err, "Erro ao atualizar aula")
+			return err
+		}
+
+		if dados.AlunoAula != nil {
+			if restErrTx = substituiPresencas(tx, aula.Id, dados.AlunoAula, professorId); restErrTx != nil {
+				return restErrTx.Err
+			}
+		}
+
+		if restErrTx = recalculaCargaHoraria(tx, aula.DisciplinaId); restErrTx != nil {
+			return restErrTx.Err
+		}
+		return nil
+	})
+	if restErrTx != nil {
+		return nil, restErrTx
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar aula", err)
+	}
+
+	agendaAvaliacaoFrequencia(aula.DisciplinaId)
+	return GetAula(aula.Id)
+}
+
+// RemoverAula apaga uma aula e os registros de presença associados
+//
+// A carga horária realizada da disciplina é recalculada após a remoção. Não é permitido remover aulas de disciplinas
+// com o semestre fechado
+//
+// Retorna erro caso a aula não exista, a disciplina esteja fechada ou a remoção falhe
+func RemoverAula(id string) *utils.RestErr {
+	aula, restErr := buscaAula(id)
+	if restErr != nil {
+		return restErr
+	}
+
+	disciplina, restErr := buscaDisciplina(aula.DisciplinaId)
+	if restErr != nil {
+		return restErr
+	}
+
+	if !disciplina.Aberta() {
+		return utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	var restErrTx *utils.RestErr
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("aula_id = ?", aula.Id).Delete(&models.AlunoAula{}).Error; err != nil {
+			restErrTx = utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover presenças da aula", err)
+			return err
+		}
+
+		if err := tx.Delete(aula).Error; err != nil {
+			restErrTx = utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover aula", err)
+			return err
+		}
+
+		if restErrTx = recalculaCargaHoraria(tx, aula.DisciplinaId); restErrTx != nil {
+			return restErrTx.Err
+		}
+		return nil
+	})
+	if restErrTx != nil {
+		return restErrTx
+	}
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover aula", err)
+	}
+
+	agendaAvaliacaoFrequencia(aula.DisciplinaId)
+	return nil
+}
+
 // ListarAulasDisciplina retorna todas as aulas relacionadas a uma disciplina
 //
 // A função busca todas as aulas associadas ao ID da disciplina fornecida, incluindo a lista de presenças (`AlunoAula`)
@@ -84,3 +241,130 @@ func GetAula(id string) (*models.Aula, *utils.RestErr) {
 
 	return aula, nil
 }
+
+// buscaAula busca uma aula pelo ID, sem carregar os registros de presença
+//
+// Retorna a aula encontrada ou erro caso não exista ou a consulta falhe
+func buscaAula(id string) (*models.Aula, *utils.RestErr) {
+	var aula models.Aula
+	err := database.DB.Where("id = ?", id).First(&aula).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "Aula não encontrada", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar aula", err)
+	}
+
+	return &aula, nil
+}
+
+// verificaNumeroAula verifica se já existe outra aula com o mesmo número na disciplina
+//
+// O parâmetro `ignorarId` permite desconsiderar a própria aula durante uma atualização.
+//
+// Retorna erro 400 caso o número já esteja em uso ou erro interno se a consulta falhar
+func verificaNumeroAula(disciplinaId string, numero int, ignorarId string) *utils.RestErr {
+	query := database.DB.Model(&models.Aula{}).Where("disciplina_id = ? AND numero = ?", disciplinaId, numero)
+	if ignorarId != "" {
+		query = query.Where("id <> ?", ignorarId)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar aulas", err)
+	}
+	if total > 0 {
+		return utils.NewRestErr(http.StatusConflict, mensagemNumeroAulaRepetido, nil)
+	}
+
+	return nil
+}
+
+// erroGravacaoAula converte o erro ao gravar uma aula, tratando como conflito o número já usado na disciplina
+//
+// A verificação de verificaNumeroAula não impede que duas aulas com o mesmo número sejam gravadas ao mesmo tempo; o
+// índice único idx_aula_numero recusa a segunda
+func erroGravacaoAula(err error, mensagem string) *utils.RestErr {
+	var erroPg *pgconn.PgError
+	if errors.As(err, &erroPg) && erroPg.Code == "23505" && erroPg.ConstraintName == "idx_aula_numero" {
+		return utils.NewRestErr(http.StatusConflict, mensagemNumeroAulaRepetido, err)
+	}
+	return utils.NewRestErr(http.StatusInternalServerError, mensagem, err)
+}
+
+// recalculaCargaHoraria atualiza a carga horária realizada de uma disciplina somando a duração de todas as suas aulas
+//
+// Deve ser chamada dentro da mesma transação que alterou as aulas, para manter a disciplina consistente
+func recalculaCargaHoraria(tx *gorm.DB, disciplinaId string) *utils.RestErr {
+	var total int
+	err := tx.Model(&models.Aula{}).
+		Select("COALESCE(SUM(quantidade_horas), 0)").
+		Where("disciplina_id = ?", disciplinaId).
+		Scan(&total).Error
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao calcular carga horária realizada", err)
+	}
+
+	err = tx.Model(&models.Disciplina{}).
+		Where("id = ?", disciplinaId).
+		Update("carga_horaria_realizada", total).Error
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar disciplina", err)
+	}
+
+	return nil
+}
+
+// substituiPresencas faz com que os registros de presença de uma aula correspondam exatamente à lista informada
+//
+// Alunos da lista têm a presença criada ou atualizada e alunos ausentes da lista têm o registro removido, sempre
+// registrando as diferenças no histórico de presenças. Deve ser chamada dentro de uma transação
+func substituiPresencas(tx *gorm.DB, aulaId string, presencas []models.AlunoAula, professorId string) *utils.RestErr {
+	const motivo = "Atualização dos dados da aula"
+
+	var atuais []models.AlunoAula
+	if err := tx.Where("aula_id = ?", aulaId).Find(&atuais).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar presenças da aula", err)
+	}
+
+	informados := make(map[string]bool, len(presencas))
+	for _, p := range presencas {
+		informados[p.AlunoId] = true
+		p.AulaId = aulaId
+		if _, restErr := registraPresenca(tx, p, professorId, models.AutorProfessor, motivo); restErr != nil {
+			return restErr
+		}
+	}
+
+	for _, atual := range atuais {
+		if informados[atual.AlunoId] {
+			continue
+		}
+		if restErr := removePresenca(tx, atual, professorId, models.AutorProfessor, motivo); restErr != nil {
+			return restErr
+		}
+	}
+
+	return nil
+}
+
+// ajustaHorasPresencas monta a lista de presenças de uma aula cuja duração foi alterada
+//
+// Alunos que assistiram a aula completa passam a ter a nova duração e presenças parciais são limitadas à nova duração.
+//
+// Retorna a lista ajustada, que deve ser aplicada com substituiPresencas, ou erro caso a consulta falhe
+func ajustaHorasPresencas(aulaId string, horasAnteriores int, horasNovas int) ([]models.AlunoAula, *utils.RestErr) {
+	var presencas []models.AlunoAula
+	if err := database.DB.Where("aula_id = ?", aulaId).Find(&presencas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar presenças da aula", err)
+	}
+
+	for i := range presencas {
+		if presencas[i].HorasPresentes == horasAnteriores || presencas[i].HorasPresentes > horasNovas {
+			presencas[i].HorasPresentes = horasNovas
+		}
+		normalizaHorasPresenca(&presencas[i], horasNovas)
+	}
+
+	return presencas, nil
+}
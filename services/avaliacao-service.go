@@ -0,0 +1,451 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/formula"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// toleranciaPesos é a diferença máxima aceita entre a soma dos pesos das avaliações e 1
+const toleranciaPesos = 1e-6
+
+// toleranciaMedia é a diferença máxima aceita entre uma média e os limites de 0 a 10, para absorver erros de
+// arredondamento
+const toleranciaMedia = 1e-9
+
+// motivoRemocaoAvaliacao é a justificativa registrada no histórico de notas quando uma avaliação é removida
+const motivoRemocaoAvaliacao = "Avaliação removida"
+
+// AtualizarAvaliacao altera os dados de uma avaliação de uma disciplina
+//
+// Apenas os campos informados em `dados` são alterados. Se o tipo mudar, os contadores de provas e trabalhos da
+// disciplina são recalculados na mesma transação. Não é permitido alterar avaliações de disciplinas fechadas nem
+// renomear avaliações referenciadas pela fórmula de média da disciplina. A avaliação de recuperação também pode ser
+// alterada durante a recuperação, mas nenhuma avaliação pode passar a ser ou deixar de ser de recuperação. Trabalhos
+// com rubrica ou janela de entrega não podem mudar de tipo
+//
+// Retorna a avaliação atualizada ou erro caso não exista, não pertença à disciplina ou a persistência falhe
+func AtualizarAvaliacao(disciplinaId string, avaliacaoId string, dados models.AtualizacaoAvaliacao) (*models.Avaliacao, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if restErr := verificaAvaliacaoAlteravel(disciplina, avaliacao.Tipo); restErr != nil {
+		return nil, restErr
+	}
+
+	if dados.Tipo != nil && *dados.Tipo != avaliacao.Tipo &&
+		(avaliacao.Recuperacao() || *dados.Tipo == models.AvaliacaoRecuperacao) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Uma avaliação não pode passar a ser ou deixar de ser de recuperação", nil)
+	}
+
+	if dados.Tipo != nil && *dados.Tipo != avaliacao.Tipo {
+		rubrica, restErr := possuiRubrica(avaliacao.Id)
+		if restErr != nil {
+			return nil, restErr
+		}
+		if rubrica {
+			return nil, utils.NewRestErr(http.StatusConflict, "Um trabalho com rubrica não pode mudar de tipo", nil)
+		}
+
+		janela, restErr := buscaJanelaEntrega(avaliacao.Id)
+		if restErr != nil {
+			return nil, restErr
+		}
+		if janela != nil {
+			return nil, utils.NewRestErr(http.StatusConflict, "Um trabalho com janela de entrega não pode mudar de tipo", nil)
+		}
+	}
+
+	if dados.Nome != nil {
+		avaliacao.Nome = *dados.Nome
+	}
+	if dados.Tipo != nil {
+		avaliacao.Tipo = *dados.Tipo
+	}
+	if dados.DataAvaliacao != nil {
+		avaliacao.DataAvaliacao = *dados.DataAvaliacao
+	}
+	if dados.Peso != nil {
+		avaliacao.Peso = *dados.Peso
+	}
+
+	if restErr := validaFormulaSemAvaliacao(disciplina, avaliacao.Id, avaliacao); restErr != nil {
+		return nil, restErr
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(avaliacao).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar avaliação", err)
+			return err
+		}
+
+		if restErr = recalculaQuantidadeAvaliacoes(tx, disciplinaId); restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar avaliação", err)
+	}
+
+	return avaliacao, nil
+}
+
+// RemoverAvaliacao apaga uma avaliação de uma disciplina e as notas lançadas para ela
+//
+// Os contadores de provas e trabalhos da disciplina são recalculados na mesma transação. Não é permitido remover
+// avaliações de disciplinas fechadas nem avaliações referenciadas pela fórmula de média; a avaliação de recuperação
+// também pode ser removida durante a recuperação. A remoção de cada nota é registrada no histórico de notas em nome do
+// professor
+//
+// Retorna erro caso a avaliação não exista, não pertença à disciplina ou a remoção falhe
+func RemoverAvaliacao(disciplinaId string, avaliacaoId string, professorId string) *utils.RestErr {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return restErr
+	}
+
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return restErr
+	}
+
+	if restErr := verificaAvaliacaoAlteravel(disciplina, avaliacao.Tipo); restErr != nil {
+		return restErr
+	}
+
+	if restErr := validaFormulaSemAvaliacao(disciplina, avaliacao.Id, nil); restErr != nil {
+		return restErr
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var notas []models.AlunoAvaliacao
+		if err := tx.Where("avaliacao_id = ?", avaliacao.Id).Find(&notas).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar notas da avaliação", err)
+			return err
+		}
+
+		for _, nota := range notas {
+			if restErr = removeNota(tx, nota, professorId, models.AutorProfessor, motivoRemocaoAvaliacao); restErr != nil {
+				return restErr.Err
+			}
+		}
+
+		if err := tx.Delete(avaliacao).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover avaliação", err)
+			return err
+		}
+
+		if restErr = recalculaQuantidadeAvaliacoes(tx, disciplinaId); restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return restErr
+	}
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover avaliação", err)
+	}
+
+	return nil
+}
+
+// VerificarPesos gera o relatório de consistência dos pesos das avaliações de uma disciplina
+//
+// Deve ser consultado antes do fechamento do semestre, que é recusado quando os pesos não somam 1 e a disciplina não
+// optou pela normalização. A avaliação de recuperação não é considerada
+func VerificarPesos(disciplinaId string) (*models.ConsistenciaPesos, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	var avaliacoes []models.Avaliacao
+	err := database.DB.Where("disciplina_id = ? AND tipo <> ?", disciplinaId, models.AvaliacaoRecuperacao).Find(&avaliacoes).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliações da disciplina", err)
+	}
+
+	return verificaPesos(disciplina, avaliacoes), nil
+}
+
+// calculaMedia calcula a média final de um aluno a partir das notas nas avaliações da disciplina
+//
+// Se `formulaMedia` for nula, usa a média ponderada pelos pesos das avaliações. Avaliações sem nota lançada contam
+// como zero. Uma média fora da faixa de 0 a 10 é recusada com erro 400
+func calculaMedia(formulaMedia *formula.Formula, avaliacoes []models.Avaliacao, notas []models.AlunoAvaliacao) (float64, *utils.RestErr) {
+	var media float64
+	if formulaMedia != nil {
+		variaveis := make([]formula.Avaliacao, 0, len(avaliacoes))
+		for _, a := range avaliacoes {
+			variaveis = append(variaveis, formula.Avaliacao{Nome: a.Nome, Tipo: a.Tipo, Nota: findNota(notas, a.Id)})
+		}
+
+		var err error
+		if media, err = formulaMedia.Avalia(variaveis); err != nil {
+			return 0, utils.NewRestErr(http.StatusBadRequest, "Erro ao calcular a média pela fórmula da disciplina", err, err.Error())
+		}
+	} else {
+		var soma float64
+		var pesoTotal float64
+		for _, avaliacao := range avaliacoes {
+			soma += findNota(notas, avaliacao.Id) * avaliacao.Peso
+			pesoTotal += avaliacao.Peso
+		}
+
+		if pesoTotal == 0 {
+			return 0, utils.NewRestErr(http.StatusBadRequest, "Peso total das avaliações é zero", nil)
+		}
+		media = soma / pesoTotal
+	}
+
+	// A comparação negada também recusa NaN
+	if !(media >= -toleranciaMedia && media <= 10+toleranciaMedia) {
+		return 0, utils.NewRestErr(http.StatusBadRequest, "A média calculada está fora da faixa de 0 a 10", nil,
+			fmt.Sprintf("média calculada: %g", media))
+	}
+	return math.Min(math.Max(media, 0), 10), nil
+}
+
+// compilaFormulaMedia verifica a sintaxe de uma fórmula de média
+//
+// Retorna a fórmula compilada ou erro 400 com a descrição do problema encontrado
+func compilaFormulaMedia(texto string) (*formula.Formula, *utils.RestErr) {
+	f, err := formula.Compila(texto)
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Fórmula de média inválida", err, err.Error())
+	}
+	return f, nil
+}
+
+// validaFormulaMedia verifica a sintaxe de uma fórmula de média e se as avaliações referenciadas por ela estão na
+// lista informada, desconsiderando a avaliação de recuperação
+//
+// A fórmula também é recusada se, com notas de 0 a 10, puder resultar em uma média fora dessa faixa. Uma fórmula
+// vazia é sempre válida, pois indica o uso da média ponderada pelos pesos
+func validaFormulaMedia(texto string, avaliacoes []models.Avaliacao) *utils.RestErr {
+	if texto == "" {
+		return nil
+	}
+
+	f, restErr := compilaFormulaMedia(texto)
+	if restErr != nil {
+		return restErr
+	}
+
+	variaveis := make([]formula.Avaliacao, 0, len(avaliacoes))
+	for _, a := range avaliacoes {
+		if !a.Recuperacao() {
+			variaveis = append(variaveis, formula.Avaliacao{Nome: a.Nome, Tipo: a.Tipo})
+		}
+	}
+
+	if err := f.Valida(variaveis); err != nil {
+		return utils.NewRestErr(http.StatusBadRequest, "Fórmula de média inválida", err, err.Error())
+	}
+
+	menor, maior, err := f.Faixa(variaveis, 0, 10)
+	if err != nil {
+		return utils.NewRestErr(http.StatusBadRequest, "Fórmula de média inválida", err, err.Error())
+	}
+	if !(menor >= -toleranciaMedia && maior <= 10+toleranciaMedia) {
+		return utils.NewRestErr(http.StatusBadRequest, "A fórmula de média pode resultar em valores fora da faixa de 0 a 10", nil,
+			fmt.Sprintf("a média pode variar de %g a %g", menor, maior))
+	}
+	return nil
+}
+
+// buscaAvaliacoesDisciplina retorna todas as avaliações cadastradas em uma disciplina
+func buscaAvaliacoesDisciplina(disciplinaId string) ([]models.Avaliacao, *utils.RestErr) {
+	var avaliacoes []models.Avaliacao
+	if err := database.DB.Where("disciplina_id = ?", disciplinaId).Find(&avaliacoes).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliações da disciplina", err)
+	}
+	return avaliacoes, nil
+}
+
+// validaFormulaSemAvaliacao verifica se a fórmula de média da disciplina continua válida quando a avaliação informada
+// é alterada ou, se `alterada` for nula, removida
+//
+// Com `avaliacaoId` vazio, `alterada` é uma avaliação nova, incluída nas avaliações existentes
+func validaFormulaSemAvaliacao(disciplina *models.Disciplina, avaliacaoId string, alterada *models.Avaliacao) *utils.RestErr {
+	if disciplina.FormulaMedia == "" {
+		return nil
+	}
+
+	avaliacoes, restErr := buscaAvaliacoesDisciplina(disciplina.Id)
+	if restErr != nil {
+		return restErr
+	}
+
+	restantes := make([]models.Avaliacao, 0, len(avaliacoes))
+	for _, a := range avaliacoes {
+		switch {
+		case a.Id != avaliacaoId:
+			restantes = append(restantes, a)
+		case alterada != nil:
+			restantes = append(restantes, *alterada)
+		}
+	}
+	if avaliacaoId == "" && alterada != nil {
+		restantes = append(restantes, *alterada)
+	}
+
+	if restErr := validaFormulaMedia(disciplina.FormulaMedia, restantes); restErr != nil {
+		restErr.Msg = "A alteração invalidaria a fórmula de média da disciplina"
+		return restErr
+	}
+	return nil
+}
+
+// verificaPesos calcula a soma dos pesos das avaliações e o peso efetivo de cada uma
+func verificaPesos(disciplina *models.Disciplina, avaliacoes []models.Avaliacao) *models.ConsistenciaPesos {
+	var soma float64
+	for _, a := range avaliacoes {
+		soma += a.Peso
+	}
+
+	consistencia := &models.ConsistenciaPesos{
+		DisciplinaId:    disciplina.Id,
+		SomaPesos:       soma,
+		Consistente:     math.Abs(soma-1) <= toleranciaPesos,
+		NormalizarPesos: disciplina.NormalizarPesos,
+		Avaliacoes:      []models.PesoAvaliacao{},
+	}
+	consistencia.PodeFechar = consistencia.Consistente || (disciplina.NormalizarPesos && soma > 0)
+
+	for _, a := range avaliacoes {
+		efetivo := a.Peso
+		if soma > 0 && !consistencia.Consistente && disciplina.NormalizarPesos {
+			efetivo = a.Peso / soma
+		}
+		consistencia.Avaliacoes = append(consistencia.Avaliacoes, models.PesoAvaliacao{
+			Id:          a.Id,
+			Nome:        a.Nome,
+			Tipo:        a.Tipo,
+			Peso:        a.Peso,
+			PesoEfetivo: efetivo,
+		})
+	}
+
+	return consistencia
+}
+
+// buscaAvaliacaoRecuperacao retorna a avaliação de recuperação da disciplina ou nil, se ela não tiver uma
+func buscaAvaliacaoRecuperacao(disciplinaId string) (*models.Avaliacao, *utils.RestErr) {
+	var avaliacao models.Avaliacao
+	err := database.DB.Where("disciplina_id = ? AND tipo = ?", disciplinaId, models.AvaliacaoRecuperacao).Limit(1).Find(&avaliacao).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliação de recuperação", err)
+	}
+
+	if avaliacao.Id == "" {
+		return nil, nil
+	}
+	return &avaliacao, nil
+}
+
+// alunosEmRecuperacao retorna os IDs dos alunos que ficaram em recuperação no fechamento do semestre da disciplina
+func alunosEmRecuperacao(disciplinaId string) (map[string]bool, *utils.RestErr) {
+	var alunoIds []string
+	err := database.DB.Model(&models.AlunoMedia{}).
+		Where("disciplina_id = ? AND em_recuperacao = true", disciplinaId).
+		Pluck("aluno_id", &alunoIds).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos em recuperação", err)
+	}
+
+	alunos := make(map[string]bool, len(alunoIds))
+	for _, id := range alunoIds {
+		alunos[id] = true
+	}
+	return alunos, nil
+}
+
+// verificaAvaliacaoAlteravel verifica se a situação da disciplina permite cadastrar, alterar ou remover avaliações do
+// tipo informado
+//
+// Avaliações regulares só podem ser alteradas com o semestre aberto; a avaliação de recuperação também pode ser
+// alterada durante a recuperação
+func verificaAvaliacaoAlteravel(disciplina *models.Disciplina, tipo string) *utils.RestErr {
+	if disciplina.Aberta() || (tipo == models.AvaliacaoRecuperacao && disciplina.EmRecuperacao()) {
+		return nil
+	}
+	return utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+}
+
+// verificaNotaAlteravel verifica se a situação da disciplina permite lançar ou corrigir notas na avaliação informada
+//
+// Notas de avaliações regulares só podem ser alteradas com o semestre aberto e notas de recuperação, apenas durante
+// a recuperação
+func verificaNotaAlteravel(disciplina *models.Disciplina, avaliacao *models.Avaliacao) *utils.RestErr {
+	if avaliacao.Recuperacao() {
+		if !disciplina.EmRecuperacao() {
+			return utils.NewRestErr(http.StatusBadRequest, "As notas de recuperação só podem ser lançadas durante a recuperação", nil)
+		}
+		return nil
+	}
+
+	if !disciplina.Aberta() {
+		return utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+	return nil
+}
+
+// buscaAvaliacaoDisciplina busca uma avaliação pelo ID garantindo que ela pertence à disciplina informada
+//
+// Retorna erro 404 caso a avaliação não exista ou seja de outra disciplina, ou erro interno se a consulta falhar
+func buscaAvaliacaoDisciplina(disciplinaId string, avaliacaoId string) (*models.Avaliacao, *utils.RestErr) {
+	var avaliacao models.Avaliacao
+	err := database.DB.Where("id = ? AND disciplina_id = ?", avaliacaoId, disciplinaId).First(&avaliacao).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "Avaliação não encontrada na disciplina", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliação", err)
+	}
+
+	return &avaliacao, nil
+}
+
+// recalculaQuantidadeAvaliacoes atualiza os contadores de provas e trabalhos de uma disciplina a partir das
+// avaliações cadastradas
+//
+// Deve ser chamada dentro da mesma transação que alterou as avaliações
+func recalculaQuantidadeAvaliacoes(tx *gorm.DB, disciplinaId string) *utils.RestErr {
+	var provas, trabalhos int64
+	if err := tx.Model(&models.Avaliacao{}).Where("disciplina_id = ? AND tipo = ?", disciplinaId, models.AvaliacaoProva).Count(&provas).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao contar provas da disciplina", err)
+	}
+	if err := tx.Model(&models.Avaliacao{}).Where("disciplina_id = ? AND tipo = ?", disciplinaId, models.AvaliacaoTrabalho).Count(&trabalhos).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao contar trabalhos da disciplina", err)
+	}
+
+	err := tx.Model(&models.Disciplina{}).Where("id = ?", disciplinaId).Updates(map[string]interface{}{
+		"quantidade_provas":    provas,
+		"quantidade_trabalhos": trabalhos,
+	}).Error
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar disciplina", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,573 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"io"
+	"net/http"
+	"reflect"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"time"
+)
+
+// Limites usados na leitura e na gravação dos backups
+const (
+	tamanhoMaximoLinhaBackup = 16 << 20
+	tamanhoLoteBackup        = 500
+	tamanhoConsultaBackup    = 1000
+)
+
+// tabelaBackup descreve uma tabela incluída no backup
+//
+// `referencias` associa cada coluna de chave estrangeira à tabela referenciada, e `senha` indica se a tabela guarda
+// hashes de senhas, exportados apenas quando pedido
+type tabelaBackup struct {
+	nome        string
+	modelo      interface{}
+	referencias map[string]string
+	senha       bool
+}
+
+// tabelasBackup são as tabelas incluídas no backup, em ordem de dependência: cada tabela só referencia as anteriores
+var tabelasBackup = []tabelaBackup{
+	{nome: "professores", modelo: &models.Professor{}, senha: true},
+	{nome: "alunos", modelo: &models.Aluno{}, senha: true},
+	{nome: "disciplinas", modelo: &models.Disciplina{}, referencias: map[string]string{"professor_id": "professores"}},
+	{nome: "aulas", modelo: &models.Aula{}, referencias: map[string]string{"disciplina_id": "disciplinas"}},
+	{nome: "avaliacoes", modelo: &models.Avaliacao{}, referencias: map[string]string{"disciplina_id": "disciplinas"}},
+	{nome: "aluno_disciplina", modelo: &models.AlunoDisciplina{}, referencias: map[string]string{
+		"aluno_id":      "alunos",
+		"disciplina_id": "disciplinas",
+	}},
+	{nome: "aluno_aula", modelo: &models.AlunoAula{}, referencias: map[string]string{
+		"aluno_id": "alunos",
+		"aula_id":  "aulas",
+	}},
+	{nome: "aluno_avaliacao", modelo: &models.AlunoAvaliacao{}, referencias: map[string]string{
+		"aluno_id":      "alunos",
+		"avaliacao_id":  "avaliacoes",
+		"disciplina_id": "disciplinas",
+	}},
+	{nome: "aluno_media", modelo: &models.AlunoMedia{}, referencias: map[string]string{
+		"aluno_id":      "alunos",
+		"disciplina_id": "disciplinas",
+	}},
+}
+
+// backupLido representa um arquivo de backup já lido e validado, com os registros de cada tabela convertidos para os
+// tipos das colunas
+type backupLido struct {
+	cabecalho models.CabecalhoBackup
+	registros map[string][]map[string]interface{}
+}
+
+// ExportarBackup grava em `destino` um backup de professores, alunos, disciplinas, aulas, avaliações, matrículas,
+// presenças, notas e médias
+//
+// O arquivo é NDJSON: a primeira linha é o cabeçalho com o formato e a versão, cada linha seguinte traz um registro de
+// uma tabela, com os valores de todas as colunas, e a última traz a quantidade de registros de cada tabela. As tabelas
+// são lidas em uma única transação somente leitura, para que o backup seja consistente mesmo com o sistema em uso. Os
+// hashes das senhas de professores e alunos só são incluídos com `senhas`
+//
+// Retorna erro caso a leitura do banco de dados ou a gravação do arquivo falhe
+func ExportarBackup(destino io.Writer, senhas bool) *utils.RestErr {
+	escritor := bufio.NewWriter(destino)
+	codificador := json.NewEncoder(escritor)
+
+	cabecalho := models.CabecalhoBackup{
+		Formato:  models.FormatoBackup,
+		Versao:   models.VersaoBackup,
+		GeradoEm: time.Now().UTC(),
+		Senhas:   senhas,
+	}
+	if err := codificador.Encode(cabecalho); err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao gravar backup", err)
+	}
+
+	totais := make(map[string]int, len(tabelasBackup))
+	var restErr *utils.RestErr
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, tabela := range tabelasBackup {
+			if restErr = exportaTabela(tx, tabela, senhas, codificador, totais); restErr != nil {
+				return restErr.Err
+			}
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if restErr != nil {
+		return restErr
+	}
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao exportar backup", err)
+	}
+
+	if err := codificador.Encode(models.RegistroBackup{Totais: totais}); err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao gravar backup", err)
+	}
+	if err := escritor.Flush(); err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao gravar backup", err)
+	}
+
+	return nil
+}
+
+// ImportarBackup importa um backup gerado por ExportarBackup, compactado ou não com gzip, preservando os IDs dos
+// registros
+//
+// Antes de gravar, o arquivo inteiro é validado: o formato e a versão, os valores de cada coluna, a quantidade de
+// registros de cada tabela e a integridade referencial, exigindo que toda chave estrangeira aponte para um registro do
+// backup ou já cadastrado. Registros com IDs já cadastrados e professores com e-mail já usado por outro professor
+// impedem a importação. Os registros são gravados em uma única transação; professores e alunos importados de um backup
+// sem senhas ficam sem senha definida
+//
+// Retorna a quantidade de registros importados de cada tabela ou erro com a lista de problemas encontrados
+func ImportarBackup(origem io.Reader) (*models.ResultadoImportacaoBackup, *utils.RestErr) {
+	backup, restErr := leBackup(origem)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	problemas, restErr := verificaIntegridadeBackup(backup)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if len(problemas) > 0 {
+		return nil, utils.NewRestErr(http.StatusConflict, "O backup não pode ser importado sobre os dados atuais", nil, problemas)
+	}
+
+	resultado := &models.ResultadoImportacaoBackup{
+		Versao:    backup.cabecalho.Versao,
+		GeradoEm:  backup.cabecalho.GeradoEm,
+		Senhas:    backup.cabecalho.Senhas,
+		Registros: make(map[string]int, len(tabelasBackup)),
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Os hooks dos modelos gerariam novos IDs; os registros são gravados com os IDs do backup
+		tx = tx.Session(&gorm.Session{SkipHooks: true})
+		for _, tabela := range tabelasBackup {
+			registros := backup.registros[tabela.nome]
+			for inicio := 0; inicio < len(registros); inicio += tamanhoLoteBackup {
+				lote := registros[inicio:min(inicio+tamanhoLoteBackup, len(registros))]
+				if err := tx.Model(tabela.modelo).Create(lote).Error; err != nil {
+					restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao importar registros de "+tabela.nome, err)
+					return err
+				}
+			}
+			resultado.Registros[tabela.nome] = len(registros)
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao importar backup", err)
+	}
+
+	return resultado, nil
+}
+
+// exportaTabela grava uma linha no backup para cada registro de uma tabela, em ordem de ID, e soma o total de
+// registros em `totais`
+func exportaTabela(tx *gorm.DB, tabela tabelaBackup, senhas bool, codificador *json.Encoder, totais map[string]int) *utils.RestErr {
+	linhas, err := tx.Model(tabela.modelo).Order("id").Rows()
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao ler "+tabela.nome, err)
+	}
+	defer linhas.Close()
+
+	for linhas.Next() {
+		valores := make(map[string]interface{})
+		if err := tx.ScanRows(linhas, &valores); err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao ler "+tabela.nome, err)
+		}
+		if tabela.senha && !senhas {
+			delete(valores, "senha")
+		}
+
+		registro := models.RegistroBackup{Tabela: tabela.nome, Dados: make(map[string]json.RawMessage, len(valores))}
+		for coluna, valor := range valores {
+			bruto, err := json.Marshal(valor)
+			if err != nil {
+				return utils.NewRestErr(http.StatusInternalServerError, "Erro ao converter "+tabela.nome+"."+coluna, err)
+			}
+			registro.Dados[coluna] = bruto
+		}
+
+		if err := codificador.Encode(registro); err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao gravar backup", err)
+		}
+		totais[tabela.nome]++
+	}
+
+	if err := linhas.Err(); err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao ler "+tabela.nome, err)
+	}
+	return nil
+}
+
+// leBackup lê e valida um arquivo de backup, convertendo os valores de cada registro para os tipos das colunas
+//
+// Retorna erro 400 com a lista de problemas se o arquivo não for um backup do sistema, for de uma versão não suportada,
+// estiver incompleto ou tiver registros inválidos
+func leBackup(origem io.Reader) (*backupLido, *utils.RestErr) {
+	leitor := bufio.NewReader(origem)
+	if inicio, _ := leitor.Peek(2); bytes.Equal(inicio, []byte{0x1f, 0x8b}) {
+		descompactado, err := gzip.NewReader(leitor)
+		if err != nil {
+			return nil, utils.NewRestErr(http.StatusBadRequest, "O arquivo de backup compactado é inválido", err)
+		}
+		defer descompactado.Close()
+		leitor = bufio.NewReader(descompactado)
+	}
+
+	linhas := bufio.NewScanner(leitor)
+	linhas.Buffer(make([]byte, 64<<10), tamanhoMaximoLinhaBackup)
+
+	if !linhas.Scan() {
+		if err := linhas.Err(); err != nil {
+			return nil, utils.NewRestErr(http.StatusBadRequest, "Erro ao ler backup", err)
+		}
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O arquivo de backup está vazio", nil)
+	}
+
+	backup := &backupLido{registros: make(map[string][]map[string]interface{}, len(tabelasBackup))}
+	if err := json.Unmarshal(linhas.Bytes(), &backup.cabecalho); err != nil || backup.cabecalho.Formato != models.FormatoBackup {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O arquivo não é um backup do sistema", err)
+	}
+	if backup.cabecalho.Versao < 1 || backup.cabecalho.Versao > models.VersaoBackup {
+		mensagem := fmt.Sprintf("A versão %d do backup não é suportada; a versão atual é %d", backup.cabecalho.Versao, models.VersaoBackup)
+		return nil, utils.NewRestErr(http.StatusBadRequest, mensagem, nil)
+	}
+
+	tabelas := make(map[string]tabelaBackup, len(tabelasBackup))
+	esquemas := make(map[string]*schema.Schema, len(tabelasBackup))
+	for _, tabela := range tabelasBackup {
+		declaracao := &gorm.Statement{DB: database.DB}
+		if err := declaracao.Parse(tabela.modelo); err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao ler estrutura de "+tabela.nome, err)
+		}
+		tabelas[tabela.nome] = tabela
+		esquemas[tabela.nome] = declaracao.Schema
+	}
+
+	var problemas []models.ProblemaBackup
+	var totais map[string]int
+	ids := make(map[string]map[string]int)
+	numero := 1
+	for linhas.Scan() {
+		numero++
+		if len(bytes.TrimSpace(linhas.Bytes())) == 0 {
+			continue
+		}
+
+		if totais != nil {
+			problemas = append(problemas, models.ProblemaBackup{Linha: numero, Motivo: "registro após o fim do backup"})
+			continue
+		}
+
+		var registro models.RegistroBackup
+		if err := json.Unmarshal(linhas.Bytes(), &registro); err != nil {
+			problemas = append(problemas, models.ProblemaBackup{Linha: numero, Motivo: "linha inválida: " + err.Error()})
+			continue
+		}
+
+		if registro.Tabela == "" && registro.Totais != nil {
+			totais = registro.Totais
+			continue
+		}
+
+		tabela, ok := tabelas[registro.Tabela]
+		if !ok {
+			problemas = append(problemas, models.ProblemaBackup{Linha: numero, Tabela: registro.Tabela, Motivo: "tabela desconhecida"})
+			continue
+		}
+
+		valores, problemasRegistro := converteRegistroBackup(esquemas[tabela.nome], registro.Dados)
+		id, _ := valores["id"].(string)
+		if id == "" {
+			problemasRegistro = append(problemasRegistro, models.ProblemaBackup{Coluna: "id", Motivo: "ID obrigatório"})
+		} else if anterior, repetido := ids[tabela.nome][id]; repetido {
+			problemasRegistro = append(problemasRegistro, models.ProblemaBackup{Coluna: "id", Motivo: fmt.Sprintf("ID repetido da linha %d", anterior)})
+		}
+		if tabela.senha {
+			if _, ok := valores["senha"]; !ok {
+				valores["senha"] = ""
+			}
+		}
+
+		if len(problemasRegistro) > 0 {
+			for _, problema := range problemasRegistro {
+				problema.Linha, problema.Tabela, problema.Id = numero, tabela.nome, id
+				problemas = append(problemas, problema)
+			}
+			continue
+		}
+
+		if ids[tabela.nome] == nil {
+			ids[tabela.nome] = make(map[string]int)
+		}
+		ids[tabela.nome][id] = numero
+		backup.registros[tabela.nome] = append(backup.registros[tabela.nome], valores)
+	}
+	if err := linhas.Err(); err != nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Erro ao ler backup", err)
+	}
+
+	if totais == nil {
+		problemas = append(problemas, models.ProblemaBackup{Motivo: "o arquivo está incompleto: a linha final com os totais não foi encontrada"})
+	} else if len(problemas) == 0 {
+		for _, tabela := range tabelasBackup {
+			if lidos := len(backup.registros[tabela.nome]); lidos != totais[tabela.nome] {
+				problemas = append(problemas, models.ProblemaBackup{
+					Tabela: tabela.nome,
+					Motivo: fmt.Sprintf("o backup declara %d registros, mas %d foram lidos", totais[tabela.nome], lidos),
+				})
+			}
+		}
+	}
+
+	if len(problemas) > 0 {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O arquivo de backup é inválido", nil, problemas)
+	}
+
+	return backup, nil
+}
+
+// converteRegistroBackup converte os valores de um registro do backup para os tipos das colunas do modelo
+//
+// Retorna os valores convertidos, indexados pelo nome da coluna, e um problema para cada coluna desconhecida ou com
+// valor de tipo incompatível
+func converteRegistroBackup(esquema *schema.Schema, dados map[string]json.RawMessage) (map[string]interface{}, []models.ProblemaBackup) {
+	valores := make(map[string]interface{}, len(dados))
+	var problemas []models.ProblemaBackup
+
+	for coluna, bruto := range dados {
+		campo, ok := esquema.FieldsByDBName[coluna]
+		if !ok {
+			problemas = append(problemas, models.ProblemaBackup{Coluna: coluna, Motivo: "coluna desconhecida"})
+			continue
+		}
+
+		valor := reflect.New(campo.FieldType)
+		if err := json.Unmarshal(bruto, valor.Interface()); err != nil {
+			problemas = append(problemas, models.ProblemaBackup{Coluna: coluna, Motivo: "valor inválido para a coluna"})
+			continue
+		}
+		valores[coluna] = valor.Elem().Interface()
+	}
+
+	return valores, problemas
+}
+
+// verificaIntegridadeBackup confere se os registros de um backup podem ser gravados sobre os dados atuais
+//
+// Verifica se os IDs já estão cadastrados, se as chaves estrangeiras apontam para registros do backup ou do banco de
+// dados e se os e-mails dos professores já são usados por outros professores. Retorna os problemas encontrados ou
+// erro caso as consultas falhem
+func verificaIntegridadeBackup(backup *backupLido) ([]models.ProblemaBackup, *utils.RestErr) {
+	var problemas []models.ProblemaBackup
+
+	ids := make(map[string]map[string]bool, len(tabelasBackup))
+	modelos := make(map[string]interface{}, len(tabelasBackup))
+	for _, tabela := range tabelasBackup {
+		modelos[tabela.nome] = tabela.modelo
+		ids[tabela.nome] = make(map[string]bool, len(backup.registros[tabela.nome]))
+		for _, registro := range backup.registros[tabela.nome] {
+			ids[tabela.nome][registro["id"].(string)] = true
+		}
+	}
+
+	for _, tabela := range tabelasBackup {
+		cadastrados, restErr := idsCadastrados(database.DB, tabela.modelo, "id", chaves(ids[tabela.nome]))
+		if restErr != nil {
+			return nil, restErr
+		}
+		for _, registro := range backup.registros[tabela.nome] {
+			if id := registro["id"].(string); cadastrados[id] {
+				problemas = append(problemas, models.ProblemaBackup{Tabela: tabela.nome, Id: id, Coluna: "id", Motivo: "ID já cadastrado"})
+			}
+		}
+
+		for coluna, referenciada := range tabela.referencias {
+			ausentes := make(map[string]bool)
+			for _, registro := range backup.registros[tabela.nome] {
+				if valor, _ := registro[coluna].(string); !ids[referenciada][valor] {
+					ausentes[valor] = true
+				}
+			}
+			if len(ausentes) == 0 {
+				continue
+			}
+
+			existentes, restErr := idsCadastrados(database.DB, modelos[referenciada], "id", chaves(ausentes))
+			if restErr != nil {
+				return nil, restErr
+			}
+			for _, registro := range backup.registros[tabela.nome] {
+				if valor, _ := registro[coluna].(string); ausentes[valor] && !existentes[valor] {
+					problemas = append(problemas, models.ProblemaBackup{
+						Tabela: tabela.nome,
+						Id:     registro["id"].(string),
+						Coluna: coluna,
+						Motivo: "referência a registro inexistente em " + referenciada,
+					})
+				}
+			}
+		}
+	}
+
+	professorPorEmail := make(map[string]string)
+	for _, registro := range backup.registros["professores"] {
+		email, id := registro["email"].(string), registro["id"].(string)
+		if anterior, repetido := professorPorEmail[email]; repetido {
+			problemas = append(problemas, models.ProblemaBackup{
+				Tabela: "professores", Id: id, Coluna: "email", Motivo: "e-mail repetido do professor " + anterior,
+			})
+			continue
+		}
+		professorPorEmail[email] = id
+	}
+	if len(professorPorEmail) > 0 {
+		var cadastrados []models.Professor
+		if err := database.DB.Select("id", "email").Where("email IN ?", chaves(professorPorEmail)).Find(&cadastrados).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar professores cadastrados", err)
+		}
+		for _, professor := range cadastrados {
+			if id := professorPorEmail[professor.Email]; id != professor.Id {
+				problemas = append(problemas, models.ProblemaBackup{
+					Tabela: "professores", Id: id, Coluna: "email", Motivo: "e-mail já cadastrado para outro professor",
+				})
+			}
+		}
+	}
+
+	problemasNotas, restErr := verificaNotasBackup(backup)
+	if restErr != nil {
+		return nil, restErr
+	}
+	problemas = append(problemas, problemasNotas...)
+
+	return problemas, nil
+}
+
+// verificaNotasBackup confere se as notas de um backup respeitam as restrições de aluno_avaliacao
+//
+// Aponta as notas repetidas de um aluno em uma avaliação, no backup ou em relação às notas cadastradas, e as notas cuja
+// disciplina difere da disciplina da avaliação, buscada no backup ou no banco de dados
+func verificaNotasBackup(backup *backupLido) ([]models.ProblemaBackup, *utils.RestErr) {
+	var problemas []models.ProblemaBackup
+	notas := backup.registros["aluno_avaliacao"]
+	if len(notas) == 0 {
+		return nil, nil
+	}
+
+	disciplinaAvaliacao := make(map[string]string, len(backup.registros["avaliacoes"]))
+	for _, registro := range backup.registros["avaliacoes"] {
+		disciplinaAvaliacao[registro["id"].(string)], _ = registro["disciplina_id"].(string)
+	}
+	avaliacoesNotas := make(map[string]bool)
+	for _, registro := range notas {
+		if avaliacaoId, _ := registro["avaliacao_id"].(string); avaliacaoId != "" {
+			avaliacoesNotas[avaliacaoId] = true
+		}
+	}
+
+	var ausentes []string
+	for avaliacaoId := range avaliacoesNotas {
+		if _, ok := disciplinaAvaliacao[avaliacaoId]; !ok {
+			ausentes = append(ausentes, avaliacaoId)
+		}
+	}
+	for _, lote := range lotes(ausentes, tamanhoConsultaBackup) {
+		var avaliacoes []models.Avaliacao
+		if err := database.DB.Select("id", "disciplina_id").Where("id IN ?", lote).Find(&avaliacoes).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliações cadastradas", err)
+		}
+		for _, avaliacao := range avaliacoes {
+			disciplinaAvaliacao[avaliacao.Id] = avaliacao.DisciplinaId
+		}
+	}
+
+	notaPorPar := make(map[[2]string]string, len(notas))
+	for _, registro := range notas {
+		id := registro["id"].(string)
+		alunoId, _ := registro["aluno_id"].(string)
+		avaliacaoId, _ := registro["avaliacao_id"].(string)
+		disciplinaId, _ := registro["disciplina_id"].(string)
+
+		if esperada, ok := disciplinaAvaliacao[avaliacaoId]; ok && esperada != disciplinaId {
+			problemas = append(problemas, models.ProblemaBackup{
+				Tabela: "aluno_avaliacao", Id: id, Coluna: "disciplina_id", Motivo: "disciplina diferente da disciplina da avaliação",
+			})
+		}
+
+		par := [2]string{alunoId, avaliacaoId}
+		if anterior, repetido := notaPorPar[par]; repetido {
+			problemas = append(problemas, models.ProblemaBackup{
+				Tabela: "aluno_avaliacao", Id: id, Coluna: "avaliacao_id", Motivo: "nota repetida do aluno na avaliação, já informada em " + anterior,
+			})
+			continue
+		}
+		notaPorPar[par] = id
+	}
+
+	for _, lote := range lotes(chaves(avaliacoesNotas), tamanhoConsultaBackup) {
+		var cadastradas []models.AlunoAvaliacao
+		if err := database.DB.Select("id", "aluno_id", "avaliacao_id").Where("avaliacao_id IN ?", lote).Find(&cadastradas).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar notas cadastradas", err)
+		}
+		for _, cadastrada := range cadastradas {
+			if id, ok := notaPorPar[[2]string{cadastrada.AlunoId, cadastrada.AvaliacaoId}]; ok && id != cadastrada.Id {
+				problemas = append(problemas, models.ProblemaBackup{
+					Tabela: "aluno_avaliacao", Id: id, Coluna: "avaliacao_id", Motivo: "nota do aluno na avaliação já cadastrada",
+				})
+			}
+		}
+	}
+
+	return problemas, nil
+}
+
+// idsCadastrados retorna quais dos valores informados já existem na coluna do modelo, consultando em lotes na conexão
+// ou transação informada
+func idsCadastrados(tx *gorm.DB, modelo interface{}, coluna string, valores []string) (map[string]bool, *utils.RestErr) {
+	cadastrados := make(map[string]bool)
+	for _, lote := range lotes(valores, tamanhoConsultaBackup) {
+		var encontrados []string
+		if err := tx.Model(modelo).Where(coluna+" IN ?", lote).Pluck(coluna, &encontrados).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao verificar registros cadastrados", err)
+		}
+		for _, valor := range encontrados {
+			cadastrados[valor] = true
+		}
+	}
+	return cadastrados, nil
+}
+
+// lotes divide uma lista de valores em lotes com no máximo `tamanho` valores
+func lotes(valores []string, tamanho int) [][]string {
+	var divididos [][]string
+	for inicio := 0; inicio < len(valores); inicio += tamanho {
+		divididos = append(divididos, valores[inicio:min(inicio+tamanho, len(valores))])
+	}
+	return divididos
+}
+
+// chaves retorna as chaves de um mapa indexado por strings
+func chaves[V any](mapa map[string]V) []string {
+	lista := make([]string, 0, len(mapa))
+	for chave := range mapa {
+		lista = append(lista, chave)
+	}
+	return lista
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"net/http"
+	"regexp"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"time"
+)
+
+// formatoPeriodo é o formato aceito para o período letivo do boletim, o mesmo do ano-semestre das disciplinas
+var formatoPeriodo = regexp.MustCompile(`^\d{4}-0[12]$`)
+
+// MontaBoletim reúne os dados do boletim de um aluno em um período letivo
+//
+// Inclui todas as disciplinas do período em que o aluno está matriculado, ordenadas pelo nome, com as avaliações em
+// ordem de data e as notas na escala de cada disciplina. Disciplinas ainda abertas aparecem com a frequência parcial e
+// sem média final
+//
+// Retorna o boletim ou erro caso o aluno não exista, o período seja inválido ou a consulta falhe
+func MontaBoletim(alunoId string, periodo string) (*models.Boletim, *utils.RestErr) {
+	if !formatoPeriodo.MatchString(periodo) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O período deve estar no formato yyyy-01 ou yyyy-02", nil)
+	}
+
+	aluno, restErr := buscaAluno(alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	var disciplinas []models.Disciplina
+	err := database.DB.Preload("Professor").
+		Joins("JOIN aluno_disciplina ON aluno_disciplina.disciplina_id = disciplinas.id").
+		Where("aluno_disciplina.aluno_id = ? AND disciplinas.ano_semestre = ?", alunoId, periodo).
+		Order("disciplinas.nome").
+		Find(&disciplinas).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar disciplinas do aluno", err)
+	}
+
+	if len(disciplinas) == 0 {
+		return nil, utils.NewRestErr(http.StatusNotFound, "O aluno não cursou disciplinas no período", nil)
+	}
+
+	boletim := &models.Boletim{
+		AlunoId:     aluno.Id,
+		AlunoNome:   aluno.Nome,
+		AlunoEmail:  aluno.Email,
+		Periodo:     periodo,
+		EmitidoEm:   time.Now(),
+		Disciplinas: []models.BoletimDisciplina{},
+	}
+
+	for i := range disciplinas {
+		item, restErr := boletimDisciplina(&disciplinas[i], alunoId)
+		if restErr != nil {
+			return nil, restErr
+		}
+		boletim.Disciplinas = append(boletim.Disciplinas, *item)
+	}
+
+	return boletim, nil
+}
+
+// boletimDisciplina reúne as avaliações, notas e o resultado de um aluno em uma disciplina
+//
+// Se o aluno já tiver resultado registrado, usa a média, a frequência e a situação do resultado; caso contrário,
+// calcula a frequência parcial sobre as aulas registradas
+func boletimDisciplina(disciplina *models.Disciplina, alunoId string) (*models.BoletimDisciplina, *utils.RestErr) {
+	item := &models.BoletimDisciplina{
+		DisciplinaId:     disciplina.Id,
+		Nome:             disciplina.Nome,
+		NotaMinima:       disciplina.NotaMinima,
+		FrequenciaMinima: disciplina.FrequenciaMinima,
+		Avaliacoes:       []models.BoletimAvaliacao{},
+	}
+	if disciplina.Professor != nil {
+		item.Professor = disciplina.Professor.Nome
+	}
+
+	var avaliacoes []models.Avaliacao
+	if err := database.DB.Where("disciplina_id = ?", disciplina.Id).Order("data_avaliacao, nome").Find(&avaliacoes).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliações da disciplina", err)
+	}
+
+	var notas []models.AlunoAvaliacao
+	if err := database.DB.Where("disciplina_id = ? AND aluno_id = ?", disciplina.Id, alunoId).Find(&notas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar notas do aluno", err)
+	}
+
+	porAvaliacao := make(map[string]models.AlunoAvaliacao, len(notas))
+	for _, n := range notas {
+		porAvaliacao[n.AvaliacaoId] = n
+	}
+
+	for _, avaliacao := range avaliacoes {
+		linha := models.BoletimAvaliacao{
+			Nome: avaliacao.Nome,
+			Tipo: avaliacao.Tipo,
+			Data: avaliacao.DataAvaliacao,
+			Peso: avaliacao.Peso,
+		}
+		if nota, ok := porAvaliacao[avaliacao.Id]; ok {
+			linha.Nota = notaExibida(disciplina, &nota)
+		}
+		item.Avaliacoes = append(item.Avaliacoes, linha)
+	}
+
+	var resultado models.AlunoMedia
+	err := database.DB.Where("disciplina_id = ? AND aluno_id = ?", disciplina.Id, alunoId).Limit(1).Find(&resultado).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar resultado do aluno", err)
+	}
+
+	if resultado.Id != "" {
+		media := resultado.MediaFinal
+		item.MediaFinal = &media
+		item.MediaConvertida = resultado.MediaConvertida
+		if item.MediaConvertida == "" {
+			item.MediaConvertida = converteMedia(disciplina.Escala, media)
+		}
+		item.Frequencia = resultado.Frequencia
+		item.Situacao = resultado.Situacao
+		return item, nil
+	}
+
+	var aulas []models.Aula
+	if err := database.DB.Where("disciplina_id = ?", disciplina.Id).Find(&aulas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar aulas da disciplina", err)
+	}
+
+	frequencia, restErr := calculaFrequencia(disciplina, aulas, alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	item.Frequencia = frequencia
+
+	return item, nil
+}
+
+// notaExibida retorna a nota de um aluno na escala da disciplina, como exibida nos documentos emitidos
+//
+// Usa a nota na escala registrada no lançamento e, se ela não existir, converte a nota interna
+func notaExibida(disciplina *models.Disciplina, nota *models.AlunoAvaliacao) string {
+	if nota.NotaEscala != "" {
+		return nota.NotaEscala
+	}
+	return converteMedia(disciplina.Escala, nota.Nota)
+}
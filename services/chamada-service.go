@@ -0,0 +1,373 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"time"
+)
+
+// intervaloPadraoChamada é o intervalo, em segundos, de troca do código quando não informado na abertura
+const intervaloPadraoChamada = 30
+
+// maximoFalhasCheckIn é a quantidade de códigos inválidos que um aluno pode informar em uma sessão de chamada antes de
+// ter o check-in bloqueado
+const maximoFalhasCheckIn = 5
+
+// AbrirChamada abre uma sessão de check-in para uma aula
+//
+// Apenas uma sessão pode estar aberta por aula e o semestre da disciplina deve estar aberto. A sessão expira após a
+// duração informada, quando é encerrada automaticamente
+//
+// Retorna a sessão criada ou erro em caso de falha de validação ou persistência
+func AbrirChamada(aulaId string, abertura models.AberturaChamada, professorId string) (*models.SessaoChamada, *utils.RestErr) {
+	aula, restErr := buscaAula(aulaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	disciplina, restErr := buscaDisciplina(aula.DisciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	if sessao, restErr := buscaSessaoAberta(aulaId); restErr == nil && sessao != nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Já existe uma chamada aberta para esta aula", nil)
+	} else if restErr != nil && restErr.Code != http.StatusNotFound {
+		return nil, restErr
+	}
+
+	segredo := make([]byte, 32)
+	if _, err := rand.Read(segredo); err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao gerar segredo da chamada", err)
+	}
+
+	intervalo := abertura.IntervaloSegundos
+	if intervalo == 0 {
+		intervalo = intervaloPadraoChamada
+	}
+
+	sessao := models.SessaoChamada{
+		AulaId:            aula.Id,
+		ProfessorId:       professorId,
+		Segredo:           hex.EncodeToString(segredo),
+		IntervaloSegundos: intervalo,
+		ExpiraEm:          time.Now().Add(time.Duration(abertura.DuracaoMinutos) * time.Minute),
+	}
+
+	if err := database.DB.Create(&sessao).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao abrir chamada", err)
+	}
+
+	return &sessao, nil
+}
+
+// GetCodigoChamada retorna o código vigente da sessão de chamada aberta de uma aula
+//
+// Retorna o código com seu prazo de validade ou erro 404 caso não haja chamada aberta
+func GetCodigoChamada(aulaId string) (*models.CodigoChamada, *utils.RestErr) {
+	sessao, restErr := buscaSessaoAberta(aulaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	agora := time.Now()
+	janela := agora.Unix() / int64(sessao.IntervaloSegundos)
+	validoAte := time.Unix((janela+1)*int64(sessao.IntervaloSegundos), 0)
+	if validoAte.After(sessao.ExpiraEm) {
+		validoAte = sessao.ExpiraEm
+	}
+
+	return &models.CodigoChamada{
+		SessaoId:  sessao.Id,
+		Codigo:    geraCodigoChamada(sessao.Segredo, janela),
+		ValidoAte: validoAte,
+		ExpiraEm:  sessao.ExpiraEm,
+	}, nil
+}
+
+// GetQRCodeChamada gera a imagem PNG de um QR code contendo o código vigente da chamada aberta de uma aula
+//
+// Retorna os bytes da imagem ou erro caso não haja chamada aberta ou a geração falhe
+func GetQRCodeChamada(aulaId string) ([]byte, *utils.RestErr) {
+	codigo, restErr := GetCodigoChamada(aulaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	png, err := qrcode.Encode(codigo.Codigo, qrcode.Medium, 256)
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao gerar QR code", err)
+	}
+
+	return png, nil
+}
+
+// RegistrarCheckIn registra a presença de um aluno que informou o código da chamada aberta de uma aula
+//
+// O aluno deve estar ativo e matriculado na disciplina, e o semestre da disciplina deve estar aberto. São aceitos o
+// código vigente e o código imediatamente anterior, para tolerar a troca do código durante a digitação. O aluno é
+// marcado como presente na aula inteira
+//
+// Os códigos inválidos são contados por aluno e sessão; após maximoFalhasCheckIn falhas, o check-in do aluno na sessão
+// é recusado mesmo com o código correto. Código inválido e check-in bloqueado recebem o mesmo erro, para não revelar
+// o bloqueio a quem tenta descobrir o código
+//
+// Retorna o registro de presença do aluno ou erro caso o código seja inválido ou a persistência falhe
+func RegistrarCheckIn(aulaId string, codigo string, alunoId string) (*models.AlunoAula, *utils.RestErr) {
+	sessao, restErr := buscaSessaoAberta(aulaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	aula, restErr := buscaAula(aulaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	disciplina, restErr := buscaDisciplina(aula.DisciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	aluno, restErr := buscaAluno(alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !aluno.Ativo {
+		return nil, utils.NewRestErr(http.StatusForbidden, "Aluno com matrícula trancada", nil)
+	}
+
+	matriculado, restErr := alunoMatriculado(aula.DisciplinaId, alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !matriculado {
+		return nil, utils.NewRestErr(http.StatusForbidden, "Aluno não está matriculado na disciplina", nil)
+	}
+
+	var alunoAula *models.AlunoAula
+	codigoRecusado := false
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// A linha de falhas é travada para que tentativas simultâneas do mesmo aluno sejam contadas uma a uma
+		falhas := models.FalhasCheckIn{SessaoId: sessao.Id, AlunoId: alunoId}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&falhas).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar check-in", err)
+			return err
+		}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("sessao_id = ? AND aluno_id = ?", sessao.Id, alunoId).First(&falhas).Error
+		if err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar check-in", err)
+			return err
+		}
+
+		if falhas.Falhas >= maximoFalhasCheckIn {
+			codigoRecusado = true
+			return nil
+		}
+		if !codigoChamadaValido(sessao, codigo, time.Now()) {
+			codigoRecusado = true
+			// A falha é gravada mesmo com o check-in recusado, por isso a transação é confirmada
+			if err := tx.Model(&falhas).Update("falhas", gorm.Expr("falhas + 1")).Error; err != nil {
+				restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar check-in", err)
+				return err
+			}
+			return nil
+		}
+
+		checkIn := models.CheckInChamada{SessaoId: sessao.Id, AlunoId: alunoId}
+		if err := tx.Where(checkIn).FirstOrCreate(&checkIn).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar check-in", err)
+			return err
+		}
+
+		presenca := models.AlunoAula{AulaId: aula.Id, AlunoId: alunoId, Presenca: true}
+		normalizaHorasPresenca(&presenca, aula.QuantidadeHoras)
+
+		alunoAula, restErr = registraPresenca(tx, presenca, alunoId, models.AutorAluno, "Check-in na chamada da aula")
+		if restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar check-in", err)
+	}
+	if codigoRecusado {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Código de chamada inválido ou expirado", nil)
+	}
+
+	return alunoAula, nil
+}
+
+// EncerrarChamada encerra antecipadamente a sessão de chamada aberta de uma aula
+//
+// Os alunos ativos matriculados que não fizeram check-in são marcados como ausentes.
+//
+// Retorna a sessão encerrada ou erro caso não haja chamada aberta ou a persistência falhe
+func EncerrarChamada(aulaId string, professorId string) (*models.SessaoChamada, *utils.RestErr) {
+	sessao, restErr := buscaSessaoAberta(aulaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if restErr := encerraSessao(sessao, professorId, models.AutorProfessor); restErr != nil {
+		return nil, restErr
+	}
+
+	return sessao, nil
+}
+
+// MonitorarChamadas encerra periodicamente as sessões de chamada expiradas
+//
+// Deve ser executada em uma goroutine própria; a função não retorna. Erros são apenas registrados no log, e a sessão
+// é tentada novamente na próxima verificação
+func MonitorarChamadas(intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		encerraChamadasExpiradas()
+		<-ticker.C
+	}
+}
+
+// encerraChamadasExpiradas busca as sessões abertas cujo prazo já passou e as encerra
+func encerraChamadasExpiradas() {
+	var sessoes []models.SessaoChamada
+	err := database.DB.Where("encerrada_em IS NULL AND expira_em <= ?", time.Now()).Find(&sessoes).Error
+	if err != nil {
+		log.Printf("Erro ao buscar chamadas expiradas: %v", err)
+		return
+	}
+
+	for i := range sessoes {
+		if restErr := encerraSessao(&sessoes[i], sessoes[i].ProfessorId, models.AutorSistema); restErr != nil {
+			log.Printf("Erro ao encerrar chamada %s: %v", sessoes[i].Id, restErr.Err)
+		}
+	}
+}
+
+// encerraSessao marca a sessão como encerrada e registra ausência para os alunos que não fizeram check-in
+//
+// Todas as alterações são feitas em uma única transação e registradas no histórico de presenças. Se o semestre da
+// disciplina já tiver sido fechado, a sessão é apenas encerrada, sem alterar as presenças
+func encerraSessao(sessao *models.SessaoChamada, autorId string, autorTipo string) *utils.RestErr {
+	aula, restErr := buscaAula(sessao.AulaId)
+	if restErr != nil {
+		return restErr
+	}
+
+	disciplina, restErr := buscaDisciplina(aula.DisciplinaId)
+	if restErr != nil {
+		return restErr
+	}
+
+	var ausentes []string
+	if disciplina.Aberta() {
+		err := database.DB.Model(&models.AlunoDisciplina{}).
+			Joins("JOIN alunos ON alunos.id = aluno_disciplina.aluno_id").
+			Where("aluno_disciplina.disciplina_id = ? AND alunos.ativo = true", aula.DisciplinaId).
+			Where("aluno_disciplina.aluno_id NOT IN (?)",
+				database.DB.Model(&models.CheckInChamada{}).Select("aluno_id").Where("sessao_id = ?", sessao.Id)).
+			Pluck("aluno_disciplina.aluno_id", &ausentes).Error
+		if err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos da disciplina", err)
+		}
+	}
+
+	agora := time.Now()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, alunoId := range ausentes {
+			presenca := models.AlunoAula{AulaId: aula.Id, AlunoId: alunoId, Presenca: false}
+			if _, restErr = registraPresenca(tx, presenca, autorId, autorTipo, "Ausente no encerramento da chamada"); restErr != nil {
+				return restErr.Err
+			}
+		}
+
+		if err := tx.Model(sessao).Update("encerrada_em", agora).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao encerrar chamada", err)
+			return err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return restErr
+	}
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao encerrar chamada", err)
+	}
+
+	sessao.EncerradaEm = &agora
+	agendaAvaliacaoFrequencia(aula.DisciplinaId)
+	return nil
+}
+
+// buscaSessaoAberta busca a sessão de chamada aberta e ainda não expirada de uma aula
+//
+// Retorna erro 404 caso não haja sessão aberta ou erro interno se a consulta falhar
+func buscaSessaoAberta(aulaId string) (*models.SessaoChamada, *utils.RestErr) {
+	var sessao models.SessaoChamada
+	err := database.DB.
+		Where("aula_id = ? AND encerrada_em IS NULL AND expira_em > ?", aulaId, time.Now()).
+		First(&sessao).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "Não há chamada aberta para esta aula", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar chamada", err)
+	}
+
+	return &sessao, nil
+}
+
+// codigoChamadaValido confere se o código informado corresponde à janela de tempo atual ou à anterior da sessão
+func codigoChamadaValido(sessao *models.SessaoChamada, codigo string, agora time.Time) bool {
+	janela := agora.Unix() / int64(sessao.IntervaloSegundos)
+	for _, j := range []int64{janela, janela - 1} {
+		if hmac.Equal([]byte(geraCodigoChamada(sessao.Segredo, j)), []byte(codigo)) {
+			return true
+		}
+	}
+	return false
+}
+
+// geraCodigoChamada gera o código numérico de 6 dígitos de uma janela de tempo a partir do segredo da sessão
+//
+// Usa HMAC-SHA256 com truncamento dinâmico, no mesmo esquema dos códigos TOTP
+func geraCodigoChamada(segredo string, janela int64) string {
+	mensagem := make([]byte, 8)
+	binary.BigEndian.PutUint64(mensagem, uint64(janela))
+
+	mac := hmac.New(sha256.New, []byte(segredo))
+	mac.Write(mensagem)
+	soma := mac.Sum(nil)
+
+	deslocamento := soma[len(soma)-1] & 0x0f
+	valor := binary.BigEndian.Uint32(soma[deslocamento:deslocamento+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", valor%1000000)
+}
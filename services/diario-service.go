@@ -0,0 +1,246 @@
+package services
+
+import (
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"strconv"
+	"time"
+)
+
+// MontaDiarioClasse reúne os dados do diário de classe de uma disciplina
+//
+// Inclui todas as aulas registradas, em ordem de número, e a marcação de presença de cada aluno matriculado em cada
+// aula, com os alunos em ordem alfabética. Faltas abonadas por justificativas aprovadas são marcadas como abonadas
+//
+// Retorna o diário ou erro caso a disciplina não exista ou a consulta falhe
+func MontaDiarioClasse(disciplinaId string) (*models.DiarioClasse, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplinaDocumento(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	alunos, restErr := alunosDocumento(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	var aulas []models.Aula
+	if err := database.DB.Where("disciplina_id = ?", disciplinaId).Order("numero, data").Find(&aulas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar aulas da disciplina", err)
+	}
+
+	diario := &models.DiarioClasse{
+		CabecalhoDisciplina: cabecalhoDisciplina(disciplina),
+		Alunos:              alunos,
+		Aulas:               []models.DiarioAula{},
+	}
+	if len(aulas) == 0 {
+		return diario, nil
+	}
+
+	aulaIds := extractAulaIds(aulas)
+
+	var presencas []models.AlunoAula
+	if err := database.DB.Where("aula_id IN (?)", aulaIds).Find(&presencas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar presenças da disciplina", err)
+	}
+
+	presencaPorAula := make(map[string]models.AlunoAula, len(presencas))
+	for _, p := range presencas {
+		presencaPorAula[p.AulaId+"/"+p.AlunoId] = p
+	}
+
+	abonadasPorAluno := make(map[string]map[string]bool, len(alunos))
+	for _, aluno := range alunos {
+		abonadas, restErr := aulasAbonadas(aluno.AlunoId, aulaIds)
+		if restErr != nil {
+			return nil, restErr
+		}
+		abonadasPorAluno[aluno.AlunoId] = abonadas
+	}
+
+	for _, aula := range aulas {
+		linha := models.DiarioAula{
+			Numero:          aula.Numero,
+			Data:            aula.Data,
+			QuantidadeHoras: aula.QuantidadeHoras,
+			Conteudo:        aula.Conteudo,
+			Presencas:       make([]string, len(alunos)),
+		}
+		for i, aluno := range alunos {
+			presenca, registrada := presencaPorAula[aula.Id+"/"+aluno.AlunoId]
+			linha.Presencas[i] = marcaPresenca(&aula, presenca, registrada, abonadasPorAluno[aluno.AlunoId][aula.Id])
+		}
+		diario.Aulas = append(diario.Aulas, linha)
+	}
+
+	return diario, nil
+}
+
+// MontaAtaFinal reúne os dados da ata final de uma disciplina
+//
+// Inclui todas as avaliações, em ordem de data, e, para cada aluno matriculado, em ordem alfabética, as notas na
+// escala da disciplina, a frequência, a média final e a situação. Alunos sem resultado registrado aparecem com a
+// frequência parcial e sem média final
+//
+// Retorna a ata ou erro caso a disciplina não exista ou a consulta falhe
+func MontaAtaFinal(disciplinaId string) (*models.AtaFinal, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplinaDocumento(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	alunos, restErr := alunosDocumento(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	var avaliacoes []models.Avaliacao
+	if err := database.DB.Where("disciplina_id = ?", disciplinaId).Order("data_avaliacao, nome").Find(&avaliacoes).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliações da disciplina", err)
+	}
+
+	var notas []models.AlunoAvaliacao
+	if err := database.DB.Where("disciplina_id = ?", disciplinaId).Find(&notas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar notas da disciplina", err)
+	}
+
+	var resultados []models.AlunoMedia
+	if err := database.DB.Where("disciplina_id = ?", disciplinaId).Find(&resultados).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar resultados da disciplina", err)
+	}
+
+	var aulas []models.Aula
+	if err := database.DB.Where("disciplina_id = ?", disciplinaId).Find(&aulas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar aulas da disciplina", err)
+	}
+
+	notaPorAvaliacao := make(map[string]models.AlunoAvaliacao, len(notas))
+	for _, n := range notas {
+		notaPorAvaliacao[n.AvaliacaoId+"/"+n.AlunoId] = n
+	}
+
+	resultadoPorAluno := make(map[string]models.AlunoMedia, len(resultados))
+	for _, r := range resultados {
+		resultadoPorAluno[r.AlunoId] = r
+	}
+
+	ata := &models.AtaFinal{
+		CabecalhoDisciplina: cabecalhoDisciplina(disciplina),
+		Avaliacoes:          []models.AvaliacaoDocumento{},
+		Alunos:              []models.AtaAluno{},
+	}
+	for _, avaliacao := range avaliacoes {
+		ata.Avaliacoes = append(ata.Avaliacoes, models.AvaliacaoDocumento{
+			Nome: avaliacao.Nome,
+			Tipo: avaliacao.Tipo,
+			Data: avaliacao.DataAvaliacao,
+			Peso: avaliacao.Peso,
+		})
+	}
+
+	for _, aluno := range alunos {
+		linha := models.AtaAluno{
+			AlunoDocumento: aluno,
+			Notas:          make([]string, len(avaliacoes)),
+		}
+		for i, avaliacao := range avaliacoes {
+			if nota, ok := notaPorAvaliacao[avaliacao.Id+"/"+aluno.AlunoId]; ok {
+				linha.Notas[i] = notaExibida(disciplina, &nota)
+			}
+		}
+
+		if resultado, ok := resultadoPorAluno[aluno.AlunoId]; ok {
+			media := resultado.MediaFinal
+			linha.MediaFinal = &media
+			linha.MediaConvertida = resultado.MediaConvertida
+			if linha.MediaConvertida == "" {
+				linha.MediaConvertida = converteMedia(disciplina.Escala, media)
+			}
+			linha.Frequencia = resultado.Frequencia
+			linha.Situacao = resultado.Situacao
+		} else {
+			frequencia, restErr := calculaFrequencia(disciplina, aulas, aluno.AlunoId)
+			if restErr != nil {
+				return nil, restErr
+			}
+			linha.Frequencia = frequencia
+		}
+
+		ata.Alunos = append(ata.Alunos, linha)
+	}
+
+	return ata, nil
+}
+
+// buscaDisciplinaDocumento busca uma disciplina com o professor responsável, para a emissão dos seus documentos
+func buscaDisciplinaDocumento(disciplinaId string) (*models.Disciplina, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	var professor models.Professor
+	if err := database.DB.Where("id = ?", disciplina.ProfessorId).Limit(1).Find(&professor).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar professor da disciplina", err)
+	}
+	if professor.Id != "" {
+		disciplina.Professor = &professor
+	}
+
+	return disciplina, nil
+}
+
+// cabecalhoDisciplina monta a identificação da disciplina usada nos documentos emitidos para ela
+func cabecalhoDisciplina(disciplina *models.Disciplina) models.CabecalhoDisciplina {
+	cabecalho := models.CabecalhoDisciplina{
+		DisciplinaId:         disciplina.Id,
+		Disciplina:           disciplina.Nome,
+		AnoSemestre:          disciplina.AnoSemestre,
+		CargaHorariaPrevista: disciplina.CargaHorariaPrevista,
+		EmitidoEm:            time.Now(),
+	}
+	if disciplina.Professor != nil {
+		cabecalho.Professor = disciplina.Professor.Nome
+	}
+	return cabecalho
+}
+
+// alunosDocumento retorna os alunos matriculados em uma disciplina em ordem alfabética, incluindo os inativos
+func alunosDocumento(disciplinaId string) ([]models.AlunoDocumento, *utils.RestErr) {
+	alunos := []models.AlunoDocumento{}
+	err := database.DB.Model(&models.Aluno{}).
+		Select("alunos.id AS aluno_id, alunos.nome").
+		Joins("JOIN aluno_disciplina ON aluno_disciplina.aluno_id = alunos.id").
+		Where("aluno_disciplina.disciplina_id = ?", disciplinaId).
+		Order("alunos.nome, alunos.id").
+		Scan(&alunos).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos da disciplina", err)
+	}
+
+	return alunos, nil
+}
+
+// marcaPresenca retorna a marcação de presença de um aluno em uma aula no diário de classe
+//
+// Presenças parciais são marcadas com as horas assistidas sobre a duração da aula e faltas abonadas prevalecem sobre
+// faltas e aulas sem registro
+func marcaPresenca(aula *models.Aula, presenca models.AlunoAula, registrada bool, abonada bool) string {
+	switch {
+	case registrada && presenca.Presenca && presenca.HorasPresentes > 0 && presenca.HorasPresentes < aula.QuantidadeHoras:
+		return strconv.Itoa(presenca.HorasPresentes) + "/" + strconv.Itoa(aula.QuantidadeHoras)
+	case registrada && presenca.Presenca && presenca.Atraso:
+		return models.MarcaAtraso
+	case registrada && presenca.Presenca:
+		return models.MarcaPresente
+	case abonada:
+		return models.MarcaAbonada
+	case registrada:
+		return models.MarcaFalta
+	default:
+		return models.MarcaSemRegistro
+	}
+}
@@ -3,20 +3,39 @@ package services
 import (
 	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
 	"net/http"
 	"sistema-alunos-go/database"
+	"sistema-alunos-go/formula"
 	"sistema-alunos-go/models"
 	"sistema-alunos-go/utils"
 )
 
 // CadastrarDisciplina registra uma nova disciplina associada a um professor
 //
-// Define o ID do professor na disciplina e salva no banco
+// Define o ID do professor na disciplina e salva no banco. A sintaxe da fórmula de média, se informada, é verificada;
+// as avaliações referenciadas por ela só são conferidas ao configurar a disciplina e ao fechar o semestre
 // Após o cadastro, busca os dados do professor para retornar no payload
 //
 // Retorna a disciplina criada ou erro, caso ocorra falha ao salvar ou buscar dados
 func CadastrarDisciplina(disciplina models.Disciplina, professorId string) (*models.Disciplina, *utils.RestErr) {
 	disciplina.ProfessorId = professorId
+	disciplina.Situacao = models.DisciplinaAberta
+	if disciplina.ModoFrequencia == "" {
+		disciplina.ModoFrequencia = models.FrequenciaPorHoras
+	}
+	if disciplina.RegraRecuperacao == "" {
+		disciplina.RegraRecuperacao = models.RecuperacaoSubstitui
+	}
+	if disciplina.Escala == "" {
+		disciplina.Escala = models.EscalaDecimal
+	}
+	if disciplina.FormulaMedia != "" {
+		if _, restErr := compilaFormulaMedia(disciplina.FormulaMedia); restErr != nil {
+			return nil, restErr
+		}
+	}
 	if err := database.DB.Create(&disciplina).Error; err != nil {
 		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar disciplina", err)
 	}
@@ -35,7 +54,8 @@ func CadastrarDisciplina(disciplina models.Disciplina, professorId string) (*mod
 
 // Matricular associa um aluno a uma disciplina
 //
-// Cria um registro em aluno_disciplina e atualiza o contador de alunos da disciplina
+// Cria um registro em aluno_disciplina e atualiza o contador de alunos da disciplina na mesma transação, em que também
+// é publicado o evento aluno.matriculado para os webhooks
 //
 // Retorna o vínculo criado ou erro em caso de falha
 func Matricular(disciplinaId string, alunoId string) (*models.AlunoDisciplina, *utils.RestErr) {
@@ -53,21 +73,39 @@ func Matricular(disciplinaId string, alunoId string) (*models.AlunoDisciplina, *
 		AlunoId:      alunoId,
 	}
 
-	if err := database.DB.Create(&alunoDisciplina).Error; err != nil {
-		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao matricular aluno", err)
-	}
+	err := transacaoEventos(func(tx *gorm.DB) error {
+		if err := tx.Create(&alunoDisciplina).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao matricular aluno", err)
+			return err
+		}
+
+		disciplina.QuantidadeAlunos = disciplina.QuantidadeAlunos + 1
+		if err := tx.Save(&disciplina).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar quantidade de alunos", err)
+			return err
+		}
 
-	disciplina.QuantidadeAlunos = disciplina.QuantidadeAlunos + 1
-	if err := database.DB.Save(&disciplina).Error; err != nil {
-		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar quantidade de alunos", err)
+		if restErr = publicaEvento(tx, models.EventoAlunoMatriculado, alunoDisciplina); restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao matricular aluno", err)
 	}
 
 	return &alunoDisciplina, nil
 }
 
-// AdicionarAvaliacao adiciona uma nova avaliação (prova ou trabalho) a uma disciplina
+// AdicionarAvaliacao adiciona uma nova avaliação (prova, trabalho ou recuperação) a uma disciplina
 //
-// # Atualiza os contadores de provas ou trabalhos na disciplina com base no tipo de avaliação
+// Atualiza os contadores de provas ou trabalhos na disciplina com base no tipo de avaliação, na mesma transação em que
+// a avaliação é criada. Não é permitido adicionar avaliações a disciplinas com o semestre fechado, exceto a avaliação
+// de recuperação durante a recuperação. Cada disciplina possui no máximo uma avaliação de recuperação, e a nova
+// avaliação não pode invalidar a fórmula de média da disciplina
 //
 // Retorna a avaliação criada ou erro em caso de falha
 func AdicionarAvaliacao(avaliacao models.Avaliacao, disciplinaId string) (*models.Avaliacao, *utils.RestErr) {
@@ -76,51 +114,192 @@ func AdicionarAvaliacao(avaliacao models.Avaliacao, disciplinaId string) (*model
 		return nil, restErr
 	}
 
-	avaliacao.DisciplinaId = disciplina.Id
-
-	if avaliacao.Tipo == "P" {
-		disciplina.QuantidadeProvas += 1
-	} else {
-		disciplina.QuantidadeTrabalhos += 1
+	if restErr := verificaAvaliacaoAlteravel(disciplina, avaliacao.Tipo); restErr != nil {
+		return nil, restErr
 	}
 
-	if err := database.DB.Save(&disciplina).Error; err != nil {
-		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar disciplina", err)
+	if avaliacao.Recuperacao() {
+		recuperacao, restErr := buscaAvaliacaoRecuperacao(disciplinaId)
+		if restErr != nil {
+			return nil, restErr
+		}
+		if recuperacao != nil {
+			return nil, utils.NewRestErr(http.StatusConflict, "A disciplina já possui uma avaliação de recuperação", nil)
+		}
+	}
 
+	if restErr := validaFormulaSemAvaliacao(disciplina, "", &avaliacao); restErr != nil {
+		return nil, restErr
 	}
 
-	if err := database.DB.Create(&avaliacao).Error; err != nil {
-		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao inserir avaliação", err)
+	avaliacao.DisciplinaId = disciplina.Id
 
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&avaliacao).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao inserir avaliação", err)
+			return err
+		}
+
+		if restErr = recalculaQuantidadeAvaliacoes(tx, disciplina.Id); restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao inserir avaliação", err)
 	}
 
 	return &avaliacao, nil
 }
 
-// AdicionarNotaAvaliacao associa uma lista de notas de alunos a uma determinada avaliação
+// AdicionarNotaAvaliacao lança ou atualiza as notas de uma lista de alunos em uma determinada avaliação
+//
+// A avaliação deve pertencer à disciplina e cada aluno deve estar matriculado nela, com matrícula ativa, e aparecer
+// uma única vez na lista. No modo estrito, qualquer linha inválida rejeita toda a lista com a relação de erros por
+// linha; no modo parcial, as linhas válidas são gravadas e as inválidas são devolvidas como rejeitadas.
 //
-// A função insere múltiplos registros na tabela aluno_avaliacao com as notas fornecidas
+// Cada aluno possui uma única nota por avaliação: se o aluno já tiver nota, ela é substituída. Toda nota lançada ou
+// alterada é registrada no histórico de notas com a justificativa informada, ou uma justificativa padrão se vazia.
+// As notas são gravadas em uma única transação e não podem ser alteradas após o fechamento do semestre. Notas da
+// avaliação de recuperação só são aceitas durante a recuperação e para os alunos em recuperação, e avaliações com
+// rubrica só recebem notas pela correção da rubrica. Em trabalhos entregues com atraso, a penalidade configurada na
+// janela de entrega é descontada da nota lançada
 //
-// Retorna a lista salva ou erro em caso de falha de validação ou persistência
-func AdicionarNotaAvaliacao(alunosNota []models.AlunoAvaliacao, avaliacaoId string, disciplinaId string) ([]models.AlunoAvaliacao, *utils.RestErr) {
-	if _, restErr := buscaDisciplina(disciplinaId); restErr != nil {
+// Retorna as notas salvas e as linhas rejeitadas ou erro em caso de falha de validação ou persistência
+func AdicionarNotaAvaliacao(alunosNota []models.AlunoAvaliacao, avaliacaoId string, disciplinaId string, professorId string, justificativa string, parcial bool) (*models.ResultadoNotas, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
 		return nil, restErr
 	}
 
-	if _, restErr := buscaAvaliacao(avaliacaoId); restErr != nil {
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
 		return nil, restErr
 	}
 
-	for i := range alunosNota {
-		alunosNota[i].AvaliacaoId = avaliacaoId
-		alunosNota[i].DisciplinaId = disciplinaId
+	if restErr := verificaNotaAlteravel(disciplina, avaliacao); restErr != nil {
+		return nil, restErr
 	}
 
-	if err := database.DB.Create(&alunosNota).Error; err != nil {
+	if restErr := verificaNotaSemRubrica(avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	var permitidos map[string]bool
+	if avaliacao.Recuperacao() {
+		if permitidos, restErr = alunosEmRecuperacao(disciplinaId); restErr != nil {
+			return nil, restErr
+		}
+	}
+
+	validas, rejeitadas, restErr := validaNotas(disciplina, alunosNota, permitidos)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if len(rejeitadas) > 0 && (!parcial || len(validas) == 0) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Lista de notas inválida", nil, rejeitadas)
+	}
+
+	if justificativa == "" {
+		justificativa = motivoLancamentoNotas
+	}
+
+	penalidades, restErr := penalidadesAtraso(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	resultado := &models.ResultadoNotas{Salvas: []models.AlunoAvaliacao{}, Rejeitadas: rejeitadas}
+	err := transacaoEventos(func(tx *gorm.DB) error {
+		for _, alunoNota := range validas {
+			alunoNota.AvaliacaoId = avaliacaoId
+			alunoNota.DisciplinaId = disciplinaId
+			aplicaPenalidade(disciplina.Escala, &alunoNota, penalidades[alunoNota.AlunoId])
+
+			salva, restErrNota := registraNota(tx, alunoNota, professorId, models.AutorProfessor, justificativa)
+			if restErrNota != nil {
+				restErr = restErrNota
+				return restErrNota.Err
+			}
+			resultado.Salvas = append(resultado.Salvas, *salva)
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
 		return nil, utils.NewRestErr(500, "Erro ao salvar notas dos alunos", err)
 	}
 
-	return alunosNota, nil
+	return resultado, nil
+}
+
+// ConfigurarDisciplina altera os critérios de cálculo de uma disciplina
+//
+// Apenas os campos informados são alterados e somente enquanto o semestre da disciplina estiver aberto. Uma nova
+// fórmula de média só é aceita se for válida e referenciar apenas avaliações já cadastradas na disciplina, e a escala
+// de notas só pode ser alterada enquanto nenhuma nota tiver sido lançada.
+//
+// Retorna a disciplina atualizada ou erro caso não exista, esteja fechada ou a persistência falhe
+func ConfigurarDisciplina(disciplinaId string, configuracao models.ConfiguracaoDisciplina) (*models.Disciplina, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	if configuracao.ModoFrequencia != nil {
+		disciplina.ModoFrequencia = *configuracao.ModoFrequencia
+	}
+	if configuracao.NormalizarPesos != nil {
+		disciplina.NormalizarPesos = *configuracao.NormalizarPesos
+	}
+	if configuracao.Escala != nil && *configuracao.Escala != disciplina.Escala {
+		var notasLancadas int64
+		if err := database.DB.Model(&models.AlunoAvaliacao{}).Where("disciplina_id = ?", disciplinaId).Count(&notasLancadas).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar notas da disciplina", err)
+		}
+		if notasLancadas > 0 {
+			return nil, utils.NewRestErr(http.StatusConflict, "A escala de notas não pode ser alterada após o lançamento de notas", nil)
+		}
+		disciplina.Escala = *configuracao.Escala
+	}
+	if configuracao.Recuperacao != nil {
+		disciplina.Recuperacao = *configuracao.Recuperacao
+	}
+	if configuracao.NotaMinimaRecuperacao != nil {
+		disciplina.NotaMinimaRecuperacao = *configuracao.NotaMinimaRecuperacao
+	}
+	if configuracao.RegraRecuperacao != nil {
+		disciplina.RegraRecuperacao = *configuracao.RegraRecuperacao
+	}
+	if disciplina.NotaMinimaRecuperacao >= disciplina.NotaMinima {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A nota mínima para recuperação deve ser menor que a nota mínima da disciplina", nil)
+	}
+	if configuracao.FormulaMedia != nil {
+		avaliacoes, restErr := buscaAvaliacoesDisciplina(disciplinaId)
+		if restErr != nil {
+			return nil, restErr
+		}
+		if restErr := validaFormulaMedia(*configuracao.FormulaMedia, avaliacoes); restErr != nil {
+			return nil, restErr
+		}
+		disciplina.FormulaMedia = *configuracao.FormulaMedia
+	}
+
+	if err := database.DB.Omit(clause.Associations).Save(disciplina).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar disciplina", err)
+	}
+
+	return disciplina, nil
 }
 
 // ListarDisciplinas retorna todas as disciplinas associadas a um professor
@@ -143,7 +322,18 @@ func ListarDisciplinas(professorId string) ([]models.Disciplina, *utils.RestErr)
 
 // FecharSemestre finaliza o semestre de uma disciplina calculando média e frequência dos alunos
 //
-// # Calcula a média ponderada com base nas avaliações e a frequência baseada nas presenças
+// Calcula a média ponderada com base nas avaliações e a frequência baseada nas presenças, por horas ou por aulas
+// conforme o modo de frequência da disciplina. Os pesos das avaliações devem somar 1, a menos que a disciplina tenha
+// optado pela normalização dos pesos. Se a disciplina tiver uma fórmula de média, ela é usada no lugar dos pesos
+//
+// Se a disciplina tiver recuperação, os alunos com frequência suficiente e média entre a nota mínima para recuperação
+// e a nota mínima da disciplina ficam em recuperação e não são aprovados nesta etapa. Cada aluno recebe uma situação
+// detalhada; alunos com a matrícula trancada ficam com a situação "trancado". As tentativas de questionários online
+// cujo tempo terminou sem finalização são corrigidas antes do cálculo.
+//
+// Ao final, a disciplina é marcada como fechada, bloqueando alterações posteriores em suas aulas, ou como em
+// recuperação, se houver alunos em recuperação. Nesse caso, o resultado definitivo é calculado por FecharRecuperacao.
+// Em ambos os casos, o evento semestre.fechado é publicado para os webhooks.
 //
 // Retorna a lista de AlunoMedia com aprovação e dados finais ou erro em caso de falha
 func FecharSemestre(disciplinaId string) ([]models.AlunoMedia, *utils.RestErr) {
@@ -152,12 +342,20 @@ func FecharSemestre(disciplinaId string) ([]models.AlunoMedia, *utils.RestErr) {
 		return nil, restErr
 	}
 
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(400, "O semestre da disciplina já foi fechado", nil)
+	}
+
 	if disciplina.CargaHorariaRealizada < disciplina.CargaHorariaPrevista {
 		return nil, utils.NewRestErr(400, "Carga horária realizada menor que a prevista", nil)
 	}
 
-	var alunosDisciplina []models.AlunoDisciplina
-	if err := database.DB.Where("disciplina_id = ?", disciplinaId).Find(&alunosDisciplina).Error; err != nil {
+	var alunos []models.Aluno
+	err := database.DB.
+		Joins("JOIN aluno_disciplina ON aluno_disciplina.aluno_id = alunos.id").
+		Where("aluno_disciplina.disciplina_id = ?", disciplinaId).
+		Find(&alunos).Error
+	if err != nil {
 		return nil, utils.NewRestErr(500, "Erro ao buscar alunos da disciplina", err)
 	}
 
@@ -166,13 +364,13 @@ func FecharSemestre(disciplinaId string) ([]models.AlunoMedia, *utils.RestErr) {
 		return nil, utils.NewRestErr(500, "Erro ao buscar aulas da disciplina", err)
 	}
 
-	totalAulas := len(aulas)
-	if totalAulas == 0 {
+	if len(aulas) == 0 {
 		return nil, utils.NewRestErr(400, "Disciplina não possui aulas registradas", nil)
 	}
 
 	var avaliacoes []models.Avaliacao
-	if err := database.DB.Where("disciplina_id = ?", disciplinaId).Find(&avaliacoes).Error; err != nil {
+	err = database.DB.Where("disciplina_id = ? AND tipo <> ?", disciplinaId, models.AvaliacaoRecuperacao).Find(&avaliacoes).Error
+	if err != nil {
 		return nil, utils.NewRestErr(500, "Erro ao buscar avaliações da disciplina", err)
 	}
 
@@ -180,56 +378,219 @@ func FecharSemestre(disciplinaId string) ([]models.AlunoMedia, *utils.RestErr) {
 		return nil, utils.NewRestErr(400, "A disciplina não possui avaliações cadastradas", nil)
 	}
 
+	var formulaMedia *formula.Formula
+	if disciplina.FormulaMedia != "" {
+		if restErr := validaFormulaMedia(disciplina.FormulaMedia, avaliacoes); restErr != nil {
+			return nil, restErr
+		}
+		if formulaMedia, restErr = compilaFormulaMedia(disciplina.FormulaMedia); restErr != nil {
+			return nil, restErr
+		}
+	} else if consistencia := verificaPesos(disciplina, avaliacoes); !consistencia.PodeFechar {
+		return nil, utils.NewRestErr(400, "A soma dos pesos das avaliações deve ser 1", nil, consistencia)
+	}
+
+	// As tentativas expiradas só são finalizadas depois de todas as verificações, para que um fechamento recusado
+	// não lance notas de questionários
+	if restErr := finalizaTentativasDisciplina(disciplinaId); restErr != nil {
+		return nil, restErr
+	}
+
 	var medias []models.AlunoMedia
+	situacaoDisciplina := models.DisciplinaFechada
 
-	for _, ad := range alunosDisciplina {
-		var presencas int64
-		if err := database.DB.
-			Model(&models.AlunoAula{}).
-			Where("aluno_id = ? AND presenca = true AND aula_id IN (?)", ad.AlunoId, extractAulaIds(aulas)).
-			Count(&presencas).Error; err != nil {
-			return nil, utils.NewRestErr(500, "Erro ao calcular frequência", err)
+	for _, aluno := range alunos {
+		frequencia, restErr := calculaFrequencia(disciplina, aulas, aluno.Id)
+		if restErr != nil {
+			return nil, restErr
 		}
-		frequencia := float64(presencas) / float64(totalAulas) * 100
 
 		var notas []models.AlunoAvaliacao
 		if err := database.DB.
-			Where("aluno_id = ? AND avaliacao_id IN (?)", ad.AlunoId, extractAvaliacaoIds(avaliacoes)).
+			Where("aluno_id = ? AND avaliacao_id IN (?)", aluno.Id, extractAvaliacaoIds(avaliacoes)).
 			Find(&notas).Error; err != nil {
 			return nil, utils.NewRestErr(500, "Erro ao buscar notas do aluno", err)
 		}
 
-		var soma float64
-		var pesoTotal float64
-
-		for _, avaliacao := range avaliacoes {
-			nota := findNota(notas, avaliacao.Id)
-			soma += nota * avaliacao.Peso
-			pesoTotal += avaliacao.Peso
+		mediaFinal, restErr := calculaMedia(formulaMedia, avaliacoes, notas)
+		if restErr != nil {
+			return nil, restErr
 		}
 
-		if pesoTotal == 0 {
-			return nil, utils.NewRestErr(400, "Peso total das avaliações é zero", nil)
+		situacao := situacaoSemestre(disciplina, aluno.Ativo, mediaFinal, frequencia)
+		if situacao == models.SituacaoEmRecuperacao {
+			situacaoDisciplina = models.DisciplinaEmRecuperacao
 		}
-		mediaFinal := soma / pesoTotal
 
-		aprovado := mediaFinal >= disciplina.NotaMinima && frequencia >= disciplina.FrequenciaMinima
 		medias = append(medias, models.AlunoMedia{
-			AlunoId:      ad.AlunoId,
-			DisciplinaId: disciplinaId,
-			MediaFinal:   mediaFinal,
-			Frequencia:   frequencia,
-			Aprovado:     aprovado,
+			AlunoId:         aluno.Id,
+			DisciplinaId:    disciplinaId,
+			MediaSemestre:   mediaFinal,
+			MediaFinal:      mediaFinal,
+			MediaConvertida: converteMedia(disciplina.Escala, mediaFinal),
+			Frequencia:      frequencia,
+			Aprovado:        situacao == models.SituacaoAprovado,
+			Situacao:        situacao,
+			EmRecuperacao:   situacao == models.SituacaoEmRecuperacao,
 		})
 	}
 
-	if err := database.DB.Create(&medias).Error; err != nil {
+	var restErrTx *utils.RestErr
+	err = transacaoEventos(func(tx *gorm.DB) error {
+		if len(medias) > 0 {
+			if err := tx.Create(&medias).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(disciplina).Update("situacao", situacaoDisciplina).Error; err != nil {
+			return err
+		}
+
+		if restErrTx = publicaSemestreFechado(tx, disciplina, situacaoDisciplina, medias); restErrTx != nil {
+			return restErrTx.Err
+		}
+		return nil
+	})
+	if restErrTx != nil {
+		return nil, restErrTx
+	}
+	if err != nil {
 		return nil, utils.NewRestErr(500, "Erro ao salvar médias dos alunos", err)
 	}
 
 	return medias, nil
 }
 
+// FecharRecuperacao calcula o resultado definitivo dos alunos em recuperação e fecha a disciplina
+//
+// A disciplina deve estar em recuperação e possuir uma avaliação de recuperação. Alunos sem nota de recuperação ficam
+// com zero. A média final é calculada conforme a regra de recuperação da disciplina e nunca fica abaixo da média do
+// semestre; o aluno é aprovado em recuperação se ela atingir a nota mínima da disciplina e, caso contrário, reprovado
+// por nota. O evento semestre.fechado é publicado novamente para os webhooks, com os resultados da recuperação
+//
+// Retorna as médias atualizadas dos alunos em recuperação ou erro em caso de falha
+func FecharRecuperacao(disciplinaId string) ([]models.AlunoMedia, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !disciplina.EmRecuperacao() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A disciplina não está em recuperação", nil)
+	}
+
+	recuperacao, restErr := buscaAvaliacaoRecuperacao(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if recuperacao == nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A disciplina não possui avaliação de recuperação cadastrada", nil)
+	}
+
+	var medias []models.AlunoMedia
+	err := database.DB.Where("disciplina_id = ? AND em_recuperacao = true", disciplinaId).Find(&medias).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar médias dos alunos", err)
+	}
+
+	var notas []models.AlunoAvaliacao
+	if err := database.DB.Where("avaliacao_id = ?", recuperacao.Id).Find(&notas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar notas de recuperação", err)
+	}
+
+	notasAluno := make(map[string]float64, len(notas))
+	for _, n := range notas {
+		notasAluno[n.AlunoId] = n.Nota
+	}
+
+	err = transacaoEventos(func(tx *gorm.DB) error {
+		for i := range medias {
+			nota := notasAluno[medias[i].AlunoId]
+			medias[i].NotaRecuperacao = &nota
+			medias[i].MediaFinal = mediaAposRecuperacao(disciplina, medias[i].MediaSemestre, nota)
+			medias[i].MediaConvertida = converteMedia(disciplina.Escala, medias[i].MediaFinal)
+			medias[i].Aprovado = medias[i].MediaFinal >= disciplina.NotaMinima
+			medias[i].Situacao = models.SituacaoReprovadoNota
+			if medias[i].Aprovado {
+				medias[i].Situacao = models.SituacaoAprovadoRecuperacao
+			}
+
+			if err := tx.Omit(clause.Associations).Save(&medias[i]).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(disciplina).Update("situacao", models.DisciplinaFechada).Error; err != nil {
+			return err
+		}
+
+		if restErr = publicaSemestreFechado(tx, disciplina, models.DisciplinaFechada, medias); restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao salvar médias dos alunos", err)
+	}
+
+	return medias, nil
+}
+
+// publicaSemestreFechado publica o evento semestre.fechado com a nova situação da disciplina e as médias calculadas
+func publicaSemestreFechado(tx *gorm.DB, disciplina *models.Disciplina, situacao string, medias []models.AlunoMedia) *utils.RestErr {
+	return publicaEvento(tx, models.EventoSemestreFechado, models.SemestreFechado{
+		DisciplinaId: disciplina.Id,
+		Disciplina:   disciplina.Nome,
+		AnoSemestre:  disciplina.AnoSemestre,
+		Situacao:     situacao,
+		Resultados:   medias,
+	})
+}
+
+// situacaoSemestre determina a situação de um aluno no fechamento do semestre
+//
+// Alunos com a matrícula trancada ficam trancados independentemente da média e da frequência. Se a disciplina tiver
+// recuperação, alunos com frequência suficiente e média abaixo da mínima, mas não abaixo da nota mínima para
+// recuperação, ficam em recuperação
+func situacaoSemestre(disciplina *models.Disciplina, ativo bool, media float64, frequencia float64) string {
+	notaSuficiente := media >= disciplina.NotaMinima
+	frequenciaSuficiente := frequencia >= disciplina.FrequenciaMinima
+
+	switch {
+	case !ativo:
+		return models.SituacaoTrancado
+	case notaSuficiente && frequenciaSuficiente:
+		return models.SituacaoAprovado
+	case !notaSuficiente && !frequenciaSuficiente:
+		return models.SituacaoReprovadoNotaFalta
+	case !frequenciaSuficiente:
+		return models.SituacaoReprovadoFalta
+	case disciplina.Recuperacao && media >= disciplina.NotaMinimaRecuperacao:
+		return models.SituacaoEmRecuperacao
+	default:
+		return models.SituacaoReprovadoNota
+	}
+}
+
+// mediaAposRecuperacao aplica a regra de recuperação da disciplina à média do semestre e à nota de recuperação
+//
+// O resultado nunca é menor que a média do semestre
+func mediaAposRecuperacao(disciplina *models.Disciplina, mediaSemestre float64, notaRecuperacao float64) float64 {
+	var media float64
+	switch disciplina.RegraRecuperacao {
+	case models.RecuperacaoMedia:
+		media = (mediaSemestre + notaRecuperacao) / 2
+	case models.RecuperacaoLimita:
+		media = math.Min(notaRecuperacao, disciplina.NotaMinima)
+	default:
+		media = notaRecuperacao
+	}
+
+	return math.Max(media, mediaSemestre)
+}
+
 // buscaDisciplina é uma função auxiliar para buscar uma disciplina pelo ID
 //
 // Retorna a disciplina encontrada ou erro, caso não exista ou ocorra falha na consulta
@@ -246,6 +607,41 @@ func buscaDisciplina(id string) (*models.Disciplina, *utils.RestErr) {
 	return &disciplina, nil
 }
 
+// verificaResponsavelDisciplina confere se o professor é o responsável pela disciplina ou um coordenador
+//
+// Retorna erro 403 caso o professor não tenha permissão sobre a disciplina ou erro caso a consulta falhe
+func verificaResponsavelDisciplina(disciplina *models.Disciplina, professorId string) *utils.RestErr {
+	if disciplina.ProfessorId == professorId {
+		return nil
+	}
+
+	professor, restErr := buscaProfessor(professorId)
+	if restErr != nil {
+		return restErr
+	}
+
+	if !professor.Coordenador {
+		return utils.NewRestErr(http.StatusForbidden, "Apenas o professor da disciplina ou um coordenador pode realizar esta operação", nil)
+	}
+
+	return nil
+}
+
+// alunoMatriculado verifica se um aluno possui matrícula em uma disciplina
+//
+// Retorna true se houver vínculo em aluno_disciplina ou erro caso a consulta falhe
+func alunoMatriculado(disciplinaId string, alunoId string) (bool, *utils.RestErr) {
+	var total int64
+	err := database.DB.Model(&models.AlunoDisciplina{}).
+		Where("disciplina_id = ? AND aluno_id = ?", disciplinaId, alunoId).
+		Count(&total).Error
+	if err != nil {
+		return false, utils.NewRestErr(http.StatusInternalServerError, "Erro ao verificar matrícula do aluno", err)
+	}
+
+	return total > 0, nil
+}
+
 // extractAulaIds extrai os IDs de uma lista de aulas
 //
 // Retorna um slice de strings contendo os IDs das aulas fornecidas
@@ -281,19 +677,3 @@ func findNota(notas []models.AlunoAvaliacao, avaliacaoId string) float64 {
 	}
 	return 0.0
 }
-
-// buscaAvaliacao recupera uma avaliação pelo ID
-//
-// Se a avaliação não for encontrada, retorna erro 404
-// Em caso de falha de consulta, retorna erro interno
-func buscaAvaliacao(id string) (*models.Avaliacao, *utils.RestErr) {
-	var avaliacao models.Avaliacao
-	if err := database.DB.Where("id = ?", id).Find(&avaliacao).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, utils.NewRestErr(http.StatusNotFound, "Avaliação não encontrada", err)
-		}
-		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliação", err)
-	}
-
-	return &avaliacao, nil
-}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/storage"
+	"sistema-alunos-go/utils"
+	"strings"
+	"time"
+)
+
+// tamanhoMaximoEntrega é o tamanho máximo, em bytes, de um arquivo entregue para um trabalho
+const tamanhoMaximoEntrega = 20 << 20
+
+// DefinirJanelaEntrega cadastra ou substitui o período de entrega de um trabalho
+//
+// A janela só pode ser definida em avaliações do tipo trabalho e com o semestre da disciplina aberto. Entregas já
+// realizadas mantêm a marcação de atraso calculada no momento do envio
+//
+// Retorna a janela cadastrada ou erro caso a avaliação não aceite entregas ou a persistência falhe
+func DefinirJanelaEntrega(disciplinaId string, avaliacaoId string, janela models.JanelaEntrega) (*models.JanelaEntrega, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if avaliacao.Tipo != models.AvaliacaoTrabalho {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Apenas trabalhos recebem entregas", nil)
+	}
+
+	if restErr := verificaAvaliacaoAlteravel(disciplina, avaliacao.Tipo); restErr != nil {
+		return nil, restErr
+	}
+
+	existente, restErr := buscaJanelaEntrega(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if existente != nil {
+		existente.Inicio = janela.Inicio
+		existente.Fim = janela.Fim
+		existente.AtrasoMaximoDias = janela.AtrasoMaximoDias
+		existente.PenalidadeAtraso = janela.PenalidadeAtraso
+		if err := database.DB.Omit(clause.Associations).Save(existente).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar janela de entrega", err)
+		}
+		return existente, nil
+	}
+
+	janela.AvaliacaoId = avaliacaoId
+
+	if err := database.DB.Create(&janela).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar janela de entrega", err)
+	}
+
+	return &janela, nil
+}
+
+// EnviarEntrega registra a entrega de um arquivo por um aluno para um trabalho
+//
+// O aluno deve estar matriculado e ativo na disciplina, que deve estar com o semestre aberto, e a entrega deve ocorrer
+// dentro da janela do trabalho ou do período de atraso permitido. Cada envio gera uma nova versão, que passa a ser a
+// entrega atual do aluno. O arquivo é gravado no armazenamento de arquivos antes do registro
+//
+// Retorna a entrega registrada ou erro em caso de falha de validação ou persistência
+func EnviarEntrega(avaliacaoId string, alunoId string, arquivo *multipart.FileHeader) (*models.Entrega, *utils.RestErr) {
+	var avaliacao models.Avaliacao
+	if err := database.DB.Where("id = ?", avaliacaoId).First(&avaliacao).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "Avaliação não encontrada", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliação", err)
+	}
+
+	janela, restErr := buscaJanelaEntrega(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if janela == nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A avaliação não recebe entregas", nil)
+	}
+
+	disciplina, restErr := buscaDisciplina(avaliacao.DisciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	aluno, restErr := buscaAluno(alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	matriculado, restErr := alunoMatriculado(disciplina.Id, alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !matriculado {
+		return nil, utils.NewRestErr(http.StatusForbidden, "Aluno não está matriculado na disciplina", nil)
+	}
+	if !aluno.Ativo {
+		return nil, utils.NewRestErr(http.StatusForbidden, "Aluno com matrícula trancada", nil)
+	}
+
+	agora := time.Now()
+	if agora.Before(janela.Inicio) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O período de entrega ainda não começou", nil)
+	}
+
+	diasAtraso := janela.DiasAtraso(agora)
+	if diasAtraso > janela.AtrasoMaximoDias {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O período de entrega foi encerrado", nil)
+	}
+
+	tipo, restErr := validaArquivoEntrega(arquivo)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	chave := "entregas/" + avaliacaoId + "/" + alunoId + "/" + uuid.New().String() + strings.ToLower(filepath.Ext(arquivo.Filename))
+	if restErr := salvaArquivo(chave, arquivo); restErr != nil {
+		return nil, restErr
+	}
+
+	entrega := models.Entrega{
+		AvaliacaoId:    avaliacaoId,
+		AlunoId:        alunoId,
+		DisciplinaId:   disciplina.Id,
+		Atual:          true,
+		Atrasada:       diasAtraso > 0,
+		DiasAtraso:     diasAtraso,
+		ArquivoNome:    filepath.Base(arquivo.Filename),
+		ArquivoTipo:    tipo,
+		ArquivoTamanho: arquivo.Size,
+		ArquivoChave:   chave,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// As entregas anteriores do aluno ficam bloqueadas até o fim da transação, para que reenvios simultâneos não
+		// calculem a mesma versão
+		var anteriores []models.Entrega
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("avaliacao_id = ? AND aluno_id = ?", avaliacaoId, alunoId).
+			Order("versao DESC").
+			Find(&anteriores).Error
+		if err != nil {
+			return err
+		}
+		entrega.Versao = 1
+		if len(anteriores) > 0 {
+			entrega.Versao = anteriores[0].Versao + 1
+		}
+
+		err = tx.Model(&models.Entrega{}).
+			Where("avaliacao_id = ? AND aluno_id = ? AND atual = true", avaliacaoId, alunoId).
+			Update("atual", false).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Omit(clause.Associations).Create(&entrega).Error
+	})
+	if err != nil {
+		_ = storage.Arquivos.Remover(chave)
+		return nil, erroGravacaoEntrega(err)
+	}
+
+	return &entrega, nil
+}
+
+// erroGravacaoEntrega converte o erro ao registrar uma entrega, tratando como conflito outra entrega gravada ao mesmo
+// tempo
+//
+// Na primeira entrega do aluno não há linhas para bloquear; se duas forem enviadas ao mesmo tempo, os índices únicos
+// idx_entrega_versao e idx_entrega_atual recusam a segunda
+func erroGravacaoEntrega(err error) *utils.RestErr {
+	var erroPg *pgconn.PgError
+	if errors.As(err, &erroPg) && erroPg.Code == "23505" &&
+		(erroPg.ConstraintName == "idx_entrega_versao" || erroPg.ConstraintName == "idx_entrega_atual") {
+		return utils.NewRestErr(http.StatusConflict, "Outra entrega do aluno foi registrada ao mesmo tempo, tente novamente", err)
+	}
+	return utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar entrega", err)
+}
+
+// ListarEntregasAluno retorna todas as versões entregues por um aluno para um trabalho, da mais recente para a mais antiga
+func ListarEntregasAluno(avaliacaoId string, alunoId string) ([]models.Entrega, *utils.RestErr) {
+	var entregas []models.Entrega
+	err := database.DB.Where("avaliacao_id = ? AND aluno_id = ?", avaliacaoId, alunoId).Order("versao DESC").Find(&entregas).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar entregas", err)
+	}
+
+	return entregas, nil
+}
+
+// ListarEntregasAvaliacao retorna as entregas de um trabalho com os dados de cada aluno
+//
+// Por padrão, retorna apenas a entrega atual de cada aluno; com `historico`, inclui também as versões anteriores
+func ListarEntregasAvaliacao(disciplinaId string, avaliacaoId string, historico bool) ([]models.Entrega, *utils.RestErr) {
+	if _, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	query := database.DB.Preload("Aluno").Where("avaliacao_id = ?", avaliacaoId)
+	if !historico {
+		query = query.Where("atual = true")
+	}
+
+	var entregas []models.Entrega
+	if err := query.Order("aluno_id, versao DESC").Find(&entregas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar entregas", err)
+	}
+
+	return entregas, nil
+}
+
+// CompactaEntregas grava em `destino` um arquivo zip com os arquivos das entregas informadas
+//
+// Cada aluno recebe uma pasta com seu nome e ID, e cada arquivo é prefixado pela versão e, se for o caso, pela
+// marcação de atraso. Como o zip é gravado enquanto é gerado, um erro pode ocorrer depois de parte do conteúdo já ter
+// sido enviada
+func CompactaEntregas(destino io.Writer, entregas []models.Entrega) error {
+	arquivoZip := zip.NewWriter(destino)
+
+	for _, entrega := range entregas {
+		pasta := entrega.AlunoId
+		if entrega.Aluno != nil {
+			pasta = nomeArquivoSeguro(entrega.Aluno.Nome) + "_" + entrega.AlunoId
+		}
+
+		nome := fmt.Sprintf("v%d_%s", entrega.Versao, nomeArquivoSeguro(entrega.ArquivoNome))
+		if entrega.Atrasada {
+			nome = fmt.Sprintf("v%d_atraso-%dd_%s", entrega.Versao, entrega.DiasAtraso, nomeArquivoSeguro(entrega.ArquivoNome))
+		}
+
+		if err := copiaEntrega(arquivoZip, pasta+"/"+nome, entrega); err != nil {
+			return err
+		}
+	}
+
+	return arquivoZip.Close()
+}
+
+// copiaEntrega adiciona ao zip o arquivo de uma entrega, lido do armazenamento de arquivos
+func copiaEntrega(arquivoZip *zip.Writer, nome string, entrega models.Entrega) error {
+	conteudo, err := storage.Arquivos.Abrir(entrega.ArquivoChave)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir entrega %s: %w", entrega.Id, err)
+	}
+	defer conteudo.Close()
+
+	destino, err := arquivoZip.CreateHeader(&zip.FileHeader{Name: nome, Method: zip.Deflate, Modified: entrega.CreatedAt})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(destino, conteudo)
+	return err
+}
+
+// penalidadesAtraso retorna o percentual de penalidade por atraso de cada aluno em uma avaliação
+//
+// Considera apenas a entrega atual de cada aluno. Alunos sem entrega atrasada, ou avaliações sem janela de entrega ou
+// sem penalidade configurada, não aparecem no mapa
+func penalidadesAtraso(avaliacaoId string) (map[string]float64, *utils.RestErr) {
+	janela, restErr := buscaJanelaEntrega(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	penalidades := make(map[string]float64)
+	if janela == nil || janela.PenalidadeAtraso == 0 {
+		return penalidades, nil
+	}
+
+	var atrasadas []models.Entrega
+	err := database.DB.Where("avaliacao_id = ? AND atual = true AND atrasada = true", avaliacaoId).Find(&atrasadas).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar entregas atrasadas", err)
+	}
+
+	for _, entrega := range atrasadas {
+		penalidades[entrega.AlunoId] = janela.Penalidade(entrega.DiasAtraso)
+	}
+	return penalidades, nil
+}
+
+// aplicaPenalidade desconta da nota o percentual de penalidade por atraso e converte o resultado para a escala da
+// disciplina
+func aplicaPenalidade(escala string, nota *models.AlunoAvaliacao, penalidade float64) {
+	nota.Penalidade = penalidade
+	if penalidade <= 0 {
+		return
+	}
+
+	nota.Nota = math.Round(nota.Nota*(100-penalidade)) / 100
+	nota.NotaEscala = converteMedia(escala, nota.Nota)
+}
+
+// buscaJanelaEntrega retorna a janela de entrega de uma avaliação ou nil, se ela não tiver uma
+func buscaJanelaEntrega(avaliacaoId string) (*models.JanelaEntrega, *utils.RestErr) {
+	var janela models.JanelaEntrega
+	err := database.DB.Where("avaliacao_id = ?", avaliacaoId).Limit(1).Find(&janela).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar janela de entrega", err)
+	}
+
+	if janela.Id == "" {
+		return nil, nil
+	}
+	return &janela, nil
+}
+
+// validaArquivoEntrega verifica se o arquivo de uma entrega foi enviado e respeita o tamanho máximo
+//
+// Retorna o tipo de conteúdo detectado a partir dos primeiros bytes do arquivo
+func validaArquivoEntrega(arquivo *multipart.FileHeader) (string, *utils.RestErr) {
+	if arquivo == nil {
+		return "", utils.NewRestErr(http.StatusBadRequest, "O arquivo da entrega é obrigatório", nil)
+	}
+
+	if arquivo.Size > tamanhoMaximoEntrega {
+		return "", utils.NewRestErr(http.StatusBadRequest, "O arquivo deve ter no máximo 20 MB", nil)
+	}
+
+	conteudo, err := arquivo.Open()
+	if err != nil {
+		return "", utils.NewRestErr(http.StatusBadRequest, "Erro ao ler arquivo", err)
+	}
+	defer conteudo.Close()
+
+	cabecalho := make([]byte, 512)
+	n, err := io.ReadFull(conteudo, cabecalho)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", utils.NewRestErr(http.StatusBadRequest, "Erro ao ler arquivo", err)
+	}
+
+	return http.DetectContentType(cabecalho[:n]), nil
+}
+
+// nomeArquivoSeguro remove de um nome os caracteres que não podem compor o nome de um arquivo ou pasta no zip
+func nomeArquivoSeguro(nome string) string {
+	nome = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(nome))
+
+	if nome == "" || nome == "." || nome == ".." {
+		return "arquivo"
+	}
+	return nome
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"math"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"strconv"
+	"strings"
+)
+
+// motivoNotaForaEscala é o motivo de rejeição de notas que não pertencem à escala da disciplina
+const motivoNotaForaEscala = "nota inválida para a escala da disciplina"
+
+// GetEscalaDisciplina retorna a escala de notas de uma disciplina com sua tabela de conversão
+func GetEscalaDisciplina(disciplinaId string) (*models.TabelaEscala, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	tabela := tabelaEscala(disciplina.Escala)
+	return &tabela, nil
+}
+
+// tabelaEscala retorna a tabela de conversão da escala informada
+//
+// Disciplinas cadastradas antes da configuração de escalas usam a escala de 0 a 10
+func tabelaEscala(escala string) models.TabelaEscala {
+	if tabela, ok := models.TabelasEscala[escala]; ok {
+		return tabela
+	}
+	return models.TabelasEscala[models.EscalaDecimal]
+}
+
+// converteNota converte uma nota lançada na escala da disciplina para a escala interna de 0 a 10
+//
+// Em escalas numéricas, a nota é lida de `notaEscala`, se informada, ou de `nota`. Em escalas de letras e conceitos,
+// `notaEscala` deve conter um dos valores da tabela, sem diferenciar maiúsculas de minúsculas.
+//
+// Retorna a nota interna, o valor normalizado na escala da disciplina e false se a nota não for informada ou não
+// pertencer à escala
+func converteNota(escala string, nota *float64, notaEscala string) (float64, string, bool) {
+	tabela := tabelaEscala(escala)
+	notaEscala = strings.TrimSpace(notaEscala)
+
+	if len(tabela.Faixas) == 0 {
+		var valor float64
+		switch {
+		case notaEscala != "":
+			lido, err := strconv.ParseFloat(strings.Replace(notaEscala, ",", ".", 1), 64)
+			if err != nil {
+				return 0, "", false
+			}
+			valor = lido
+		case nota != nil:
+			valor = *nota
+		default:
+			return 0, "", false
+		}
+
+		// ParseFloat aceita "NaN" e "Inf", que não são notas
+		if math.IsNaN(valor) || math.IsInf(valor, 0) || valor < 0 || valor > 10*tabela.Fator {
+			return 0, "", false
+		}
+		return valor / tabela.Fator, formataNota(valor), true
+	}
+
+	for _, faixa := range tabela.Faixas {
+		if strings.EqualFold(faixa.Valor, notaEscala) {
+			return faixa.NotaEquivalente, faixa.Valor, true
+		}
+	}
+	return 0, "", false
+}
+
+// converteMedia converte uma nota ou média da escala interna de 0 a 10 para a escala da disciplina
+func converteMedia(escala string, media float64) string {
+	tabela := tabelaEscala(escala)
+
+	if len(tabela.Faixas) == 0 {
+		return formataNota(media * tabela.Fator)
+	}
+
+	for _, faixa := range tabela.Faixas {
+		if media >= faixa.NotaMinima-1e-9 {
+			return faixa.Valor
+		}
+	}
+	return tabela.Faixas[len(tabela.Faixas)-1].Valor
+}
+
+// formataNota formata uma nota numérica com no máximo duas casas decimais
+func formataNota(nota float64) string {
+	return strconv.FormatFloat(math.Round(nota*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"math"
+	"sistema-alunos-go/models"
+	"testing"
+)
+
+// valor retorna o endereço de uma nota, para os casos em que ela é informada em `nota`
+func valor(nota float64) *float64 {
+	return &nota
+}
+
+func TestConverteNota(t *testing.T) {
+	casos := []struct {
+		escala      string
+		nota        *float64
+		notaEscala  string
+		interna     float64
+		normalizada string
+		valida      bool
+	}{
+		// Escala de 0 a 10
+		{models.EscalaDecimal, valor(7.5), "", 7.5, "7.5", true},
+		{models.EscalaDecimal, valor(10), "", 10, "10", true},
+		{models.EscalaDecimal, nil, "0", 0, "0", true},
+		{models.EscalaDecimal, nil, " 8,25 ", 8.25, "8.25", true},
+		{models.EscalaDecimal, valor(3), "6", 6, "6", true},
+		{models.EscalaDecimal, valor(0), "", 0, "0", true},
+		{models.EscalaDecimal, nil, "", 0, "", false},
+		{models.EscalaDecimal, nil, "   ", 0, "", false},
+		{models.EscalaDecimal, valor(10.5), "", 0, "", false},
+		{models.EscalaDecimal, valor(-1), "", 0, "", false},
+		{models.EscalaDecimal, nil, "sete", 0, "", false},
+		{models.EscalaDecimal, nil, "NaN", 0, "", false},
+		{models.EscalaDecimal, nil, "Inf", 0, "", false},
+		{models.EscalaDecimal, nil, "+Inf", 0, "", false},
+		{models.EscalaDecimal, nil, "-Inf", 0, "", false},
+		{models.EscalaDecimal, valor(math.NaN()), "", 0, "", false},
+		{models.EscalaDecimal, valor(math.Inf(1)), "", 0, "", false},
+
+		// Escala de 0 a 100
+		{models.EscalaCentesimal, valor(75), "", 7.5, "75", true},
+		{models.EscalaCentesimal, valor(100), "", 10, "100", true},
+		{models.EscalaCentesimal, nil, "0", 0, "0", true},
+		{models.EscalaCentesimal, nil, "", 0, "", false},
+		{models.EscalaCentesimal, valor(100.5), "", 0, "", false},
+		{models.EscalaCentesimal, nil, "infinity", 0, "", false},
+
+		// Letras
+		{models.EscalaLetras, nil, "A", 9.5, "A", true},
+		{models.EscalaLetras, nil, " c ", 7.5, "C", true},
+		{models.EscalaLetras, nil, "F", 2.5, "F", true},
+		{models.EscalaLetras, valor(9), "", 0, "", false},
+		{models.EscalaLetras, nil, "", 0, "", false},
+		{models.EscalaLetras, nil, "G", 0, "", false},
+
+		// Conceitos
+		{models.EscalaConceitos, nil, "MB", 9.5, "MB", true},
+		{models.EscalaConceitos, nil, "r", 6, "R", true},
+		{models.EscalaConceitos, nil, "I", 2.5, "I", true},
+		{models.EscalaConceitos, nil, "", 0, "", false},
+		{models.EscalaConceitos, nil, "A", 0, "", false},
+
+		// Disciplinas sem escala configurada usam a escala de 0 a 10
+		{"", valor(6), "", 6, "6", true},
+		{"", nil, "", 0, "", false},
+	}
+
+	for _, caso := range casos {
+		interna, normalizada, valida := converteNota(caso.escala, caso.nota, caso.notaEscala)
+		if valida != caso.valida || normalizada != caso.normalizada || math.Abs(interna-caso.interna) > 1e-9 {
+			informada := "nula"
+			if caso.nota != nil {
+				informada = formataNota(*caso.nota)
+			}
+			t.Errorf("converteNota(%q, %s, %q) = %v, %q, %v; esperado %v, %q, %v", caso.escala, informada,
+				caso.notaEscala, interna, normalizada, valida, caso.interna, caso.normalizada, caso.valida)
+		}
+	}
+}
+
+func TestConverteMedia(t *testing.T) {
+	casos := []struct {
+		escala   string
+		media    float64
+		esperada string
+	}{
+		{models.EscalaDecimal, 7.456, "7.46"},
+		{models.EscalaDecimal, 0, "0"},
+		{models.EscalaDecimal, 10, "10"},
+		{models.EscalaCentesimal, 7.456, "74.56"},
+		{models.EscalaCentesimal, 10, "100"},
+
+		{models.EscalaLetras, 10, "A"},
+		{models.EscalaLetras, 9, "A"},
+		{models.EscalaLetras, 8.999999999999, "A"},
+		{models.EscalaLetras, 8.99, "B"},
+		{models.EscalaLetras, 7, "C"},
+		{models.EscalaLetras, 6.5, "D"},
+		{models.EscalaLetras, 5, "E"},
+		{models.EscalaLetras, 4.99, "F"},
+		{models.EscalaLetras, 0, "F"},
+
+		{models.EscalaConceitos, 9.5, "MB"},
+		{models.EscalaConceitos, 8.99, "B"},
+		{models.EscalaConceitos, 7, "B"},
+		{models.EscalaConceitos, 6.99, "R"},
+		{models.EscalaConceitos, 5, "R"},
+		{models.EscalaConceitos, 4.99, "I"},
+		{models.EscalaConceitos, 0, "I"},
+
+		{"", 5.5, "5.5"},
+	}
+
+	for _, caso := range casos {
+		if obtida := converteMedia(caso.escala, caso.media); obtida != caso.esperada {
+			t.Errorf("converteMedia(%q, %v) = %q, esperado %q", caso.escala, caso.media, obtida, caso.esperada)
+		}
+	}
+}
+
+func TestConverteNotaIdaEVolta(t *testing.T) {
+	for escala, tabela := range models.TabelasEscala {
+		for _, faixa := range tabela.Faixas {
+			interna, _, valida := converteNota(escala, nil, faixa.Valor)
+			if !valida {
+				t.Errorf("converteNota(%q, nula, %q) rejeitou um valor da tabela", escala, faixa.Valor)
+				continue
+			}
+			if media := converteMedia(escala, interna); media != faixa.Valor {
+				t.Errorf("converteMedia(%q, %v) = %q, esperado %q", escala, interna, media, faixa.Valor)
+			}
+		}
+	}
+}
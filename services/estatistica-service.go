@@ -0,0 +1,159 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// faixasHistograma é a quantidade de faixas de mesma largura dos histogramas de distribuição
+const faixasHistograma = 10
+
+// GetEstatisticasAvaliacao calcula as estatísticas das notas lançadas em uma avaliação
+//
+// Retorna média, mediana, desvio padrão, mínima, máxima, quartis e histograma das notas, na escala interna de 0 a 10
+func GetEstatisticasAvaliacao(disciplinaId string, avaliacaoId string) (*models.EstatisticasAvaliacao, *utils.RestErr) {
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	notas, restErr := estatisticasColuna("aluno_avaliacao", "nota", 10, "avaliacao_id = ?", avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	return &models.EstatisticasAvaliacao{
+		AvaliacaoId: avaliacao.Id,
+		Nome:        avaliacao.Nome,
+		Tipo:        avaliacao.Tipo,
+		Notas:       *notas,
+	}, nil
+}
+
+// GetEstatisticasDisciplina calcula as estatísticas dos resultados finais de uma disciplina
+//
+// Inclui as estatísticas das médias finais e das frequências, as taxas de aprovação e reprovação e a distribuição dos
+// alunos por situação final. Enquanto o semestre não for fechado, não há resultados e as estatísticas ficam vazias
+func GetEstatisticasDisciplina(disciplinaId string) (*models.EstatisticasDisciplina, *utils.RestErr) {
+	if _, restErr := buscaDisciplina(disciplinaId); restErr != nil {
+		return nil, restErr
+	}
+
+	medias, restErr := estatisticasColuna("aluno_media", "media_final", 10, "disciplina_id = ?", disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	frequencias, restErr := estatisticasColuna("aluno_media", "frequencia", 100, "disciplina_id = ?", disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	estatisticas := &models.EstatisticasDisciplina{
+		DisciplinaId: disciplinaId,
+		Medias:       *medias,
+		Frequencias:  *frequencias,
+		Situacoes:    []models.TaxaSituacao{},
+	}
+
+	err := database.DB.Table("aluno_media").
+		Select("COALESCE(AVG(CASE WHEN aprovado THEN 100.0 ELSE 0 END), 0) AS taxa_aprovacao, "+
+			"COALESCE(AVG(CASE WHEN situacao IN (?, ?, ?) THEN 100.0 ELSE 0 END), 0) AS taxa_reprovacao",
+			models.SituacaoReprovadoNota, models.SituacaoReprovadoFalta, models.SituacaoReprovadoNotaFalta).
+		Where("disciplina_id = ?", disciplinaId).
+		Scan(estatisticas).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao calcular taxas de aprovação", err)
+	}
+
+	err = database.DB.Table("aluno_media").
+		Select("situacao, COUNT(*) AS quantidade, COUNT(*) * 100.0 / SUM(COUNT(*)) OVER () AS percentual").
+		Where("disciplina_id = ?", disciplinaId).
+		Group("situacao").
+		Order("quantidade DESC, situacao").
+		Scan(&estatisticas.Situacoes).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao calcular situações dos alunos", err)
+	}
+
+	return estatisticas, nil
+}
+
+// estatisticasColuna calcula as estatísticas descritivas de uma coluna numérica
+//
+// `tabela` e `coluna` devem ser nomes fixos definidos no código, nunca valores recebidos na requisição. As medidas são
+// calculadas no banco de dados; o histograma é montado por montaHistograma a partir da quantidade de cada valor
+func estatisticasColuna(tabela string, coluna string, maximo float64, condicao string, args ...interface{}) (*models.EstatisticasNotas, *utils.RestErr) {
+	var estatisticas models.EstatisticasNotas
+	err := database.DB.Table(tabela).
+		Select(fmt.Sprintf(`COUNT(%[1]s) AS quantidade,
+			AVG(%[1]s) AS media,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY %[1]s) AS mediana,
+			stddev_pop(%[1]s) AS desvio_padrao,
+			MIN(%[1]s) AS minima,
+			MAX(%[1]s) AS maxima,
+			percentile_cont(0.25) WITHIN GROUP (ORDER BY %[1]s) AS primeiro_quartil,
+			percentile_cont(0.75) WITHIN GROUP (ORDER BY %[1]s) AS terceiro_quartil`, coluna)).
+		Where(condicao, args...).
+		Scan(&estatisticas).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao calcular estatísticas", err)
+	}
+
+	var contagens []contagemValor
+	err = database.DB.Table(tabela).
+		Select(fmt.Sprintf("%[1]s AS valor, COUNT(*) AS quantidade", coluna)).
+		Where(condicao, args...).
+		Where(fmt.Sprintf("%s IS NOT NULL", coluna)).
+		Group("valor").
+		Scan(&contagens).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao calcular histograma", err)
+	}
+
+	estatisticas.Histograma = montaHistograma(maximo, contagens)
+	return &estatisticas, nil
+}
+
+// contagemValor é a quantidade de registros com um mesmo valor na coluna das estatísticas
+type contagemValor struct {
+	Valor      float64
+	Quantidade int64
+}
+
+// montaHistograma distribui as contagens de cada valor nas faixas de mesma largura do intervalo de 0 a `maximo`
+//
+// Todas as faixas são retornadas, mesmo sem valores; sem contagens, o histograma fica com todas as quantidades zeradas
+func montaHistograma(maximo float64, contagens []contagemValor) []models.FaixaHistograma {
+	largura := maximo / faixasHistograma
+	histograma := make([]models.FaixaHistograma, faixasHistograma)
+	for i := range histograma {
+		histograma[i] = models.FaixaHistograma{Inicio: float64(i) * largura, Fim: float64(i+1) * largura}
+	}
+
+	for _, c := range contagens {
+		if faixa := faixaHistograma(c.Valor, maximo); faixa >= 0 {
+			histograma[faixa].Quantidade += c.Quantidade
+		}
+	}
+	return histograma
+}
+
+// faixaHistograma retorna o índice, a partir de zero, da faixa do histograma de 0 a `maximo` em que o valor se encaixa
+//
+// Segue a regra do width_bucket do PostgreSQL, com o início da faixa incluído e o fim excluído, exceto que valores
+// iguais ou maiores que `maximo` entram na última faixa. Retorna -1 para valores negativos ou NaN
+func faixaHistograma(valor float64, maximo float64) int {
+	switch {
+	case !(valor >= 0):
+		return -1
+	case valor >= maximo:
+		return faixasHistograma - 1
+	default:
+		return int(math.Floor(valor * faixasHistograma / maximo))
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFaixaHistograma(t *testing.T) {
+	casos := []struct {
+		valor  float64
+		maximo float64
+		faixa  int
+	}{
+		{0, 10, 0},
+		{0.99, 10, 0},
+		{1, 10, 1},
+		{5.5, 10, 5},
+		{9, 10, 9},
+		{9.99, 10, 9},
+		{10, 10, 9},
+		{10.5, 10, 9},
+		{-0.5, 10, -1},
+		{math.NaN(), 10, -1},
+		{0, 100, 0},
+		{75, 100, 7},
+		{100, 100, 9},
+	}
+
+	for _, caso := range casos {
+		if faixa := faixaHistograma(caso.valor, caso.maximo); faixa != caso.faixa {
+			t.Errorf("faixaHistograma(%v, %v) = %d, esperado %d", caso.valor, caso.maximo, faixa, caso.faixa)
+		}
+	}
+}
+
+func TestMontaHistograma(t *testing.T) {
+	histograma := montaHistograma(10, []contagemValor{
+		{Valor: 0, Quantidade: 2},
+		{Valor: 0.5, Quantidade: 1},
+		{Valor: 7.25, Quantidade: 3},
+		{Valor: 9.5, Quantidade: 1},
+		{Valor: 10, Quantidade: 4},
+	})
+
+	esperadas := []int64{3, 0, 0, 0, 0, 0, 0, 3, 0, 5}
+	if len(histograma) != len(esperadas) {
+		t.Fatalf("histograma com %d faixas, esperado %d", len(histograma), len(esperadas))
+	}
+	for i, faixa := range histograma {
+		if faixa.Quantidade != esperadas[i] {
+			t.Errorf("faixa %d: quantidade = %d, esperado %d", i, faixa.Quantidade, esperadas[i])
+		}
+	}
+	if ultima := histograma[len(histograma)-1]; ultima.Inicio != 9 || ultima.Fim != 10 {
+		t.Errorf("última faixa = [%v, %v), esperado [9, 10]", ultima.Inicio, ultima.Fim)
+	}
+}
+
+func TestMontaHistogramaVazio(t *testing.T) {
+	histograma := montaHistograma(100, nil)
+	if len(histograma) != faixasHistograma {
+		t.Fatalf("histograma com %d faixas, esperado %d", len(histograma), faixasHistograma)
+	}
+	for i, faixa := range histograma {
+		if faixa.Quantidade != 0 {
+			t.Errorf("faixa %d: quantidade = %d, esperado 0", i, faixa.Quantidade)
+		}
+		if faixa.Inicio != float64(i)*10 || faixa.Fim != float64(i+1)*10 {
+			t.Errorf("faixa %d = [%v, %v), esperado [%d, %d)", i, faixa.Inicio, faixa.Fim, i*10, (i+1)*10)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// calculaFrequencia calcula a frequência percentual de um aluno em uma disciplina
+//
+// No modo "horas", soma as horas presentes do aluno nas aulas informadas e divide pela carga horária realizada da
+// disciplina. No modo "aulas", conta as aulas em que o aluno esteve presente (mesmo que parcialmente) e divide pelo
+// número de aulas. Aulas com faltas abonadas por justificativas aprovadas contam como assistidas integralmente
+//
+// Retorna a frequência entre 0 e 100 ou erro caso a consulta falhe
+func calculaFrequencia(disciplina *models.Disciplina, aulas []models.Aula, alunoId string) (float64, *utils.RestErr) {
+	assistido, restErr := contabilizaPresenca(disciplina, aulas, alunoId)
+	if restErr != nil {
+		return 0, restErr
+	}
+
+	total := totalFrequencia(disciplina, aulas)
+	if total == 0 {
+		return 0, nil
+	}
+
+	return float64(assistido) / float64(total) * 100, nil
+}
+
+// contabilizaPresenca retorna quanto das aulas informadas o aluno assistiu, na unidade do modo de frequência da
+// disciplina: horas no modo "horas" e número de aulas no modo "aulas"
+//
+// Aulas com faltas abonadas por justificativas aprovadas contam como assistidas integralmente
+func contabilizaPresenca(disciplina *models.Disciplina, aulas []models.Aula, alunoId string) (int, *utils.RestErr) {
+	if len(aulas) == 0 {
+		return 0, nil
+	}
+
+	aulaIds := extractAulaIds(aulas)
+
+	var presencas []models.AlunoAula
+	err := database.DB.Where("aluno_id = ? AND aula_id IN (?)", alunoId, aulaIds).Find(&presencas).Error
+	if err != nil {
+		return 0, utils.NewRestErr(http.StatusInternalServerError, "Erro ao calcular frequência", err)
+	}
+
+	abonadas, restErr := aulasAbonadas(alunoId, aulaIds)
+	if restErr != nil {
+		return 0, restErr
+	}
+
+	presencaPorAula := make(map[string]models.AlunoAula, len(presencas))
+	for _, p := range presencas {
+		presencaPorAula[p.AulaId] = p
+	}
+
+	var assistido int
+	for _, aula := range aulas {
+		presenca, registrada := presencaPorAula[aula.Id]
+		switch {
+		case disciplina.ModoFrequencia == models.FrequenciaPorAulas:
+			if abonadas[aula.Id] || presenca.Presenca {
+				assistido++
+			}
+		case abonadas[aula.Id]:
+			assistido += aula.QuantidadeHoras
+		case registrada && presenca.Presenca:
+			assistido += presenca.HorasPresentes
+		}
+	}
+
+	return assistido, nil
+}
+
+// totalFrequencia retorna a base do cálculo de frequência de uma disciplina: a carga horária realizada no modo
+// "horas" ou o número de aulas no modo "aulas"
+func totalFrequencia(disciplina *models.Disciplina, aulas []models.Aula) int {
+	if disciplina.ModoFrequencia == models.FrequenciaPorAulas {
+		return len(aulas)
+	}
+	return disciplina.CargaHorariaRealizada
+}
@@ -0,0 +1,557 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"mime/multipart"
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/planilhas"
+	"sistema-alunos-go/utils"
+	"strconv"
+)
+
+// tamanhoMaximoPlanilha é o tamanho máximo, em bytes, das planilhas enviadas para importação
+const tamanhoMaximoPlanilha = 5 << 20
+
+// colunasAluno são os títulos aceitos para cada coluna da planilha de importação de alunos
+var colunasAluno = map[string][]string{
+	"nome":       {"nome", "aluno"},
+	"email":      {"email", "e-mail"},
+	"disciplina": {"disciplina", "disciplina_id"},
+}
+
+// colunasNota são os títulos aceitos para cada coluna da planilha de notas de uma avaliação
+var colunasNota = map[string][]string{
+	"matricula": {"matrícula", "matricula", "aluno_id"},
+	"email":     {"email", "e-mail"},
+	"nota":      {"nota"},
+}
+
+// ImportarAlunos importa alunos a partir de uma planilha CSV ou XLSX com as colunas nome, email e, opcionalmente,
+// disciplina (o ID da disciplina em que o aluno deve ser matriculado)
+//
+// Cada linha é validada com as mesmas regras do cadastro de alunos e cada e-mail deve aparecer em apenas uma linha. Alunos com e-mail já cadastrado têm o nome
+// atualizado; os demais são criados ativos. Sem `aplicar`, nada é gravado e o resultado é a prévia das alterações,
+// com os erros de cada linha. Com `aplicar`, todas as linhas são gravadas em uma única transação, e a importação é
+// recusada se alguma linha for inválida
+//
+// Retorna o resultado da importação ou erro caso a planilha seja inválida ou a gravação falhe
+func ImportarAlunos(arquivo *multipart.FileHeader, aplicar bool) (*models.ResultadoImportacaoAlunos, *utils.RestErr) {
+	linhas, restErr := lePlanilhaEnviada(arquivo)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	colunas := planilhas.Colunas(linhas[0], colunasAluno)
+	_, temNome := colunas["nome"]
+	_, temEmail := colunas["email"]
+	if !temNome || !temEmail {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha deve ter as colunas nome e email", nil)
+	}
+
+	resultado, restErr := previaImportacaoAlunos(linhas, colunas)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !aplicar {
+		return resultado, nil
+	}
+
+	if resultado.Invalidas > 0 {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha possui linhas inválidas; nenhuma alteração foi aplicada", nil, resultado.Linhas)
+	}
+
+	err := transacaoEventos(func(tx *gorm.DB) error {
+		if restErr = aplicaImportacaoAlunos(tx, resultado); restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao importar alunos", err)
+	}
+
+	resultado.Aplicada = true
+	return resultado, nil
+}
+
+// previaImportacaoAlunos valida as linhas da planilha de alunos e define a ação de cada uma, sem gravar nada
+//
+// Busca em lotes os alunos já cadastrados com os e-mails da planilha, as disciplinas informadas e as matrículas
+// existentes, para não consultar o banco a cada linha
+func previaImportacaoAlunos(linhas [][]string, colunas map[string]int) (*models.ResultadoImportacaoAlunos, *utils.RestErr) {
+	resultado := &models.ResultadoImportacaoAlunos{Linhas: []models.LinhaImportacaoAluno{}}
+
+	emails := make(map[string]bool)
+	disciplinaIds := make(map[string]bool)
+	for _, linha := range linhas[1:] {
+		emails[planilhas.Valor(linha, colunas, "email")] = true
+		if disciplinaId := planilhas.Valor(linha, colunas, "disciplina"); disciplinaId != "" {
+			disciplinaIds[disciplinaId] = true
+		}
+	}
+
+	alunoPorEmail := make(map[string]models.Aluno, len(emails))
+	alunoIds := make([]string, 0, len(emails))
+	for _, lote := range lotes(chaves(emails), tamanhoConsultaBackup) {
+		var existentes []models.Aluno
+		if err := database.DB.Where("email IN (?)", lote).Find(&existentes).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos cadastrados", err)
+		}
+		for _, aluno := range existentes {
+			alunoPorEmail[aluno.Email] = aluno
+			alunoIds = append(alunoIds, aluno.Id)
+		}
+	}
+
+	disciplinas := make(map[string]bool)
+	matriculas := make(map[string]bool)
+	if len(disciplinaIds) > 0 {
+		var restErr *utils.RestErr
+		disciplinas, restErr = idsCadastrados(database.DB, &models.Disciplina{}, "id", chaves(disciplinaIds))
+		if restErr != nil {
+			return nil, restErr
+		}
+
+		// As matrículas são buscadas por aluno e filtradas pelas disciplinas da planilha
+		for _, lote := range lotes(alunoIds, tamanhoConsultaBackup) {
+			var vinculos []models.AlunoDisciplina
+			if err := database.DB.Where("aluno_id IN (?)", lote).Find(&vinculos).Error; err != nil {
+				return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar matrículas", err)
+			}
+			for _, v := range vinculos {
+				if disciplinaIds[v.DisciplinaId] {
+					matriculas[v.AlunoId+"/"+v.DisciplinaId] = true
+				}
+			}
+		}
+	}
+
+	linhaPorEmail := make(map[string]int)
+	for i, valores := range linhas[1:] {
+		if planilhas.Vazia(valores) {
+			continue
+		}
+
+		linha := models.LinhaImportacaoAluno{
+			Linha:        i + 2,
+			Nome:         planilhas.Valor(valores, colunas, "nome"),
+			Email:        planilhas.Valor(valores, colunas, "email"),
+			DisciplinaId: planilhas.Valor(valores, colunas, "disciplina"),
+		}
+
+		linha.Erros = utils.ValidateStruct(&models.Aluno{Nome: linha.Nome, Email: linha.Email})
+		if anterior, repetido := linhaPorEmail[linha.Email]; repetido && linha.Email != "" {
+			linha.Erros = append(linha.Erros, utils.ValidationError{
+				Path:    "Email",
+				Message: "O e-mail já aparece na linha " + strconv.Itoa(anterior),
+			})
+		} else {
+			linhaPorEmail[linha.Email] = linha.Linha
+		}
+		if linha.DisciplinaId != "" && !disciplinas[linha.DisciplinaId] {
+			linha.Erros = append(linha.Erros, utils.ValidationError{
+				Path:    "Disciplina",
+				Message: "Disciplina não encontrada",
+			})
+		}
+
+		if len(linha.Erros) > 0 {
+			resultado.Invalidas++
+			resultado.Linhas = append(resultado.Linhas, linha)
+			continue
+		}
+
+		existente, cadastrado := alunoPorEmail[linha.Email]
+		switch {
+		case !cadastrado:
+			linha.Acao = models.ImportacaoCriar
+			resultado.Criados++
+		case existente.Nome != linha.Nome:
+			linha.AlunoId = existente.Id
+			linha.Acao = models.ImportacaoAtualizar
+			resultado.Atualizados++
+		default:
+			linha.AlunoId = existente.Id
+			linha.Acao = models.ImportacaoInalterado
+		}
+
+		linha.Matricular = linha.DisciplinaId != "" && !matriculas[linha.AlunoId+"/"+linha.DisciplinaId]
+		if linha.Matricular {
+			resultado.Matriculas++
+		}
+
+		resultado.Validas++
+		resultado.Linhas = append(resultado.Linhas, linha)
+	}
+
+	if resultado.Validas+resultado.Invalidas == 0 {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha não possui alunos", nil)
+	}
+
+	return resultado, nil
+}
+
+// aplicaImportacaoAlunos grava as linhas da prévia na transação informada: cria ou atualiza os alunos, cria as
+// matrículas e atualiza a quantidade de alunos das disciplinas
+//
+// Preenche o ID dos alunos criados nas linhas do resultado. Os eventos aluno.cadastrado e aluno.matriculado são
+// publicados para os webhooks a cada aluno criado e a cada matrícula
+func aplicaImportacaoAlunos(tx *gorm.DB, resultado *models.ResultadoImportacaoAlunos) *utils.RestErr {
+	novasMatriculas := make(map[string]int)
+
+	for i := range resultado.Linhas {
+		linha := &resultado.Linhas[i]
+
+		switch linha.Acao {
+		case models.ImportacaoCriar:
+			aluno := models.Aluno{Nome: linha.Nome, Email: linha.Email, Ativo: true}
+			if err := tx.Create(&aluno).Error; err != nil {
+				return utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar aluno da linha "+strconv.Itoa(linha.Linha), err)
+			}
+			linha.AlunoId = aluno.Id
+			if restErr := publicaEvento(tx, models.EventoAlunoCadastrado, aluno); restErr != nil {
+				return restErr
+			}
+		case models.ImportacaoAtualizar:
+			if err := tx.Model(&models.Aluno{}).Where("id = ?", linha.AlunoId).Update("nome", linha.Nome).Error; err != nil {
+				return utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar aluno da linha "+strconv.Itoa(linha.Linha), err)
+			}
+		}
+
+		if linha.Matricular {
+			vinculo := models.AlunoDisciplina{AlunoId: linha.AlunoId, DisciplinaId: linha.DisciplinaId}
+			if err := tx.Create(&vinculo).Error; err != nil {
+				return utils.NewRestErr(http.StatusInternalServerError, "Erro ao matricular aluno da linha "+strconv.Itoa(linha.Linha), err)
+			}
+			if restErr := publicaEvento(tx, models.EventoAlunoMatriculado, vinculo); restErr != nil {
+				return restErr
+			}
+			novasMatriculas[linha.DisciplinaId]++
+		}
+	}
+
+	for disciplinaId, quantidade := range novasMatriculas {
+		err := tx.Model(&models.Disciplina{}).Where("id = ?", disciplinaId).
+			Update("quantidade_alunos", gorm.Expr("quantidade_alunos + ?", quantidade)).Error
+		if err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar quantidade de alunos", err)
+		}
+	}
+
+	return nil
+}
+
+// PlanilhaNotasAvaliacao monta o modelo da planilha de notas de uma avaliação, com as colunas Matrícula, Aluno,
+// E-mail e Nota
+//
+// Lista os alunos ativos matriculados na disciplina, em ordem alfabética, ou apenas os alunos em recuperação, se a
+// avaliação for a de recuperação. A coluna Nota vem preenchida com as notas já lançadas, na escala da disciplina
+//
+// Retorna as linhas da planilha, com o cabeçalho na primeira, ou erro caso a disciplina ou a avaliação não existam
+func PlanilhaNotasAvaliacao(disciplinaId string, avaliacaoId string) ([][]string, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	var permitidos map[string]bool
+	if avaliacao.Recuperacao() {
+		if permitidos, restErr = alunosEmRecuperacao(disciplinaId); restErr != nil {
+			return nil, restErr
+		}
+	}
+
+	var alunos []models.Aluno
+	err := database.DB.
+		Joins("JOIN aluno_disciplina ON aluno_disciplina.aluno_id = alunos.id").
+		Where("aluno_disciplina.disciplina_id = ? AND alunos.ativo = true", disciplinaId).
+		Order("alunos.nome").
+		Find(&alunos).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos da disciplina", err)
+	}
+
+	notas, restErr := notasLancadas(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	linhas := [][]string{{"Matrícula", "Aluno", "E-mail", "Nota"}}
+	for _, aluno := range alunos {
+		if permitidos != nil && !permitidos[aluno.Id] {
+			continue
+		}
+
+		nota := ""
+		if lancada, ok := notas[aluno.Id]; ok {
+			nota = notaExibida(disciplina, &lancada)
+		}
+		linhas = append(linhas, []string{aluno.Id, aluno.Nome, aluno.Email, nota})
+	}
+
+	return linhas, nil
+}
+
+// ImportarNotasAvaliacao importa as notas de uma avaliação a partir de uma planilha CSV ou XLSX com a coluna nota e a
+// coluna matrícula (o ID do aluno) ou email, como a gerada por PlanilhaNotasAvaliacao
+//
+// Cada nota é validada como no lançamento de notas: o aluno deve estar matriculado e ativo e a nota deve pertencer à
+// escala da disciplina (de 0 a 10 na escala padrão). Linhas com a nota em branco são ignoradas. Sem `aplicar`, nada é
+// gravado e o resultado é a prévia das alterações, comparando cada nota com a já lançada, já com a penalidade por
+// atraso. Com `aplicar`, as notas novas ou alteradas são lançadas por AdicionarNotaAvaliacao, com a justificativa
+// informada, e a importação é recusada se alguma linha for inválida
+//
+// Retorna o resultado da importação ou erro caso a planilha seja inválida, as notas da avaliação não possam ser
+// alteradas ou a gravação falhe
+func ImportarNotasAvaliacao(arquivo *multipart.FileHeader, disciplinaId string, avaliacaoId string, professorId string, importacao models.ImportacaoNotas) (*models.ResultadoImportacaoNotas, *utils.RestErr) {
+	linhas, restErr := lePlanilhaEnviada(arquivo)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	colunas := planilhas.Colunas(linhas[0], colunasNota)
+	_, temMatricula := colunas["matricula"]
+	_, temEmail := colunas["email"]
+	_, temNota := colunas["nota"]
+	if !temNota || (!temMatricula && !temEmail) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha deve ter a coluna nota e a coluna matrícula ou email", nil)
+	}
+
+	resultado, notas, restErr := previaImportacaoNotas(linhas, colunas, disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !importacao.Aplicar {
+		return resultado, nil
+	}
+
+	if resultado.Invalidas > 0 {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha possui linhas inválidas; nenhuma nota foi lançada", nil, resultado.Linhas)
+	}
+
+	if len(notas) > 0 {
+		_, restErr = AdicionarNotaAvaliacao(notas, avaliacaoId, disciplinaId, professorId, importacao.Justificativa, false)
+		if restErr != nil {
+			return nil, restErr
+		}
+	}
+
+	resultado.Aplicada = true
+	return resultado, nil
+}
+
+// previaImportacaoNotas valida as linhas da planilha de notas e compara cada nota com a já lançada, sem gravar nada
+//
+// Verifica se as notas da avaliação podem ser alteradas e valida as notas com validaNotas, como no lançamento de
+// notas. Retorna também as notas das linhas que serão lançadas ou atualizadas, como lidas da planilha
+func previaImportacaoNotas(linhas [][]string, colunas map[string]int, disciplinaId string, avaliacaoId string) (*models.ResultadoImportacaoNotas, []models.AlunoAvaliacao, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, nil, restErr
+	}
+
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, nil, restErr
+	}
+
+	if restErr := verificaNotaAlteravel(disciplina, avaliacao); restErr != nil {
+		return nil, nil, restErr
+	}
+
+	if restErr := verificaNotaSemRubrica(avaliacaoId); restErr != nil {
+		return nil, nil, restErr
+	}
+
+	var permitidos map[string]bool
+	if avaliacao.Recuperacao() {
+		if permitidos, restErr = alunosEmRecuperacao(disciplinaId); restErr != nil {
+			return nil, nil, restErr
+		}
+	}
+
+	var matriculados []models.Aluno
+	err := database.DB.
+		Joins("JOIN aluno_disciplina ON aluno_disciplina.aluno_id = alunos.id").
+		Where("aluno_disciplina.disciplina_id = ?", disciplinaId).
+		Find(&matriculados).Error
+	if err != nil {
+		return nil, nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos da disciplina", err)
+	}
+	alunoPorId := make(map[string]models.Aluno, len(matriculados))
+	alunoPorEmail := make(map[string]models.Aluno, len(matriculados))
+	for _, aluno := range matriculados {
+		alunoPorId[aluno.Id] = aluno
+		alunoPorEmail[aluno.Email] = aluno
+	}
+
+	lancadas, restErr := notasLancadas(avaliacaoId)
+	if restErr != nil {
+		return nil, nil, restErr
+	}
+
+	penalidades, restErr := penalidadesAtraso(avaliacaoId)
+	if restErr != nil {
+		return nil, nil, restErr
+	}
+
+	resultado := &models.ResultadoImportacaoNotas{Linhas: []models.LinhaImportacaoNota{}}
+
+	// notas e posicoes guardam as linhas com nota a validar e a posição de cada uma em resultado.Linhas
+	var notas []models.AlunoAvaliacao
+	var posicoes []int
+	for i, valores := range linhas[1:] {
+		if planilhas.Vazia(valores) {
+			continue
+		}
+
+		linha := models.LinhaImportacaoNota{
+			Linha:   i + 2,
+			AlunoId: planilhas.Valor(valores, colunas, "matricula"),
+		}
+
+		email := planilhas.Valor(valores, colunas, "email")
+		if linha.AlunoId == "" && email != "" {
+			if aluno, ok := alunoPorEmail[email]; ok {
+				linha.AlunoId = aluno.Id
+			} else {
+				linha.Erros = []utils.ValidationError{{Path: "Email", Message: motivoNaoMatriculado}}
+			}
+		}
+		if linha.AlunoId == "" && linha.Erros == nil {
+			linha.Erros = []utils.ValidationError{{Path: "Matricula", Message: "A matrícula ou o e-mail do aluno é obrigatório"}}
+		}
+		linha.Nome = alunoPorId[linha.AlunoId].Nome
+
+		if lancada, ok := lancadas[linha.AlunoId]; ok {
+			linha.NotaAtual = notaExibida(disciplina, &lancada)
+		}
+
+		nota := planilhas.Valor(valores, colunas, "nota")
+		switch {
+		case linha.Erros != nil:
+			resultado.Invalidas++
+		case nota == "":
+			linha.Acao = models.ImportacaoIgnorar
+			resultado.Ignoradas++
+		default:
+			notas = append(notas, models.AlunoAvaliacao{AlunoId: linha.AlunoId, NotaEscala: nota})
+			posicoes = append(posicoes, len(resultado.Linhas))
+		}
+		resultado.Linhas = append(resultado.Linhas, linha)
+	}
+
+	if len(resultado.Linhas) == 0 {
+		return nil, nil, utils.NewRestErr(http.StatusBadRequest, "A planilha não possui notas", nil)
+	}
+
+	validas, rejeitadas, restErr := validaNotas(disciplina, notas, permitidos)
+	if restErr != nil {
+		return nil, nil, restErr
+	}
+
+	for _, rejeitada := range rejeitadas {
+		linha := &resultado.Linhas[posicoes[rejeitada.Indice]]
+		campo := "Matricula"
+		if rejeitada.Motivo == motivoNotaForaEscala {
+			campo = "Nota"
+		}
+		linha.Erros = append(linha.Erros, utils.ValidationError{Path: campo, Message: rejeitada.Motivo})
+		resultado.Invalidas++
+	}
+
+	// validaNotas preserva a ordem das notas; as válidas são as que não foram rejeitadas
+	aLancar := make([]models.AlunoAvaliacao, 0, len(validas))
+	for i, j := 0, 0; i < len(notas); i++ {
+		linha := &resultado.Linhas[posicoes[i]]
+		if linha.Erros != nil {
+			continue
+		}
+
+		nota := validas[j]
+		j++
+		aplicaPenalidade(disciplina.Escala, &nota, penalidades[nota.AlunoId])
+		linha.NotaNova = notaExibida(disciplina, &nota)
+
+		lancada, existe := lancadas[nota.AlunoId]
+		switch {
+		case !existe:
+			linha.Acao = models.ImportacaoCriar
+			resultado.Lancadas++
+		case lancada.Nota != nota.Nota || lancada.NotaEscala != nota.NotaEscala || lancada.Penalidade != nota.Penalidade:
+			linha.Acao = models.ImportacaoAtualizar
+			resultado.Atualizadas++
+		default:
+			linha.Acao = models.ImportacaoInalterado
+			resultado.Inalteradas++
+		}
+		if linha.Acao != models.ImportacaoInalterado {
+			aLancar = append(aLancar, notas[i])
+		}
+	}
+
+	resultado.Validas = len(resultado.Linhas) - resultado.Invalidas
+	return resultado, aLancar, nil
+}
+
+// notasLancadas retorna as notas já lançadas em uma avaliação, indexadas pelo ID do aluno
+func notasLancadas(avaliacaoId string) (map[string]models.AlunoAvaliacao, *utils.RestErr) {
+	var notas []models.AlunoAvaliacao
+	if err := database.DB.Where("avaliacao_id = ?", avaliacaoId).Find(&notas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar notas da avaliação", err)
+	}
+
+	lancadas := make(map[string]models.AlunoAvaliacao, len(notas))
+	for _, nota := range notas {
+		lancadas[nota.AlunoId] = nota
+	}
+	return lancadas, nil
+}
+
+// lePlanilhaEnviada lê as linhas de uma planilha CSV ou XLSX enviada na requisição
+//
+// Retorna as linhas, com o cabeçalho na primeira, ou erro 400 se o arquivo não for enviado, for grande demais, não
+// for uma planilha válida ou estiver vazio
+func lePlanilhaEnviada(arquivo *multipart.FileHeader) ([][]string, *utils.RestErr) {
+	if arquivo == nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha é obrigatória", nil)
+	}
+
+	if arquivo.Size > tamanhoMaximoPlanilha {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha deve ter no máximo 5 MB", nil)
+	}
+
+	formato, err := planilhas.Formato(arquivo.Filename)
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha deve ser um arquivo .csv ou .xlsx", nil)
+	}
+
+	conteudo, err := arquivo.Open()
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Erro ao ler planilha", err)
+	}
+	defer conteudo.Close()
+
+	linhas, err := planilhas.Le(formato, conteudo)
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha não pôde ser lida: "+err.Error(), nil)
+	}
+
+	if len(linhas) == 0 {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A planilha está vazia", nil)
+	}
+
+	return linhas, nil
+}
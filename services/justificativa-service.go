@@ -0,0 +1,364 @@
+package services
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/storage"
+	"sistema-alunos-go/utils"
+	"strings"
+	"time"
+)
+
+// tamanhoMaximoDocumento é o tamanho máximo, em bytes, de um documento anexado a uma justificativa
+const tamanhoMaximoDocumento = 10 << 20
+
+// tiposDocumentoPermitidos lista os tipos de conteúdo aceitos como documento comprobatório
+var tiposDocumentoPermitidos = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+}
+
+// EnviarJustificativa registra um pedido de abono de faltas de um aluno em aulas de uma disciplina
+//
+// Quando enviada por um aluno, a justificativa é sempre registrada em seu próprio nome; quando enviada por um
+// professor, o aluno deve ser informado. O aluno deve estar matriculado na disciplina, todas as aulas devem pertencer
+// a ela e o semestre deve estar aberto. O documento é gravado no armazenamento de arquivos antes do registro
+//
+// Retorna a justificativa criada, com situação pendente, ou erro em caso de falha de validação ou persistência
+func EnviarJustificativa(solicitacao models.SolicitacaoJustificativa, documento *multipart.FileHeader, solicitanteId string, solicitanteTipo string) (*models.Justificativa, *utils.RestErr) {
+	if solicitanteTipo == models.AutorAluno {
+		solicitacao.AlunoId = solicitanteId
+	}
+	if solicitacao.AlunoId == "" {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O aluno da justificativa deve ser informado", nil)
+	}
+
+	disciplina, restErr := buscaDisciplina(solicitacao.DisciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	matriculado, restErr := alunoMatriculado(disciplina.Id, solicitacao.AlunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !matriculado {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Aluno não está matriculado na disciplina", nil)
+	}
+
+	aulaIds, restErr := validaAulasDisciplina(disciplina.Id, solicitacao.AulaIds)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	tipo, restErr := validaDocumento(documento)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	chave := "justificativas/" + uuid.New().String() + strings.ToLower(filepath.Ext(documento.Filename))
+	if restErr := salvaArquivo(chave, documento); restErr != nil {
+		return nil, restErr
+	}
+
+	justificativa := models.Justificativa{
+		AlunoId:         solicitacao.AlunoId,
+		DisciplinaId:    disciplina.Id,
+		Motivo:          solicitacao.Motivo,
+		Status:          models.JustificativaPendente,
+		SolicitanteId:   solicitanteId,
+		SolicitanteTipo: solicitanteTipo,
+		DocumentoNome:   filepath.Base(documento.Filename),
+		DocumentoTipo:   tipo,
+		DocumentoChave:  chave,
+	}
+	for _, aulaId := range aulaIds {
+		justificativa.Aulas = append(justificativa.Aulas, models.JustificativaAula{AulaId: aulaId})
+	}
+
+	if err := database.DB.Create(&justificativa).Error; err != nil {
+		_ = storage.Arquivos.Remover(chave)
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar justificativa", err)
+	}
+
+	return &justificativa, nil
+}
+
+// AvaliarJustificativa aprova ou rejeita uma justificativa pendente
+//
+// Apenas o professor responsável pela disciplina ou um professor coordenador pode avaliar a justificativa, e somente
+// enquanto o semestre da disciplina estiver aberto. Justificativas aprovadas passam a abonar as faltas nas aulas
+// indicadas no cálculo da frequência
+//
+// Retorna a justificativa atualizada ou erro em caso de falha de autorização, validação ou persistência
+func AvaliarJustificativa(id string, aprovada bool, observacao string, professorId string) (*models.Justificativa, *utils.RestErr) {
+	justificativa, restErr := buscaJustificativa(id)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if justificativa.Status != models.JustificativaPendente {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Justificativa já foi avaliada", nil)
+	}
+
+	disciplina, restErr := buscaDisciplina(justificativa.DisciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	if restErr := verificaResponsavelDisciplina(disciplina, professorId); restErr != nil {
+		return nil, restErr
+	}
+
+	agora := time.Now()
+	justificativa.Status = models.JustificativaRejeitada
+	if aprovada {
+		justificativa.Status = models.JustificativaAprovada
+	}
+	justificativa.AvaliadorId = &professorId
+	justificativa.Observacao = observacao
+	justificativa.AvaliadaEm = &agora
+
+	err := database.DB.Model(justificativa).Updates(map[string]interface{}{
+		"status":       justificativa.Status,
+		"avaliador_id": professorId,
+		"observacao":   observacao,
+		"avaliada_em":  agora,
+	}).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao avaliar justificativa", err)
+	}
+
+	return justificativa, nil
+}
+
+// ListarJustificativasDisciplina retorna as justificativas enviadas para uma disciplina
+//
+// Apenas o professor responsável pela disciplina ou um professor coordenador pode listar as justificativas. Se
+// `status` for informado, apenas justificativas nessa situação são retornadas
+func ListarJustificativasDisciplina(disciplinaId string, status string, professorId string) ([]models.Justificativa, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if restErr := verificaResponsavelDisciplina(disciplina, professorId); restErr != nil {
+		return nil, restErr
+	}
+
+	query := database.DB.Preload("Aluno").Preload("Aulas").Where("disciplina_id = ?", disciplinaId)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var justificativas []models.Justificativa
+	if err := query.Order("created_at DESC").Find(&justificativas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar justificativas", err)
+	}
+
+	return justificativas, nil
+}
+
+// ListarJustificativasAluno retorna as justificativas de um aluno
+//
+// Se `status` for informado, apenas justificativas nessa situação são retornadas
+func ListarJustificativasAluno(alunoId string, status string) ([]models.Justificativa, *utils.RestErr) {
+	query := database.DB.Preload("Aulas").Where("aluno_id = ?", alunoId)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var justificativas []models.Justificativa
+	if err := query.Order("created_at DESC").Find(&justificativas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar justificativas", err)
+	}
+
+	return justificativas, nil
+}
+
+// GetJustificativa retorna os detalhes de uma justificativa
+//
+// Alunos só podem consultar as próprias justificativas, e professores apenas as das disciplinas pelas quais são
+// responsáveis, exceto coordenadores
+func GetJustificativa(id string, usuarioId string, usuarioTipo string) (*models.Justificativa, *utils.RestErr) {
+	justificativa, restErr := buscaJustificativa(id)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if usuarioTipo == models.AutorAluno {
+		if justificativa.AlunoId != usuarioId {
+			return nil, utils.NewRestErr(http.StatusForbidden, "Justificativa pertence a outro aluno", nil)
+		}
+		return justificativa, nil
+	}
+
+	disciplina, restErr := buscaDisciplina(justificativa.DisciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if restErr := verificaResponsavelDisciplina(disciplina, usuarioId); restErr != nil {
+		return nil, restErr
+	}
+
+	return justificativa, nil
+}
+
+// AbrirDocumentoJustificativa abre o documento comprobatório de uma justificativa para leitura
+//
+// Aplica as mesmas regras de acesso de GetJustificativa. O leitor retornado deve ser fechado pelo chamador
+func AbrirDocumentoJustificativa(id string, usuarioId string, usuarioTipo string) (io.ReadCloser, *models.Justificativa, *utils.RestErr) {
+	justificativa, restErr := GetJustificativa(id, usuarioId, usuarioTipo)
+	if restErr != nil {
+		return nil, nil, restErr
+	}
+
+	documento, err := storage.Arquivos.Abrir(justificativa.DocumentoChave)
+	if err != nil {
+		if errors.Is(err, storage.ErrArquivoNaoEncontrado) {
+			return nil, nil, utils.NewRestErr(http.StatusNotFound, "Documento não encontrado", err)
+		}
+		return nil, nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao abrir documento", err)
+	}
+
+	return documento, justificativa, nil
+}
+
+// buscaJustificativa busca uma justificativa pelo ID, incluindo as aulas associadas
+//
+// Retorna a justificativa encontrada ou erro caso não exista ou a consulta falhe
+func buscaJustificativa(id string) (*models.Justificativa, *utils.RestErr) {
+	var justificativa models.Justificativa
+	err := database.DB.Preload("Aulas").Where("id = ?", id).First(&justificativa).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "Justificativa não encontrada", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar justificativa", err)
+	}
+
+	return &justificativa, nil
+}
+
+// aulasAbonadas retorna o conjunto de aulas, dentre as informadas, com faltas abonadas por justificativas aprovadas do aluno
+func aulasAbonadas(alunoId string, aulaIds []string) (map[string]bool, *utils.RestErr) {
+	var ids []string
+	err := database.DB.Model(&models.JustificativaAula{}).
+		Joins("JOIN justificativas ON justificativas.id = justificativa_aula.justificativa_id").
+		Where("justificativas.aluno_id = ? AND justificativas.status = ?", alunoId, models.JustificativaAprovada).
+		Where("justificativa_aula.aula_id IN (?)", aulaIds).
+		Distinct().Pluck("justificativa_aula.aula_id", &ids).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar faltas abonadas", err)
+	}
+
+	abonadas := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		abonadas[id] = true
+	}
+	return abonadas, nil
+}
+
+// validaAulasDisciplina confere se todas as aulas informadas existem e pertencem à disciplina
+//
+// Retorna os IDs sem repetições ou erro 400 com a lista de IDs que não pertencem à disciplina
+func validaAulasDisciplina(disciplinaId string, aulaIds []string) ([]string, *utils.RestErr) {
+	var unicos []string
+	vistos := make(map[string]bool, len(aulaIds))
+	for _, id := range aulaIds {
+		if !vistos[id] {
+			vistos[id] = true
+			unicos = append(unicos, id)
+		}
+	}
+
+	var encontrados []string
+	err := database.DB.Model(&models.Aula{}).
+		Where("disciplina_id = ? AND id IN (?)", disciplinaId, unicos).
+		Pluck("id", &encontrados).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar aulas", err)
+	}
+
+	pertencem := make(map[string]bool, len(encontrados))
+	for _, id := range encontrados {
+		pertencem[id] = true
+	}
+
+	var invalidas []string
+	for _, id := range unicos {
+		if !pertencem[id] {
+			invalidas = append(invalidas, id)
+		}
+	}
+	if len(invalidas) > 0 {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Aulas não pertencem à disciplina", nil, invalidas)
+	}
+
+	return unicos, nil
+}
+
+// validaDocumento confere o tamanho e o tipo de conteúdo de um documento enviado
+//
+// Retorna o tipo de conteúdo detectado ou erro 400 caso o documento esteja ausente, seja grande demais ou de um tipo
+// não permitido
+func validaDocumento(documento *multipart.FileHeader) (string, *utils.RestErr) {
+	if documento == nil {
+		return "", utils.NewRestErr(http.StatusBadRequest, "O documento comprobatório é obrigatório", nil)
+	}
+
+	if documento.Size > tamanhoMaximoDocumento {
+		return "", utils.NewRestErr(http.StatusBadRequest, "O documento deve ter no máximo 10 MB", nil)
+	}
+
+	arquivo, err := documento.Open()
+	if err != nil {
+		return "", utils.NewRestErr(http.StatusBadRequest, "Erro ao ler documento", err)
+	}
+	defer arquivo.Close()
+
+	cabecalho := make([]byte, 512)
+	n, err := io.ReadFull(arquivo, cabecalho)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", utils.NewRestErr(http.StatusBadRequest, "Erro ao ler documento", err)
+	}
+
+	tipo := http.DetectContentType(cabecalho[:n])
+	if !tiposDocumentoPermitidos[tipo] {
+		return "", utils.NewRestErr(http.StatusBadRequest, "O documento deve ser um PDF, PNG ou JPEG", nil)
+	}
+
+	return tipo, nil
+}
+
+// salvaArquivo grava o conteúdo de um arquivo enviado no armazenamento de arquivos sob a chave informada
+func salvaArquivo(chave string, arquivo *multipart.FileHeader) *utils.RestErr {
+	conteudo, err := arquivo.Open()
+	if err != nil {
+		return utils.NewRestErr(http.StatusBadRequest, "Erro ao ler arquivo", err)
+	}
+	defer conteudo.Close()
+
+	if err := storage.Arquivos.Salvar(chave, conteudo); err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao armazenar arquivo", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,658 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"net/http"
+	"regexp"
+	"sistema-alunos-go/legado"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// motivoImportacaoLegado é a justificativa registrada no histórico das notas importadas do sistema antigo
+const motivoImportacaoLegado = "Importação do sistema antigo"
+
+// Valores usados nas disciplinas do sistema antigo quando o layout não informa os critérios de aprovação
+const (
+	cargaHorariaPadraoLegado     = 60
+	notaMinimaPadraoLegado       = 6
+	frequenciaMinimaPadraoLegado = 75
+)
+
+// namespaceLegado é o namespace dos IDs dos registros importados do sistema antigo
+//
+// Os IDs são derivados das chaves do sistema antigo (UUID versão 5), para que uma nova importação dos mesmos
+// arquivos atualize os registros já importados em vez de duplicá-los
+var namespaceLegado = uuid.NewSHA1(uuid.NameSpaceURL, []byte("sistema-alunos-go/legado"))
+
+// anoSemestreLegado reconhece os formatos de ano e semestre usados pelo sistema antigo, como 2019-01, 2019/1 e 20191
+var anoSemestreLegado = regexp.MustCompile(`^(\d{4})\D?0?([12])$`)
+
+// errSimulacaoLegado desfaz a transação de uma importação simulada
+var errSimulacaoLegado = errors.New("importação simulada")
+
+// importacaoLegado guarda o estado de uma importação dos arquivos do sistema antigo
+//
+// Os mapas associam as chaves do sistema antigo aos IDs dos registros importados nesta execução ou em execuções
+// anteriores
+type importacaoLegado struct {
+	tx          *gorm.DB
+	dados       *legado.Dados
+	professorId string
+	resultado   *models.ResultadoImportacaoLegado
+	alunos      map[string]string
+	disciplinas map[string]string
+	avaliacoes  map[string]string
+}
+
+// ImportarLegado importa os registros lidos dos arquivos do sistema antigo como alunos, disciplinas, avaliações e
+// notas
+//
+// As disciplinas importadas ficam sob responsabilidade do professor com o e-mail informado. Cada registro é validado
+// com as mesmas regras da API; registros inválidos, repetidos ou que referenciem registros inexistentes são rejeitados
+// e listados no resultado, sem impedir a importação dos demais. Os alunos com notas são matriculados nas disciplinas,
+// e as notas, na escala de 0 a 10 do sistema antigo, são registradas no histórico de notas. Os alunos criados, as
+// matrículas e as notas alteradas publicam os eventos correspondentes para os webhooks
+//
+// A importação é idempotente: os IDs são derivados das chaves do sistema antigo, e importar os mesmos arquivos de novo
+// apenas atualiza o que mudou. Com `simular`, tudo é validado em uma transação desfeita ao final
+//
+// O sistema antigo não guardava aulas nem presenças, por isso nenhuma média é calculada: as disciplinas importadas
+// ficam abertas, mesmo as de semestres passados, e só entram no histórico escolar após o fechamento do semestre
+//
+// Retorna o resultado da importação ou erro caso o professor não exista ou a gravação falhe
+func ImportarLegado(dados *legado.Dados, professorEmail string, simular bool) (*models.ResultadoImportacaoLegado, *utils.RestErr) {
+	professor, restErr := buscaProfessorEmail(professorEmail)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if professor.Id == "" {
+		return nil, utils.NewRestErr(http.StatusNotFound, "Professor não encontrado", nil)
+	}
+
+	importacao := &importacaoLegado{
+		dados:       dados,
+		professorId: professor.Id,
+		resultado:   &models.ResultadoImportacaoLegado{Simulada: simular, Rejeitados: []models.RegistroLegadoRejeitado{}},
+		alunos:      make(map[string]string),
+		disciplinas: make(map[string]string),
+		avaliacoes:  make(map[string]string),
+	}
+
+	err := transacaoEventos(func(tx *gorm.DB) error {
+		importacao.tx = tx
+		etapas := []func() *utils.RestErr{
+			importacao.importaAlunos,
+			importacao.importaDisciplinas,
+			importacao.importaAvaliacoes,
+			importacao.importaNotas,
+		}
+		for _, etapa := range etapas {
+			if restErr = etapa(); restErr != nil {
+				return restErr.Err
+			}
+		}
+
+		if simular {
+			return errSimulacaoLegado
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil && !errors.Is(err, errSimulacaoLegado) {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao importar dados do sistema antigo", err)
+	}
+
+	return importacao.resultado, nil
+}
+
+// importaAlunos cria ou atualiza os alunos do arquivo de alunos
+//
+// Alunos sem e-mail recebem um e-mail formado pela matrícula e pelo domínio do layout. Como na API, cada e-mail
+// pertence a um único aluno: são rejeitados os registros com o e-mail de outro registro do arquivo ou de um aluno já
+// cadastrado que não tenha vindo do sistema antigo com a mesma matrícula
+func (i *importacaoLegado) importaAlunos() *utils.RestErr {
+	var candidatos []models.Aluno
+	var registros []legado.Registro
+	linhas := make(map[string]int)
+	linhasEmail := make(map[string]int)
+	for _, registro := range i.dados.Registros[legado.ArquivoAlunos] {
+		matricula := registro.Campos["matricula"]
+		if matricula == "" {
+			i.rejeita(registro, "matrícula em branco")
+			continue
+		}
+		if anterior, repetido := linhas[matricula]; repetido {
+			i.rejeita(registro, fmt.Sprintf("matrícula %s repetida da linha %d", matricula, anterior))
+			continue
+		}
+
+		email := registro.Campos["email"]
+		if email == "" {
+			email = strings.ToLower(matricula) + "@" + i.dados.DominioEmail
+		}
+		ativo, ok := booleanoLegado(registro.Campos["ativo"])
+		if !ok {
+			i.rejeita(registro, "situação do aluno inválida: "+registro.Campos["ativo"])
+			continue
+		}
+
+		aluno := models.Aluno{Id: idLegado("aluno", matricula), Nome: registro.Campos["nome"], Email: email, Ativo: ativo}
+		if erros := utils.ValidateStruct(&aluno); len(erros) > 0 {
+			i.rejeitaValidacao(registro, erros)
+			continue
+		}
+		if anterior, repetido := linhasEmail[email]; repetido {
+			i.rejeita(registro, fmt.Sprintf("e-mail %s repetido da linha %d", email, anterior))
+			continue
+		}
+
+		linhas[matricula] = registro.Linha
+		linhasEmail[email] = registro.Linha
+		candidatos = append(candidatos, aluno)
+		registros = append(registros, registro)
+	}
+
+	donos, restErr := i.donosEmails(chaves(linhasEmail))
+	if restErr != nil {
+		return restErr
+	}
+
+	var alunos []models.Aluno
+	for indice, aluno := range candidatos {
+		if dono, cadastrado := donos[aluno.Email]; cadastrado && dono != aluno.Id {
+			i.rejeita(registros[indice], fmt.Sprintf("e-mail %s já pertence a outro aluno", aluno.Email))
+			continue
+		}
+		i.alunos[registros[indice].Campos["matricula"]] = aluno.Id
+		alunos = append(alunos, aluno)
+	}
+
+	existentes, restErr := buscaPorIds(i.tx, alunos, func(a models.Aluno) string { return a.Id })
+	if restErr != nil {
+		return restErr
+	}
+
+	for _, aluno := range alunos {
+		atual, existe := existentes[aluno.Id]
+		colunas := map[string]interface{}{"nome": aluno.Nome, "email": aluno.Email, "ativo": aluno.Ativo}
+		alterado := existe && (atual.Nome != aluno.Nome || atual.Email != aluno.Email || atual.Ativo != aluno.Ativo)
+		if restErr := i.grava(&aluno, existe, alterado, colunas, &i.resultado.Alunos); restErr != nil {
+			return restErr
+		}
+		if !existe {
+			if restErr := publicaEvento(i.tx, models.EventoAlunoCadastrado, aluno); restErr != nil {
+				return restErr
+			}
+		}
+	}
+	return nil
+}
+
+// importaDisciplinas cria ou atualiza as disciplinas do arquivo de disciplinas, sob responsabilidade do professor da
+// importação
+//
+// Os critérios de aprovação não informados usam os valores padrão do sistema antigo
+func (i *importacaoLegado) importaDisciplinas() *utils.RestErr {
+	var disciplinas []models.Disciplina
+	linhas := make(map[string]int)
+	for _, registro := range i.dados.Registros[legado.ArquivoDisciplinas] {
+		codigo := registro.Campos["codigo"]
+		if codigo == "" {
+			i.rejeita(registro, "código da disciplina em branco")
+			continue
+		}
+		if anterior, repetido := linhas[codigo]; repetido {
+			i.rejeita(registro, fmt.Sprintf("disciplina %s repetida da linha %d", codigo, anterior))
+			continue
+		}
+
+		cargaHoraria, okCarga := numeroLegado(registro.Campos["carga_horaria"], cargaHorariaPadraoLegado)
+		notaMinima, okNota := numeroLegado(registro.Campos["nota_minima"], notaMinimaPadraoLegado)
+		frequenciaMinima, okFrequencia := numeroLegado(registro.Campos["frequencia_minima"], frequenciaMinimaPadraoLegado)
+		if !okCarga || !okNota || !okFrequencia {
+			i.rejeita(registro, "carga horária, nota mínima ou frequência mínima inválida")
+			continue
+		}
+
+		disciplina := models.Disciplina{
+			Id:                   idLegado("disciplina", codigo),
+			Nome:                 registro.Campos["nome"],
+			ProfessorId:          i.professorId,
+			AnoSemestre:          anoSemestreLegado.ReplaceAllString(registro.Campos["ano_semestre"], "$1-0$2"),
+			CargaHorariaPrevista: int(cargaHoraria),
+			NotaMinima:           notaMinima,
+			FrequenciaMinima:     frequenciaMinima,
+		}
+		if erros := utils.ValidateStruct(&disciplina); len(erros) > 0 {
+			i.rejeitaValidacao(registro, erros)
+			continue
+		}
+
+		linhas[codigo] = registro.Linha
+		i.disciplinas[codigo] = disciplina.Id
+		disciplinas = append(disciplinas, disciplina)
+	}
+
+	existentes, restErr := buscaPorIds(i.tx, disciplinas, func(d models.Disciplina) string { return d.Id })
+	if restErr != nil {
+		return restErr
+	}
+
+	for _, disciplina := range disciplinas {
+		atual, existe := existentes[disciplina.Id]
+		colunas := map[string]interface{}{
+			"nome":                   disciplina.Nome,
+			"ano_semestre":           disciplina.AnoSemestre,
+			"carga_horaria_prevista": disciplina.CargaHorariaPrevista,
+			"nota_minima":            disciplina.NotaMinima,
+			"frequencia_minima":      disciplina.FrequenciaMinima,
+		}
+		alterado := existe && (atual.Nome != disciplina.Nome || atual.AnoSemestre != disciplina.AnoSemestre ||
+			atual.CargaHorariaPrevista != disciplina.CargaHorariaPrevista || atual.NotaMinima != disciplina.NotaMinima ||
+			atual.FrequenciaMinima != disciplina.FrequenciaMinima)
+		if restErr := i.grava(&disciplina, existe, alterado, colunas, &i.resultado.Disciplinas); restErr != nil {
+			return restErr
+		}
+	}
+	return nil
+}
+
+// importaAvaliacoes cria ou atualiza as avaliações do arquivo de avaliações e recalcula a quantidade de provas e
+// trabalhos das disciplinas alteradas
+//
+// O código de cada avaliação é único dentro da disciplina. Avaliações sem nome recebem o próprio código e avaliações
+// sem tipo são consideradas provas
+func (i *importacaoLegado) importaAvaliacoes() *utils.RestErr {
+	registros := i.dados.Registros[legado.ArquivoAvaliacoes]
+	if restErr := i.resolveReferencias(registros, "disciplina", "disciplina", i.disciplinas, &models.Disciplina{}); restErr != nil {
+		return restErr
+	}
+
+	var avaliacoes []models.Avaliacao
+	linhas := make(map[string]int)
+	for _, registro := range registros {
+		disciplinaId, ok := i.disciplinas[registro.Campos["disciplina"]]
+		if !ok {
+			i.rejeita(registro, "disciplina "+registro.Campos["disciplina"]+" não encontrada")
+			continue
+		}
+
+		codigo := registro.Campos["codigo"]
+		chave := registro.Campos["disciplina"] + "/" + codigo
+		if codigo == "" {
+			i.rejeita(registro, "código da avaliação em branco")
+			continue
+		}
+		if anterior, repetido := linhas[chave]; repetido {
+			i.rejeita(registro, fmt.Sprintf("avaliação %s repetida da linha %d", chave, anterior))
+			continue
+		}
+
+		peso, okPeso := numeroLegado(registro.Campos["peso"], -1)
+		if !okPeso {
+			i.rejeita(registro, "peso inválido: "+registro.Campos["peso"])
+			continue
+		}
+		nome := registro.Campos["nome"]
+		if nome == "" {
+			nome = codigo
+		}
+		tipo := strings.ToUpper(registro.Campos["tipo"])
+		if tipo == "" {
+			tipo = models.AvaliacaoProva
+		}
+
+		avaliacao := models.Avaliacao{
+			Id:            idLegado("avaliacao", registro.Campos["disciplina"], codigo),
+			DisciplinaId:  disciplinaId,
+			Nome:          nome,
+			Tipo:          tipo,
+			DataAvaliacao: dataLegado(registro.Campos["data"]),
+			Peso:          peso,
+		}
+		if erros := utils.ValidateStruct(&avaliacao); len(erros) > 0 {
+			i.rejeitaValidacao(registro, erros)
+			continue
+		}
+
+		linhas[chave] = registro.Linha
+		i.avaliacoes[chave] = avaliacao.Id
+		avaliacoes = append(avaliacoes, avaliacao)
+	}
+
+	existentes, restErr := buscaPorIds(i.tx, avaliacoes, func(a models.Avaliacao) string { return a.Id })
+	if restErr != nil {
+		return restErr
+	}
+
+	alteradas := make(map[string]bool)
+	for _, avaliacao := range avaliacoes {
+		atual, existe := existentes[avaliacao.Id]
+		colunas := map[string]interface{}{
+			"nome":           avaliacao.Nome,
+			"tipo":           avaliacao.Tipo,
+			"data_avaliacao": avaliacao.DataAvaliacao,
+			"peso":           avaliacao.Peso,
+		}
+		alterado := existe && (atual.Nome != avaliacao.Nome || atual.Tipo != avaliacao.Tipo ||
+			atual.DataAvaliacao != avaliacao.DataAvaliacao || atual.Peso != avaliacao.Peso)
+		if restErr := i.grava(&avaliacao, existe, alterado, colunas, &i.resultado.Avaliacoes); restErr != nil {
+			return restErr
+		}
+		if !existe || alterado {
+			alteradas[avaliacao.DisciplinaId] = true
+		}
+	}
+
+	for disciplinaId := range alteradas {
+		if restErr := recalculaQuantidadeAvaliacoes(i.tx, disciplinaId); restErr != nil {
+			return restErr
+		}
+	}
+	return nil
+}
+
+// importaNotas registra as notas do arquivo de notas, matriculando os alunos nas disciplinas quando necessário
+//
+// As notas novas ou alteradas são gravadas por registraNota, que registra a alteração no histórico de notas
+func (i *importacaoLegado) importaNotas() *utils.RestErr {
+	registros := i.dados.Registros[legado.ArquivoNotas]
+	if restErr := i.resolveReferencias(registros, "aluno", "aluno", i.alunos, &models.Aluno{}); restErr != nil {
+		return restErr
+	}
+	if restErr := i.resolveReferencias(registros, "disciplina", "disciplina", i.disciplinas, &models.Disciplina{}); restErr != nil {
+		return restErr
+	}
+
+	// As avaliações de importações anteriores são identificadas pela disciplina e pelo código da avaliação
+	referenciadas := make(map[string]bool)
+	for _, registro := range registros {
+		referenciadas[registro.Campos["disciplina"]+"/"+registro.Campos["avaliacao"]] = true
+	}
+	porId := make(map[string]string)
+	for chave := range referenciadas {
+		if _, ok := i.avaliacoes[chave]; !ok {
+			disciplina, avaliacao, _ := strings.Cut(chave, "/")
+			porId[idLegado("avaliacao", disciplina, avaliacao)] = chave
+		}
+	}
+	existentes, restErr := idsCadastrados(i.tx, &models.Avaliacao{}, "id", chaves(porId))
+	if restErr != nil {
+		return restErr
+	}
+	for id := range existentes {
+		i.avaliacoes[porId[id]] = id
+	}
+
+	var notas []models.AlunoAvaliacao
+	linhas := make(map[string]int)
+	for _, registro := range registros {
+		alunoId, okAluno := i.alunos[registro.Campos["aluno"]]
+		disciplinaId, okDisciplina := i.disciplinas[registro.Campos["disciplina"]]
+		avaliacaoId, okAvaliacao := i.avaliacoes[registro.Campos["disciplina"]+"/"+registro.Campos["avaliacao"]]
+		switch {
+		case !okAluno:
+			i.rejeita(registro, "aluno "+registro.Campos["aluno"]+" não encontrado")
+			continue
+		case !okDisciplina:
+			i.rejeita(registro, "disciplina "+registro.Campos["disciplina"]+" não encontrada")
+			continue
+		case !okAvaliacao:
+			i.rejeita(registro, "avaliação "+registro.Campos["avaliacao"]+" não encontrada na disciplina "+registro.Campos["disciplina"])
+			continue
+		}
+
+		chave := alunoId + "/" + avaliacaoId
+		if anterior, repetido := linhas[chave]; repetido {
+			i.rejeita(registro, fmt.Sprintf("nota repetida da linha %d", anterior))
+			continue
+		}
+
+		nota, notaEscala, ok := converteNota(models.EscalaDecimal, nil, registro.Campos["nota"])
+		if !ok {
+			i.rejeita(registro, motivoNotaForaEscala+": "+registro.Campos["nota"])
+			continue
+		}
+
+		linhas[chave] = registro.Linha
+		notas = append(notas, models.AlunoAvaliacao{
+			AlunoId:      alunoId,
+			AvaliacaoId:  avaliacaoId,
+			DisciplinaId: disciplinaId,
+			Nota:         nota,
+			NotaEscala:   notaEscala,
+		})
+	}
+
+	if restErr := i.matriculaAlunos(notas); restErr != nil {
+		return restErr
+	}
+
+	avaliacaoIds := make(map[string]bool)
+	for _, nota := range notas {
+		avaliacaoIds[nota.AvaliacaoId] = true
+	}
+	lancadas := make(map[string]models.AlunoAvaliacao)
+	for _, lote := range lotes(chaves(avaliacaoIds), tamanhoConsultaBackup) {
+		var existentes []models.AlunoAvaliacao
+		if err := i.tx.Where("avaliacao_id IN ?", lote).Find(&existentes).Error; err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar notas lançadas", err)
+		}
+		for _, nota := range existentes {
+			lancadas[nota.AlunoId+"/"+nota.AvaliacaoId] = nota
+		}
+	}
+
+	for _, nota := range notas {
+		atual, existe := lancadas[nota.AlunoId+"/"+nota.AvaliacaoId]
+		switch {
+		case !existe:
+			i.resultado.Notas.Criados++
+		case atual.Nota != nota.Nota || atual.NotaEscala != nota.NotaEscala:
+			nota.Penalidade = atual.Penalidade
+			i.resultado.Notas.Atualizados++
+		default:
+			i.resultado.Notas.Inalterados++
+			continue
+		}
+
+		if _, restErr := registraNota(i.tx, nota, "", models.AutorSistema, motivoImportacaoLegado); restErr != nil {
+			return restErr
+		}
+	}
+	return nil
+}
+
+// matriculaAlunos matricula os alunos com notas nas disciplinas em que ainda não estão matriculados e atualiza a
+// quantidade de alunos dessas disciplinas
+func (i *importacaoLegado) matriculaAlunos(notas []models.AlunoAvaliacao) *utils.RestErr {
+	disciplinaIds := make(map[string]bool)
+	for _, nota := range notas {
+		disciplinaIds[nota.DisciplinaId] = true
+	}
+
+	matriculados := make(map[string]bool)
+	for _, lote := range lotes(chaves(disciplinaIds), tamanhoConsultaBackup) {
+		var vinculos []models.AlunoDisciplina
+		if err := i.tx.Where("disciplina_id IN ?", lote).Find(&vinculos).Error; err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar matrículas", err)
+		}
+		for _, vinculo := range vinculos {
+			matriculados[vinculo.AlunoId+"/"+vinculo.DisciplinaId] = true
+		}
+	}
+
+	novas := make(map[string]int)
+	for _, nota := range notas {
+		chave := nota.AlunoId + "/" + nota.DisciplinaId
+		if matriculados[chave] {
+			continue
+		}
+
+		vinculo := models.AlunoDisciplina{AlunoId: nota.AlunoId, DisciplinaId: nota.DisciplinaId}
+		if err := i.tx.Create(&vinculo).Error; err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao matricular aluno", err)
+		}
+		if restErr := publicaEvento(i.tx, models.EventoAlunoMatriculado, vinculo); restErr != nil {
+			return restErr
+		}
+		matriculados[chave] = true
+		novas[nota.DisciplinaId]++
+		i.resultado.Matriculas++
+	}
+
+	for disciplinaId, quantidade := range novas {
+		err := i.tx.Model(&models.Disciplina{}).Where("id = ?", disciplinaId).
+			Update("quantidade_alunos", gorm.Expr("quantidade_alunos + ?", quantidade)).Error
+		if err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar quantidade de alunos", err)
+		}
+	}
+	return nil
+}
+
+// donosEmails retorna o ID do aluno já cadastrado com cada um dos e-mails informados
+func (i *importacaoLegado) donosEmails(emails []string) (map[string]string, *utils.RestErr) {
+	donos := make(map[string]string, len(emails))
+	for _, lote := range lotes(emails, tamanhoConsultaBackup) {
+		var alunos []models.Aluno
+		if err := i.tx.Select("id", "email").Where("email IN ?", lote).Find(&alunos).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos pelo e-mail", err)
+		}
+		for _, aluno := range alunos {
+			donos[aluno.Email] = aluno.Id
+		}
+	}
+	return donos, nil
+}
+
+// resolveReferencias completa `ids` com os registros importados em execuções anteriores que são referenciados pelo
+// campo informado, mas não estão nos arquivos desta importação
+func (i *importacaoLegado) resolveReferencias(registros []legado.Registro, campo string, tipo string, ids map[string]string, modelo interface{}) *utils.RestErr {
+	porId := make(map[string]string)
+	for _, registro := range registros {
+		chave := registro.Campos[campo]
+		if _, ok := ids[chave]; !ok && chave != "" {
+			porId[idLegado(tipo, chave)] = chave
+		}
+	}
+
+	existentes, restErr := idsCadastrados(i.tx, modelo, "id", chaves(porId))
+	if restErr != nil {
+		return restErr
+	}
+	for id := range existentes {
+		ids[porId[id]] = id
+	}
+	return nil
+}
+
+// grava cria o registro, se ele ainda não existir, ou atualiza as colunas informadas, se tiverem mudado, somando o
+// resultado na contagem
+//
+// Os registros são criados sem os hooks dos modelos, que gerariam um novo ID no lugar do derivado da chave antiga
+func (i *importacaoLegado) grava(registro interface{}, existe bool, alterado bool, colunas map[string]interface{}, contagem *models.ContagemImportacao) *utils.RestErr {
+	switch {
+	case !existe:
+		if err := i.tx.Session(&gorm.Session{SkipHooks: true}).Create(registro).Error; err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao gravar registro do sistema antigo", err)
+		}
+		contagem.Criados++
+	case alterado:
+		if err := i.tx.Model(registro).Updates(colunas).Error; err != nil {
+			return utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar registro do sistema antigo", err)
+		}
+		contagem.Atualizados++
+	default:
+		contagem.Inalterados++
+	}
+	return nil
+}
+
+// rejeita registra um registro que não pôde ser importado e o motivo
+func (i *importacaoLegado) rejeita(registro legado.Registro, motivo string) {
+	i.resultado.Rejeitados = append(i.resultado.Rejeitados, models.RegistroLegadoRejeitado{
+		Arquivo: registro.Arquivo,
+		Linha:   registro.Linha,
+		Motivo:  motivo,
+	})
+}
+
+// rejeitaValidacao registra um registro rejeitado pelas regras de validação dos modelos
+func (i *importacaoLegado) rejeitaValidacao(registro legado.Registro, erros []utils.ValidationError) {
+	mensagens := make([]string, len(erros))
+	for j, erro := range erros {
+		mensagens[j] = erro.Path + ": " + erro.Message
+	}
+	i.rejeita(registro, strings.Join(mensagens, "; "))
+}
+
+// idLegado deriva o ID de um registro do sistema antigo a partir do tipo e das chaves do registro
+func idLegado(tipo string, chaves ...string) string {
+	nome := tipo + "\x00" + strings.Join(chaves, "\x00")
+	return uuid.NewSHA1(namespaceLegado, []byte(nome)).String()
+}
+
+// numeroLegado lê um número do sistema antigo, que pode usar vírgula como separador decimal
+//
+// Retorna o padrão informado se o valor estiver em branco e false se o valor não for um número
+func numeroLegado(valor string, padrao float64) (float64, bool) {
+	if valor == "" {
+		return padrao, true
+	}
+	numero, err := strconv.ParseFloat(strings.Replace(valor, ",", ".", 1), 64)
+	return numero, err == nil
+}
+
+// booleanoLegado lê a situação de um aluno no sistema antigo; alunos sem situação são considerados ativos
+//
+// Retorna false no segundo valor se a situação não for reconhecida
+func booleanoLegado(valor string) (bool, bool) {
+	switch strings.ToLower(valor) {
+	case "", "s", "sim", "a", "ativo", "1", "true":
+		return true, true
+	case "n", "nao", "não", "i", "inativo", "0", "false":
+		return false, true
+	}
+	return false, false
+}
+
+// dataLegado converte uma data do sistema antigo no formato DD/MM/AAAA para o formato AAAA-MM-DD da API
+//
+// Datas em outros formatos são retornadas sem alteração, para serem rejeitadas pela validação
+func dataLegado(valor string) string {
+	if data, err := time.Parse("02/01/2006", valor); err == nil {
+		return data.Format("2006-01-02")
+	}
+	return valor
+}
+
+// buscaPorIds busca, em lotes, os registros já cadastrados com os mesmos IDs dos registros informados, indexados pelo
+// ID
+func buscaPorIds[T any](tx *gorm.DB, registros []T, id func(T) string) (map[string]T, *utils.RestErr) {
+	ids := make([]string, len(registros))
+	for j, registro := range registros {
+		ids[j] = id(registro)
+	}
+
+	encontrados := make(map[string]T, len(ids))
+	for _, lote := range lotes(ids, tamanhoConsultaBackup) {
+		var cadastrados []T
+		if err := tx.Where("id IN ?", lote).Find(&cadastrados).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar registros importados", err)
+		}
+		for _, registro := range cadastrados {
+			encontrados[id(registro)] = registro
+		}
+	}
+	return encontrados, nil
+}
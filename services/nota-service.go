@@ -0,0 +1,281 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// motivoForaRecuperacao é o motivo de rejeição de notas de recuperação para alunos que não estão em recuperação
+const motivoForaRecuperacao = "aluno não está em recuperação"
+
+// motivoLancamentoNotas é a justificativa registrada no histórico quando as notas são lançadas sem justificativa
+const motivoLancamentoNotas = "Lançamento de notas"
+
+// CorrigirNota altera a nota de um aluno em uma avaliação, registrando a alteração no histórico de notas
+//
+// O aluno deve possuir nota lançada na avaliação e a disciplina deve estar com o semestre aberto ou, para a avaliação
+// de recuperação, em recuperação. Avaliações com rubrica só têm a nota alterada por uma nova correção da rubrica. Se a
+// nota informada for igual à atual, nada é alterado. A penalidade por atraso da entrega do aluno é descontada da nota
+//
+// Retorna a nota atualizada ou erro caso a nota não exista ou a persistência falhe
+func CorrigirNota(disciplinaId string, avaliacaoId string, alunoId string, correcao models.CorrecaoNota, professorId string) (*models.AlunoAvaliacao, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if restErr := verificaNotaAlteravel(disciplina, avaliacao); restErr != nil {
+		return nil, restErr
+	}
+
+	if restErr := verificaNotaSemRubrica(avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	var existente int64
+	err := database.DB.Model(&models.AlunoAvaliacao{}).
+		Where("avaliacao_id = ? AND aluno_id = ?", avaliacaoId, alunoId).Count(&existente).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar nota do aluno", err)
+	}
+	if existente == 0 {
+		return nil, utils.NewRestErr(http.StatusNotFound, "O aluno não possui nota lançada nesta avaliação", nil)
+	}
+
+	var valorEscala string
+	if correcao.NotaEscala != nil {
+		valorEscala = *correcao.NotaEscala
+	}
+
+	notaInterna, notaEscala, naEscala := converteNota(disciplina.Escala, correcao.Nota, valorEscala)
+	if !naEscala {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Nota inválida para a escala da disciplina", nil)
+	}
+
+	penalidades, restErr := penalidadesAtraso(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	nota := models.AlunoAvaliacao{
+		AlunoId:      alunoId,
+		AvaliacaoId:  avaliacaoId,
+		DisciplinaId: disciplinaId,
+		Nota:         notaInterna,
+		NotaEscala:   notaEscala,
+	}
+	aplicaPenalidade(disciplina.Escala, &nota, penalidades[alunoId])
+
+	var alunoAvaliacao *models.AlunoAvaliacao
+	err = transacaoEventos(func(tx *gorm.DB) error {
+		alunoAvaliacao, restErr = registraNota(tx, nota, professorId, models.AutorProfessor, correcao.Justificativa)
+		if restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao corrigir nota", err)
+	}
+
+	return alunoAvaliacao, nil
+}
+
+// ListarHistoricoNotasAvaliacao retorna todas as alterações de notas registradas para uma avaliação, da mais recente
+// para a mais antiga
+func ListarHistoricoNotasAvaliacao(disciplinaId string, avaliacaoId string) ([]models.AlunoAvaliacaoHistorico, *utils.RestErr) {
+	if _, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	var historico []models.AlunoAvaliacaoHistorico
+	err := database.DB.Where("avaliacao_id = ?", avaliacaoId).Order("created_at DESC").Find(&historico).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar histórico de notas", err)
+	}
+
+	return historico, nil
+}
+
+// ListarHistoricoNotasAluno retorna todas as alterações de notas registradas para um aluno
+//
+// Se `disciplinaId` for informado, apenas as alterações em avaliações dessa disciplina são retornadas. Os registros
+// são ordenados da alteração mais recente para a mais antiga
+func ListarHistoricoNotasAluno(alunoId string, disciplinaId string) ([]models.AlunoAvaliacaoHistorico, *utils.RestErr) {
+	if _, restErr := buscaAluno(alunoId); restErr != nil {
+		return nil, restErr
+	}
+
+	query := database.DB.Where("aluno_id = ?", alunoId)
+	if disciplinaId != "" {
+		query = query.Where("disciplina_id = ?", disciplinaId)
+	}
+
+	var historico []models.AlunoAvaliacaoHistorico
+	if err := query.Order("created_at DESC").Find(&historico).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar histórico de notas", err)
+	}
+
+	return historico, nil
+}
+
+// validaNotas separa as notas válidas das que não podem ser lançadas na disciplina
+//
+// Uma nota é rejeitada quando o aluno não está matriculado na disciplina, está com a matrícula trancada, já apareceu
+// antes na lista, se `permitidos` não for nulo e ele não estiver entre os alunos permitidos ou se a nota não pertencer
+// à escala da disciplina. As notas válidas são devolvidas já convertidas para a escala interna e as rejeitadas são
+// identificadas pela posição na lista original
+func validaNotas(disciplina *models.Disciplina, notas []models.AlunoAvaliacao, permitidos map[string]bool) ([]models.AlunoAvaliacao, []utils.ErroAluno, *utils.RestErr) {
+	var matriculados []models.Aluno
+	err := database.DB.
+		Joins("JOIN aluno_disciplina ON aluno_disciplina.aluno_id = alunos.id").
+		Where("aluno_disciplina.disciplina_id = ?", disciplina.Id).
+		Find(&matriculados).Error
+	if err != nil {
+		return nil, nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos da disciplina", err)
+	}
+
+	alunos := make(map[string]models.Aluno, len(matriculados))
+	for _, a := range matriculados {
+		alunos[a.Id] = a
+	}
+
+	var validas []models.AlunoAvaliacao
+	var rejeitadas []utils.ErroAluno
+	informados := make(map[string]bool, len(notas))
+	for i, n := range notas {
+		aluno, matriculado := alunos[n.AlunoId]
+		// No lançamento, nota zero equivale a nota não informada; a nota zero é lançada em nota_escala
+		var valor *float64
+		if n.Nota != 0 {
+			valor = &n.Nota
+		}
+		nota, notaEscala, naEscala := converteNota(disciplina.Escala, valor, n.NotaEscala)
+		switch {
+		case informados[n.AlunoId]:
+			rejeitadas = append(rejeitadas, utils.ErroAluno{Indice: i, AlunoId: n.AlunoId, Motivo: motivoDuplicado})
+		case !matriculado:
+			rejeitadas = append(rejeitadas, utils.ErroAluno{Indice: i, AlunoId: n.AlunoId, Motivo: motivoNaoMatriculado})
+		case !aluno.Ativo:
+			rejeitadas = append(rejeitadas, utils.ErroAluno{Indice: i, AlunoId: n.AlunoId, Motivo: motivoInativo})
+		case permitidos != nil && !permitidos[n.AlunoId]:
+			rejeitadas = append(rejeitadas, utils.ErroAluno{Indice: i, AlunoId: n.AlunoId, Motivo: motivoForaRecuperacao})
+		case !naEscala:
+			rejeitadas = append(rejeitadas, utils.ErroAluno{Indice: i, AlunoId: n.AlunoId, Motivo: motivoNotaForaEscala})
+		default:
+			n.Nota, n.NotaEscala = nota, notaEscala
+			validas = append(validas, n)
+		}
+		informados[n.AlunoId] = true
+	}
+
+	return validas, rejeitadas, nil
+}
+
+// registraNota cria ou atualiza a nota de um aluno em uma avaliação, registrando a alteração no histórico
+//
+// O registro informado deve conter AvaliacaoId, AlunoId, DisciplinaId e a nota já convertida para a escala interna,
+// com a penalidade por atraso aplicada.
+// Se o aluno já possuir a mesma nota, nada é alterado e nenhum histórico é gerado; caso contrário, o evento
+// nota.lancada é publicado para os webhooks. Deve ser chamada dentro de uma transação
+func registraNota(tx *gorm.DB, nota models.AlunoAvaliacao, autorId string, autorTipo string, justificativa string) (*models.AlunoAvaliacao, *utils.RestErr) {
+	// A inserção que encontra a nota já lançada não faz nada; assim, lançamentos simultâneos da mesma nota não violam
+	// o índice único e o segundo passa a atualizar a nota criada pelo primeiro
+	alunoAvaliacao := models.AlunoAvaliacao{
+		AlunoId:      nota.AlunoId,
+		AvaliacaoId:  nota.AvaliacaoId,
+		DisciplinaId: nota.DisciplinaId,
+		Nota:         nota.Nota,
+		NotaEscala:   nota.NotaEscala,
+		Penalidade:   nota.Penalidade,
+	}
+	resultado := tx.Omit(clause.Associations).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "aluno_id"}, {Name: "avaliacao_id"}}, DoNothing: true}).
+		Create(&alunoAvaliacao)
+	if resultado.Error != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar nota do aluno", resultado.Error)
+	}
+
+	var notaAnterior *float64
+	if resultado.RowsAffected == 0 {
+		alunoAvaliacao = models.AlunoAvaliacao{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("avaliacao_id = ? AND aluno_id = ?", nota.AvaliacaoId, nota.AlunoId).
+			First(&alunoAvaliacao).Error
+		if err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar nota do aluno", err)
+		}
+
+		if alunoAvaliacao.Nota == nota.Nota && alunoAvaliacao.NotaEscala == nota.NotaEscala && alunoAvaliacao.Penalidade == nota.Penalidade {
+			return &alunoAvaliacao, nil
+		}
+
+		valorAnterior := alunoAvaliacao.Nota
+		notaAnterior = &valorAnterior
+		alunoAvaliacao.Nota = nota.Nota
+		alunoAvaliacao.NotaEscala = nota.NotaEscala
+		alunoAvaliacao.Penalidade = nota.Penalidade
+		if err := tx.Omit(clause.Associations).Save(&alunoAvaliacao).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar nota do aluno", err)
+		}
+	}
+
+	notaNova := alunoAvaliacao.Nota
+	historico := models.AlunoAvaliacaoHistorico{
+		AvaliacaoId:   alunoAvaliacao.AvaliacaoId,
+		AlunoId:       alunoAvaliacao.AlunoId,
+		DisciplinaId:  alunoAvaliacao.DisciplinaId,
+		NotaAnterior:  notaAnterior,
+		NotaNova:      &notaNova,
+		AutorId:       autorId,
+		AutorTipo:     autorTipo,
+		Justificativa: justificativa,
+	}
+	if err := tx.Create(&historico).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar histórico de notas", err)
+	}
+
+	lancada := models.NotaLancada{AlunoAvaliacao: alunoAvaliacao, NotaAnterior: notaAnterior, AutorTipo: autorTipo}
+	if restErr := publicaEvento(tx, models.EventoNotaLancada, lancada); restErr != nil {
+		return nil, restErr
+	}
+
+	return &alunoAvaliacao, nil
+}
+
+// removeNota apaga a nota de um aluno em uma avaliação, registrando a remoção no histórico
+//
+// Deve ser chamada dentro de uma transação
+func removeNota(tx *gorm.DB, alunoAvaliacao models.AlunoAvaliacao, autorId string, autorTipo string, justificativa string) *utils.RestErr {
+	if err := tx.Delete(&alunoAvaliacao).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover nota do aluno", err)
+	}
+
+	anterior := alunoAvaliacao.Nota
+	historico := models.AlunoAvaliacaoHistorico{
+		AvaliacaoId:   alunoAvaliacao.AvaliacaoId,
+		AlunoId:       alunoAvaliacao.AlunoId,
+		DisciplinaId:  alunoAvaliacao.DisciplinaId,
+		NotaAnterior:  &anterior,
+		AutorId:       autorId,
+		AutorTipo:     autorTipo,
+		Justificativa: justificativa,
+	}
+	if err := tx.Create(&historico).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar histórico de notas", err)
+	}
+
+	return nil
+}
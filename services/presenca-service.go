@@ -0,0 +1,311 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"os"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// Motivos de rejeição de alunos em uma lista de chamada
+const (
+	motivoNaoMatriculado = "aluno não matriculado na disciplina"
+	motivoDuplicado      = "aluno informado mais de uma vez"
+	motivoInativo        = "aluno com matrícula trancada"
+	motivoHorasInvalidas = "horas presentes maiores que a duração da aula"
+)
+
+// CorrigirPresenca marca ou corrige a presença de alunos em uma aula já cadastrada
+//
+// Cada aluno informado deve estar matriculado na disciplina da aula. Alterações que não mudam o valor atual da
+// presença são ignoradas; as demais são registradas no histórico de presenças com o professor responsável e o motivo
+//
+// Retorna os registros de presença atualizados ou erro caso a aula não exista, a disciplina esteja fechada ou a
+// persistência falhe
+func CorrigirPresenca(aulaId string, correcao models.CorrecaoPresenca, professorId string) ([]models.AlunoAula, *utils.RestErr) {
+	aula, restErr := buscaAula(aulaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	disciplina, restErr := buscaDisciplina(aula.DisciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	var matriculados []string
+	err := database.DB.Model(&models.Aluno{}).
+		Joins("JOIN aluno_disciplina ON aluno_disciplina.aluno_id = alunos.id").
+		Where("aluno_disciplina.disciplina_id = ?", disciplina.Id).
+		Pluck("alunos.id", &matriculados).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos da disciplina", err)
+	}
+
+	alunos := make(map[string]bool, len(matriculados))
+	for _, id := range matriculados {
+		alunos[id] = true
+	}
+
+	var erros []utils.ErroAluno
+	informados := make(map[string]bool, len(correcao.Presencas))
+	for i, p := range correcao.Presencas {
+		switch {
+		case informados[p.AlunoId]:
+			erros = append(erros, utils.ErroAluno{Indice: i, AlunoId: p.AlunoId, Motivo: motivoDuplicado})
+		case !alunos[p.AlunoId]:
+			erros = append(erros, utils.ErroAluno{Indice: i, AlunoId: p.AlunoId, Motivo: motivoNaoMatriculado})
+		case p.HorasPresentes > aula.QuantidadeHoras:
+			erros = append(erros, utils.ErroAluno{Indice: i, AlunoId: p.AlunoId, Motivo: motivoHorasInvalidas})
+		}
+		informados[p.AlunoId] = true
+	}
+
+	if len(erros) > 0 {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Lista de presença inválida", nil, erros)
+	}
+
+	var presencas []models.AlunoAula
+	var restErrTx *utils.RestErr
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, p := range correcao.Presencas {
+			presenca := models.AlunoAula{
+				AulaId:         aula.Id,
+				AlunoId:        p.AlunoId,
+				Presenca:       *p.Presenca,
+				HorasPresentes: p.HorasPresentes,
+				Atraso:         p.Atraso,
+			}
+			normalizaHorasPresenca(&presenca, aula.QuantidadeHoras)
+
+			alunoAula, restErr := registraPresenca(tx, presenca, professorId, models.AutorProfessor, correcao.Motivo)
+			if restErr != nil {
+				restErrTx = restErr
+				return restErr.Err
+			}
+			presencas = append(presencas, *alunoAula)
+		}
+		return nil
+	})
+	if restErrTx != nil {
+		return nil, restErrTx
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao corrigir presenças", err)
+	}
+
+	agendaAvaliacaoFrequencia(disciplina.Id)
+	return presencas, nil
+}
+
+// ListarHistoricoPresencaAula retorna todas as alterações de presença registradas para uma aula
+//
+// Os registros são ordenados da alteração mais recente para a mais antiga
+func ListarHistoricoPresencaAula(aulaId string) ([]models.AlunoAulaHistorico, *utils.RestErr) {
+	if _, restErr := buscaAula(aulaId); restErr != nil {
+		return nil, restErr
+	}
+
+	var historico []models.AlunoAulaHistorico
+	err := database.DB.Where("aula_id = ?", aulaId).Order("created_at DESC").Find(&historico).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar histórico de presenças", err)
+	}
+
+	return historico, nil
+}
+
+// ListarHistoricoPresencaAluno retorna todas as alterações de presença registradas para um aluno
+//
+// Se `disciplinaId` for informado, apenas as alterações em aulas dessa disciplina são retornadas. Os registros são
+// ordenados da alteração mais recente para a mais antiga
+func ListarHistoricoPresencaAluno(alunoId string, disciplinaId string) ([]models.AlunoAulaHistorico, *utils.RestErr) {
+	if _, restErr := buscaAluno(alunoId); restErr != nil {
+		return nil, restErr
+	}
+
+	query := database.DB.Where("aluno_id = ?", alunoId)
+	if disciplinaId != "" {
+		query = query.Where("aula_id IN (?)", database.DB.Model(&models.Aula{}).Select("id").Where("disciplina_id = ?", disciplinaId))
+	}
+
+	var historico []models.AlunoAulaHistorico
+	if err := query.Order("created_at DESC").Find(&historico).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar histórico de presenças", err)
+	}
+
+	return historico, nil
+}
+
+// registraPresenca cria ou atualiza a presença de um aluno em uma aula, registrando a alteração no histórico
+//
+// O registro informado deve conter AulaId e AlunoId e já ter as horas presentes normalizadas. Se o aluno já possuir
+// a mesma presença, nada é alterado e nenhum histórico é gerado. Deve ser chamada dentro de uma transação
+func registraPresenca(tx *gorm.DB, presenca models.AlunoAula, autorId string, autorTipo string, motivo string) (*models.AlunoAula, *utils.RestErr) {
+	var alunoAula models.AlunoAula
+	err := tx.Where("aula_id = ? AND aluno_id = ?", presenca.AulaId, presenca.AlunoId).Limit(1).Find(&alunoAula).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar presença do aluno", err)
+	}
+
+	var presencaAnterior *bool
+	var horasAnteriores *int
+	if alunoAula.Id != "" {
+		if alunoAula.Presenca == presenca.Presenca &&
+			alunoAula.HorasPresentes == presenca.HorasPresentes &&
+			alunoAula.Atraso == presenca.Atraso {
+			return &alunoAula, nil
+		}
+
+		valorAnterior, horas := alunoAula.Presenca, alunoAula.HorasPresentes
+		presencaAnterior, horasAnteriores = &valorAnterior, &horas
+		alunoAula.Presenca = presenca.Presenca
+		alunoAula.HorasPresentes = presenca.HorasPresentes
+		alunoAula.Atraso = presenca.Atraso
+		if err := tx.Omit(clause.Associations).Save(&alunoAula).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar presença do aluno", err)
+		}
+	} else {
+		alunoAula = models.AlunoAula{
+			AulaId:         presenca.AulaId,
+			AlunoId:        presenca.AlunoId,
+			Presenca:       presenca.Presenca,
+			HorasPresentes: presenca.HorasPresentes,
+			Atraso:         presenca.Atraso,
+		}
+		if err := tx.Omit(clause.Associations).Create(&alunoAula).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar presença do aluno", err)
+		}
+	}
+
+	presencaNova, horasNovas := alunoAula.Presenca, alunoAula.HorasPresentes
+	historico := models.AlunoAulaHistorico{
+		AulaId:           alunoAula.AulaId,
+		AlunoId:          alunoAula.AlunoId,
+		PresencaAnterior: presencaAnterior,
+		PresencaNova:     &presencaNova,
+		HorasAnteriores:  horasAnteriores,
+		HorasNovas:       &horasNovas,
+		AutorId:          autorId,
+		AutorTipo:        autorTipo,
+		Motivo:           motivo,
+	}
+	if err := tx.Create(&historico).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar histórico de presença", err)
+	}
+
+	return &alunoAula, nil
+}
+
+// removePresenca apaga o registro de presença de um aluno em uma aula, registrando a remoção no histórico
+//
+// Deve ser chamada dentro de uma transação
+func removePresenca(tx *gorm.DB, alunoAula models.AlunoAula, autorId string, autorTipo string, motivo string) *utils.RestErr {
+	if err := tx.Delete(&alunoAula).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover presença do aluno", err)
+	}
+
+	anterior, horasAnteriores := alunoAula.Presenca, alunoAula.HorasPresentes
+	historico := models.AlunoAulaHistorico{
+		AulaId:           alunoAula.AulaId,
+		AlunoId:          alunoAula.AlunoId,
+		PresencaAnterior: &anterior,
+		HorasAnteriores:  &horasAnteriores,
+		AutorId:          autorId,
+		AutorTipo:        autorTipo,
+		Motivo:           motivo,
+	}
+	if err := tx.Create(&historico).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar histórico de presença", err)
+	}
+
+	return nil
+}
+
+// validaChamada confere a lista de presenças de uma aula com os alunos matriculados na disciplina
+//
+// Rejeita alunos não matriculados, inativos, informados mais de uma vez ou com mais horas presentes que a duração da
+// aula, retornando erro 400 com a lista de todos os alunos problemáticos. Alunos ativos matriculados que não foram
+// informados são incluídos com a presença padrão definida pela variável de ambiente PRESENCA_PADRAO ("ausente" ou
+// "presente"). As horas presentes de todos os registros são normalizadas conforme a duração da aula
+//
+// Retorna a lista de presenças completa ou erro caso a chamada seja inválida ou a consulta falhe
+func validaChamada(disciplinaId string, horasAula int, presencas []models.AlunoAula) ([]models.AlunoAula, *utils.RestErr) {
+	var matriculados []models.Aluno
+	err := database.DB.
+		Joins("JOIN aluno_disciplina ON aluno_disciplina.aluno_id = alunos.id").
+		Where("aluno_disciplina.disciplina_id = ?", disciplinaId).
+		Find(&matriculados).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar alunos da disciplina", err)
+	}
+
+	alunos := make(map[string]models.Aluno, len(matriculados))
+	for _, a := range matriculados {
+		alunos[a.Id] = a
+	}
+
+	var erros []utils.ErroAluno
+	informados := make(map[string]bool, len(presencas))
+	for i, p := range presencas {
+		aluno, matriculado := alunos[p.AlunoId]
+		switch {
+		case informados[p.AlunoId]:
+			erros = append(erros, utils.ErroAluno{Indice: i, AlunoId: p.AlunoId, Motivo: motivoDuplicado})
+		case !matriculado:
+			erros = append(erros, utils.ErroAluno{Indice: i, AlunoId: p.AlunoId, Motivo: motivoNaoMatriculado})
+		case !aluno.Ativo:
+			erros = append(erros, utils.ErroAluno{Indice: i, AlunoId: p.AlunoId, Motivo: motivoInativo})
+		case !normalizaHorasPresenca(&presencas[i], horasAula):
+			erros = append(erros, utils.ErroAluno{Indice: i, AlunoId: p.AlunoId, Motivo: motivoHorasInvalidas})
+		}
+		informados[p.AlunoId] = true
+	}
+
+	if len(erros) > 0 {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Lista de presença inválida", nil, erros)
+	}
+
+	padrao := presencaPadrao()
+	for _, a := range matriculados {
+		if a.Ativo && !informados[a.Id] {
+			presenca := models.AlunoAula{AlunoId: a.Id, Presenca: padrao}
+			normalizaHorasPresenca(&presenca, horasAula)
+			presencas = append(presencas, presenca)
+		}
+	}
+
+	return presencas, nil
+}
+
+// presencaPadrao retorna a presença atribuída aos alunos matriculados que não foram informados na chamada
+//
+// Lê a variável de ambiente PRESENCA_PADRAO; qualquer valor diferente de "presente" resulta em ausência
+func presencaPadrao() bool {
+	return os.Getenv("PRESENCA_PADRAO") == "presente"
+}
+
+// normalizaHorasPresenca ajusta as horas presentes de um registro de presença conforme a duração da aula
+//
+// Alunos ausentes ficam com zero horas e alunos presentes sem horas informadas recebem a duração completa da aula.
+//
+// Retorna false se as horas informadas forem maiores que a duração da aula
+func normalizaHorasPresenca(presenca *models.AlunoAula, horasAula int) bool {
+	if !presenca.Presenca {
+		presenca.HorasPresentes = 0
+		return true
+	}
+
+	if presenca.HorasPresentes == 0 {
+		presenca.HorasPresentes = horasAula
+	}
+
+	return presenca.HorasPresentes <= horasAula
+}
@@ -25,6 +25,7 @@ func CadastrarProfessor(professor models.Professor) (*models.Professor, *utils.R
 		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao criptografar senha", err)
 	}
 	professor.ConfirmarSenha = ""
+	professor.Coordenador = false
 
 	profExiste, restErr := buscaProfessorEmail(professor.Email)
 	if restErr != nil && restErr.Err != nil && !errors.Is(restErr.Err, gorm.ErrRecordNotFound) {
@@ -86,6 +87,21 @@ func RemoverProfessor(professorId string) *utils.RestErr {
 	return nil
 }
 
+// VerificarCoordenador confere se o professor é um coordenador, papel exigido pelas operações administrativas
+//
+// Retorna erro 403 caso o professor não seja coordenador ou erro caso ele não exista ou a consulta falhe
+func VerificarCoordenador(professorId string) *utils.RestErr {
+	professor, restErr := buscaProfessor(professorId)
+	if restErr != nil {
+		return restErr
+	}
+
+	if !professor.Coordenador {
+		return utils.NewRestErr(http.StatusForbidden, "Apenas coordenadores podem realizar esta operação", nil)
+	}
+	return nil
+}
+
 // geraToken cria um token JWT com os dados do professor autenticado com um tempo de expiração de 24 horas
 //
 // Retorna o token JWT como string ou erro em caso de falha ao assinar
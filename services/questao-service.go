@@ -0,0 +1,153 @@
+package services
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"strings"
+)
+
+// CadastrarQuestao adiciona uma questão ao banco de questões da matéria informada
+//
+// O gabarito é validado conforme o tipo da questão e os campos que não se aplicam ao tipo são descartados. A matéria
+// é normalizada para que disciplinas de mesmo nome compartilhem o banco
+//
+// Retorna a questão cadastrada ou erro em caso de falha de validação ou persistência
+func CadastrarQuestao(questao models.Questao, professorId string) (*models.Questao, *utils.RestErr) {
+	if restErr := validaQuestao(&questao); restErr != nil {
+		return nil, restErr
+	}
+
+	questao.Materia = normalizaMateria(questao.Materia)
+	questao.ProfessorId = professorId
+
+	if err := database.DB.Create(&questao).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar questão", err)
+	}
+
+	return &questao, nil
+}
+
+// ListarQuestoes retorna as questões do banco de uma matéria, com suas alternativas, das mais recentes para as mais
+// antigas
+//
+// Se `tipo` for informado, apenas as questões desse tipo são retornadas
+func ListarQuestoes(materia string, tipo string) ([]models.Questao, *utils.RestErr) {
+	if strings.TrimSpace(materia) == "" {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A matéria deve ser informada", nil)
+	}
+
+	query := database.DB.
+		Preload("Alternativas", func(db *gorm.DB) *gorm.DB { return db.Order("ordem") }).
+		Where("materia = ?", normalizaMateria(materia))
+	if tipo != "" {
+		query = query.Where("tipo = ?", tipo)
+	}
+
+	var questoes []models.Questao
+	if err := query.Order("created_at DESC").Find(&questoes).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar questões", err)
+	}
+
+	return questoes, nil
+}
+
+// RemoverQuestao apaga uma questão do banco de questões
+//
+// Apenas o professor que cadastrou a questão pode removê-la, e somente se ela não fizer parte de nenhum questionário.
+//
+// Retorna erro caso a questão não exista, pertença a outro professor, esteja em uso ou a remoção falhe
+func RemoverQuestao(id string, professorId string) *utils.RestErr {
+	var questao models.Questao
+	if err := database.DB.Where("id = ?", id).First(&questao).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NewRestErr(http.StatusNotFound, "Questão não encontrada", err)
+		}
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar questão", err)
+	}
+
+	if questao.ProfessorId != professorId {
+		return utils.NewRestErr(http.StatusForbidden, "Apenas o autor da questão pode removê-la", nil)
+	}
+
+	var usos int64
+	if err := database.DB.Model(&models.QuestionarioQuestao{}).Where("questao_id = ?", id).Count(&usos).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao verificar uso da questão", err)
+	}
+	if usos > 0 {
+		return utils.NewRestErr(http.StatusConflict, "A questão faz parte de um questionário e não pode ser removida", nil)
+	}
+
+	if err := database.DB.Delete(&questao).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover questão", err)
+	}
+
+	return nil
+}
+
+// validaQuestao verifica se o gabarito da questão é coerente com seu tipo e descarta os campos que não se aplicam
+//
+// Questões de múltipla escolha devem ter ao menos duas alternativas, exatamente uma correta; verdadeiro ou falso exige
+// RespostaBooleana; numéricas exigem RespostaNumerica; e resposta curta exige ao menos uma resposta aceita
+func validaQuestao(questao *models.Questao) *utils.RestErr {
+	questao.Id = ""
+	alternativas, booleana, numerica, aceitas := questao.Alternativas, questao.RespostaBooleana, questao.RespostaNumerica, questao.RespostasAceitas
+	questao.Alternativas, questao.RespostaBooleana, questao.RespostaNumerica, questao.RespostasAceitas = nil, nil, nil, nil
+	tolerancia := questao.Tolerancia
+	questao.Tolerancia = 0
+
+	switch questao.Tipo {
+	case models.QuestaoMultiplaEscolha:
+		if len(alternativas) < 2 {
+			return utils.NewRestErr(http.StatusBadRequest, "Questões de múltipla escolha devem ter ao menos duas alternativas", nil)
+		}
+
+		corretas := 0
+		for i := range alternativas {
+			alternativas[i].Id = ""
+			alternativas[i].Ordem = i
+			if alternativas[i].Correta {
+				corretas++
+			}
+		}
+		if corretas != 1 {
+			return utils.NewRestErr(http.StatusBadRequest, "Questões de múltipla escolha devem ter exatamente uma alternativa correta", nil)
+		}
+		questao.Alternativas = alternativas
+	case models.QuestaoVerdadeiroFalso:
+		if booleana == nil {
+			return utils.NewRestErr(http.StatusBadRequest, "Informe a resposta da questão de verdadeiro ou falso", nil)
+		}
+		questao.RespostaBooleana = booleana
+	case models.QuestaoNumerica:
+		if numerica == nil {
+			return utils.NewRestErr(http.StatusBadRequest, "Informe a resposta da questão numérica", nil)
+		}
+		questao.RespostaNumerica = numerica
+		questao.Tolerancia = tolerancia
+	case models.QuestaoRespostaCurta:
+		for _, resposta := range aceitas {
+			if normalizaResposta(resposta) != "" {
+				questao.RespostasAceitas = append(questao.RespostasAceitas, strings.TrimSpace(resposta))
+			}
+		}
+		if len(questao.RespostasAceitas) == 0 {
+			return utils.NewRestErr(http.StatusBadRequest, "Informe ao menos uma resposta aceita", nil)
+		}
+	}
+
+	return nil
+}
+
+// normalizaMateria converte o nome de uma disciplina na chave da matéria usada pelo banco de questões
+func normalizaMateria(nome string) string {
+	return strings.ToLower(strings.Join(strings.Fields(nome), " "))
+}
+
+// normalizaResposta prepara uma resposta curta para comparação, ignorando maiúsculas, minúsculas e espaços extras
+func normalizaResposta(resposta string) string {
+	return strings.ToLower(strings.Join(strings.Fields(resposta), " "))
+}
@@ -0,0 +1,692 @@
+package services
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
+	"math/rand"
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// motivoCorrecaoQuestionario é a justificativa registrada no histórico de notas pela correção automática
+const motivoCorrecaoQuestionario = "Correção automática do questionário"
+
+// MontarQuestionario cadastra ou substitui o questionário online de uma avaliação
+//
+// Apenas provas e trabalhos sem rubrica nem janela de entrega podem ser aplicados como questionário, com o semestre da
+// disciplina aberto e antes de qualquer aluno iniciar uma tentativa. As questões devem ser da matéria da disciplina e
+// aparecer uma única vez, e a quantidade sorteada por aluno não pode exceder o total de questões
+//
+// Retorna o questionário cadastrado ou erro em caso de falha de validação ou persistência
+func MontarQuestionario(disciplinaId string, avaliacaoId string, montagem models.MontagemQuestionario) (*models.Questionario, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if avaliacao.Recuperacao() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A avaliação de recuperação não pode ser aplicada como questionário", nil)
+	}
+
+	if restErr := verificaAvaliacaoAlteravel(disciplina, avaliacao.Tipo); restErr != nil {
+		return nil, restErr
+	}
+
+	if restErr := verificaNotaSemRubrica(avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	janela, restErr := buscaJanelaEntrega(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if janela != nil {
+		return nil, utils.NewRestErr(http.StatusConflict, "Trabalhos com janela de entrega não podem ser aplicados como questionário", nil)
+	}
+
+	var tentativas int64
+	if err := database.DB.Model(&models.Tentativa{}).Where("avaliacao_id = ?", avaliacaoId).Count(&tentativas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar tentativas do questionário", err)
+	}
+	if tentativas > 0 {
+		return nil, utils.NewRestErr(http.StatusConflict, "O questionário não pode ser alterado após o início das tentativas", nil)
+	}
+
+	if montagem.QuantidadeQuestoes > len(montagem.Questoes) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A quantidade de questões sorteadas excede o total de questões", nil)
+	}
+
+	ids := make([]string, 0, len(montagem.Questoes))
+	incluidas := make(map[string]bool, len(montagem.Questoes))
+	for _, item := range montagem.Questoes {
+		if incluidas[item.QuestaoId] {
+			return nil, utils.NewRestErr(http.StatusBadRequest, "Questão incluída mais de uma vez no questionário", nil)
+		}
+		incluidas[item.QuestaoId] = true
+		ids = append(ids, item.QuestaoId)
+	}
+
+	var encontradas int64
+	err := database.DB.Model(&models.Questao{}).
+		Where("id IN ? AND materia = ?", ids, normalizaMateria(disciplina.Nome)).
+		Count(&encontradas).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar questões", err)
+	}
+	if int(encontradas) != len(ids) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Todas as questões devem existir no banco de questões da matéria da disciplina", nil)
+	}
+
+	quantidade := montagem.QuantidadeQuestoes
+	if quantidade == 0 {
+		quantidade = len(montagem.Questoes)
+	}
+
+	questionario := models.Questionario{
+		AvaliacaoId:        avaliacaoId,
+		Inicio:             montagem.Inicio,
+		Fim:                montagem.Fim,
+		DuracaoMinutos:     montagem.DuracaoMinutos,
+		QuantidadeQuestoes: quantidade,
+	}
+	for i, item := range montagem.Questoes {
+		questionario.Questoes = append(questionario.Questoes, models.QuestionarioQuestao{
+			QuestaoId: item.QuestaoId,
+			Pontos:    item.Pontos,
+			Ordem:     i,
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("avaliacao_id = ?", avaliacaoId).Delete(&models.Questionario{}).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover questionário anterior", err)
+			return err
+		}
+
+		if err := tx.Create(&questionario).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar questionário", err)
+			return err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar questionário", err)
+	}
+
+	return &questionario, nil
+}
+
+// GetQuestionario retorna o questionário de uma avaliação com as questões e o gabarito
+func GetQuestionario(disciplinaId string, avaliacaoId string) (*models.Questionario, *utils.RestErr) {
+	if _, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	return buscaQuestionario(avaliacaoId)
+}
+
+// ListarTentativas retorna as tentativas dos alunos em um questionário, com as respostas de cada uma
+//
+// As tentativas cujo tempo terminou sem finalização são corrigidas antes da listagem
+func ListarTentativas(disciplinaId string, avaliacaoId string) ([]models.Tentativa, *utils.RestErr) {
+	if _, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	if restErr := finalizaTentativasExpiradas(avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	var tentativas []models.Tentativa
+	err := database.DB.Preload("Aluno").Preload("Respostas").
+		Where("avaliacao_id = ?", avaliacaoId).
+		Order("iniciada_em").
+		Find(&tentativas).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar tentativas", err)
+	}
+
+	return tentativas, nil
+}
+
+// IniciarTentativa inicia a tentativa de um aluno em um questionário ou retoma a tentativa em andamento
+//
+// O aluno deve estar matriculado e ativo na disciplina, que deve estar com o semestre aberto, e a tentativa só pode
+// ser iniciada dentro do período do questionário. O tempo da tentativa termina após a duração configurada ou no fim
+// do período, o que ocorrer primeiro
+//
+// Retorna o questionário como apresentado ao aluno ou erro caso a tentativa não possa ser iniciada
+func IniciarTentativa(avaliacaoId string, alunoId string) (*models.ProvaAluno, *utils.RestErr) {
+	questionario, restErr := buscaQuestionario(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	tentativa, restErr := buscaTentativa(avaliacaoId, alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if tentativa != nil {
+		return retomaTentativa(tentativa, questionario)
+	}
+
+	var avaliacao models.Avaliacao
+	if err := database.DB.Where("id = ?", avaliacaoId).First(&avaliacao).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliação", err)
+	}
+
+	disciplina, restErr := buscaDisciplina(avaliacao.DisciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !disciplina.Aberta() {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O semestre da disciplina já foi fechado", nil)
+	}
+
+	aluno, restErr := buscaAluno(alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	matriculado, restErr := alunoMatriculado(disciplina.Id, alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !matriculado {
+		return nil, utils.NewRestErr(http.StatusForbidden, "Aluno não está matriculado na disciplina", nil)
+	}
+	if !aluno.Ativo {
+		return nil, utils.NewRestErr(http.StatusForbidden, "Aluno com matrícula trancada", nil)
+	}
+
+	agora := time.Now()
+	if agora.Before(questionario.Inicio) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O questionário ainda não está disponível", nil)
+	}
+	if !agora.Before(questionario.Fim) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O período do questionário foi encerrado", nil)
+	}
+
+	expiraEm := agora.Add(time.Duration(questionario.DuracaoMinutos) * time.Minute)
+	if expiraEm.After(questionario.Fim) {
+		expiraEm = questionario.Fim
+	}
+
+	tentativa = &models.Tentativa{
+		QuestionarioId: questionario.Id,
+		AvaliacaoId:    avaliacaoId,
+		AlunoId:        alunoId,
+		Semente:        rand.Int63(),
+		IniciadaEm:     agora,
+		ExpiraEm:       expiraEm,
+	}
+	// Se o aluno iniciar a tentativa em duas requisições simultâneas, a segunda não grava nada e retoma a tentativa
+	// gravada pela primeira
+	resultado := database.DB.Omit(clause.Associations).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "avaliacao_id"}, {Name: "aluno_id"}}, DoNothing: true}).
+		Create(tentativa)
+	if resultado.Error != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao iniciar tentativa", resultado.Error)
+	}
+	if resultado.RowsAffected == 0 {
+		tentativa, restErr = buscaTentativa(avaliacaoId, alunoId)
+		if restErr != nil {
+			return nil, restErr
+		}
+		if tentativa == nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao iniciar tentativa", nil)
+		}
+		return retomaTentativa(tentativa, questionario)
+	}
+
+	return provaAluno(tentativa, questionario)
+}
+
+// retomaTentativa retorna a tentativa já iniciada por um aluno, finalizando-a se o tempo tiver terminado
+//
+// Retorna erro caso a tentativa já esteja finalizada
+func retomaTentativa(tentativa *models.Tentativa, questionario *models.Questionario) (*models.ProvaAluno, *utils.RestErr) {
+	if !tentativa.Finalizada() && time.Now().After(tentativa.ExpiraEm) {
+		if restErr := finalizaTentativa(tentativa, questionario); restErr != nil {
+			return nil, restErr
+		}
+	}
+	if tentativa.Finalizada() {
+		return nil, utils.NewRestErr(http.StatusConflict, "O questionário já foi finalizado", nil)
+	}
+	return provaAluno(tentativa, questionario)
+}
+
+// GetTentativaAluno retorna o questionário como apresentado ao aluno, com as respostas enviadas e, após a
+// finalização, a nota obtida
+func GetTentativaAluno(avaliacaoId string, alunoId string) (*models.ProvaAluno, *utils.RestErr) {
+	questionario, restErr := buscaQuestionario(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	tentativa, restErr := buscaTentativa(avaliacaoId, alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if tentativa == nil {
+		return nil, utils.NewRestErr(http.StatusNotFound, "O aluno ainda não iniciou o questionário", nil)
+	}
+
+	if !tentativa.Finalizada() && time.Now().After(tentativa.ExpiraEm) {
+		if restErr := finalizaTentativa(tentativa, questionario); restErr != nil {
+			return nil, restErr
+		}
+	}
+
+	return provaAluno(tentativa, questionario)
+}
+
+// ResponderQuestao grava ou substitui a resposta de um aluno a uma questão da sua tentativa em andamento
+//
+// Retorna erro caso a tentativa não exista, já tenha terminado ou a questão não faça parte dela
+func ResponderQuestao(avaliacaoId string, alunoId string, resposta models.RespostaQuestao) (*models.RespostaTentativa, *utils.RestErr) {
+	questionario, restErr := buscaQuestionario(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	tentativa, restErr := buscaTentativa(avaliacaoId, alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if tentativa == nil {
+		return nil, utils.NewRestErr(http.StatusNotFound, "O aluno ainda não iniciou o questionário", nil)
+	}
+	if tentativa.Finalizada() || time.Now().After(tentativa.ExpiraEm) {
+		return nil, utils.NewRestErr(http.StatusConflict, "O tempo do questionário terminou", nil)
+	}
+
+	pertence := false
+	for _, item := range questoesTentativa(questionario, tentativa.Semente) {
+		if item.QuestaoId == resposta.QuestaoId {
+			pertence = true
+			break
+		}
+	}
+	if !pertence {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A questão não faz parte da tentativa do aluno", nil)
+	}
+
+	registro := models.RespostaTentativa{
+		TentativaId: tentativa.Id,
+		QuestaoId:   resposta.QuestaoId,
+		Resposta:    strings.TrimSpace(resposta.Resposta),
+	}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tentativa_id"}, {Name: "questao_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"resposta"}),
+	}).Create(&registro).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar resposta", err)
+	}
+
+	return &registro, nil
+}
+
+// FinalizarTentativa encerra a tentativa em andamento de um aluno, corrige as respostas e lança a nota
+//
+// Retorna o resultado do aluno ou erro caso a tentativa não exista ou já tenha sido finalizada
+func FinalizarTentativa(avaliacaoId string, alunoId string) (*models.ProvaAluno, *utils.RestErr) {
+	questionario, restErr := buscaQuestionario(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	tentativa, restErr := buscaTentativa(avaliacaoId, alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if tentativa == nil {
+		return nil, utils.NewRestErr(http.StatusNotFound, "O aluno ainda não iniciou o questionário", nil)
+	}
+	if tentativa.Finalizada() {
+		return nil, utils.NewRestErr(http.StatusConflict, "O questionário já foi finalizado", nil)
+	}
+
+	if restErr := finalizaTentativa(tentativa, questionario); restErr != nil {
+		return nil, restErr
+	}
+
+	return provaAluno(tentativa, questionario)
+}
+
+// finalizaTentativasExpiradas corrige as tentativas de uma avaliação cujo tempo terminou sem finalização
+func finalizaTentativasExpiradas(avaliacaoId string) *utils.RestErr {
+	var expiradas []models.Tentativa
+	err := database.DB.Where("avaliacao_id = ? AND finalizada_em IS NULL AND expira_em < ?", avaliacaoId, time.Now()).Find(&expiradas).Error
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar tentativas expiradas", err)
+	}
+	if len(expiradas) == 0 {
+		return nil
+	}
+
+	questionario, restErr := buscaQuestionario(avaliacaoId)
+	if restErr != nil {
+		return restErr
+	}
+
+	for i := range expiradas {
+		if restErr := finalizaTentativa(&expiradas[i], questionario); restErr != nil {
+			return restErr
+		}
+	}
+	return nil
+}
+
+// finalizaTentativasDisciplina corrige as tentativas expiradas de todos os questionários de uma disciplina
+//
+// É chamada antes do fechamento do semestre para que as notas dos questionários não finalizados pelos alunos sejam
+// consideradas no cálculo das médias
+func finalizaTentativasDisciplina(disciplinaId string) *utils.RestErr {
+	var avaliacaoIds []string
+	err := database.DB.Model(&models.Questionario{}).
+		Joins("JOIN avaliacoes ON avaliacoes.id = questionarios.avaliacao_id").
+		Where("avaliacoes.disciplina_id = ?", disciplinaId).
+		Pluck("questionarios.avaliacao_id", &avaliacaoIds).Error
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar questionários da disciplina", err)
+	}
+
+	for _, avaliacaoId := range avaliacaoIds {
+		if restErr := finalizaTentativasExpiradas(avaliacaoId); restErr != nil {
+			return restErr
+		}
+	}
+	return nil
+}
+
+// finalizaTentativa corrige as respostas de uma tentativa e lança a nota do aluno na avaliação
+//
+// A nota é a proporção entre os pontos obtidos e os pontos das questões apresentadas ao aluno, na escala interna de
+// 0 a 10. Se o semestre da disciplina já tiver sido fechado, a tentativa é corrigida mas a nota não é lançada. Se a
+// tentativa já tiver sido finalizada por outra requisição, nada é alterado e `tentativa` é recarregada do banco
+func finalizaTentativa(tentativa *models.Tentativa, questionario *models.Questionario) *utils.RestErr {
+	var avaliacao models.Avaliacao
+	if err := database.DB.Where("id = ?", tentativa.AvaliacaoId).First(&avaliacao).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar avaliação", err)
+	}
+
+	disciplina, restErr := buscaDisciplina(avaliacao.DisciplinaId)
+	if restErr != nil {
+		return restErr
+	}
+
+	var respostas []models.RespostaTentativa
+	if err := database.DB.Where("tentativa_id = ?", tentativa.Id).Find(&respostas).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar respostas", err)
+	}
+
+	porQuestao := make(map[string]*models.RespostaTentativa, len(respostas))
+	for i := range respostas {
+		porQuestao[respostas[i].QuestaoId] = &respostas[i]
+	}
+
+	var pontos, total float64
+	for _, item := range questoesTentativa(questionario, tentativa.Semente) {
+		total += item.Pontos
+		resposta, ok := porQuestao[item.QuestaoId]
+		if !ok {
+			continue
+		}
+
+		resposta.Correta = respostaCorreta(item.Questao, resposta.Resposta)
+		resposta.Pontos = 0
+		if resposta.Correta {
+			resposta.Pontos = item.Pontos
+			pontos += item.Pontos
+		}
+	}
+
+	agora := time.Now()
+	if agora.After(tentativa.ExpiraEm) {
+		agora = tentativa.ExpiraEm
+	}
+	var nota float64
+	if total > 0 {
+		nota = math.Round(pontos/total*1000) / 100
+	}
+	notaEscala := converteMedia(disciplina.Escala, nota)
+
+	err := transacaoEventos(func(tx *gorm.DB) error {
+		// A tentativa só é finalizada se ainda estiver em aberto: se outra requisição a finalizou ao mesmo tempo, a
+		// correção e a nota registradas por ela são mantidas
+		resultado := tx.Model(&models.Tentativa{}).
+			Where("id = ? AND finalizada_em IS NULL", tentativa.Id).
+			Updates(map[string]interface{}{
+				"finalizada_em": agora,
+				"pontos":        pontos,
+				"pontos_total":  total,
+				"nota":          nota,
+				"nota_escala":   notaEscala,
+			})
+		if resultado.Error != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao finalizar tentativa", resultado.Error)
+			return resultado.Error
+		}
+		if resultado.RowsAffected == 0 {
+			if err := tx.Where("id = ?", tentativa.Id).First(tentativa).Error; err != nil {
+				restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar tentativa", err)
+				return err
+			}
+			return nil
+		}
+
+		tentativa.FinalizadaEm = &agora
+		tentativa.Pontos = pontos
+		tentativa.PontosTotal = total
+		tentativa.Nota = nota
+		tentativa.NotaEscala = notaEscala
+
+		for i := range respostas {
+			if err := tx.Model(&respostas[i]).Select("correta", "pontos").Updates(&respostas[i]).Error; err != nil {
+				restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao corrigir respostas", err)
+				return err
+			}
+		}
+
+		if !disciplina.Aberta() {
+			return nil
+		}
+
+		alunoNota := models.AlunoAvaliacao{
+			AlunoId:      tentativa.AlunoId,
+			AvaliacaoId:  tentativa.AvaliacaoId,
+			DisciplinaId: disciplina.Id,
+			Nota:         nota,
+			NotaEscala:   notaEscala,
+		}
+		if _, restErr = registraNota(tx, alunoNota, "", models.AutorSistema, motivoCorrecaoQuestionario); restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return restErr
+	}
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao finalizar tentativa", err)
+	}
+
+	return nil
+}
+
+// questoesTentativa retorna as questões apresentadas em uma tentativa, na ordem sorteada para o aluno
+//
+// O sorteio é determinado pela semente da tentativa, de modo que o aluno sempre recebe as mesmas questões na mesma
+// ordem. As alternativas de cada questão também são embaralhadas
+func questoesTentativa(questionario *models.Questionario, semente int64) []models.QuestionarioQuestao {
+	sorteio := rand.New(rand.NewSource(semente))
+
+	itens := make([]models.QuestionarioQuestao, len(questionario.Questoes))
+	copy(itens, questionario.Questoes)
+	sort.SliceStable(itens, func(i, j int) bool { return itens[i].Ordem < itens[j].Ordem })
+	sorteio.Shuffle(len(itens), func(i, j int) { itens[i], itens[j] = itens[j], itens[i] })
+
+	if questionario.QuantidadeQuestoes > 0 && questionario.QuantidadeQuestoes < len(itens) {
+		itens = itens[:questionario.QuantidadeQuestoes]
+	}
+
+	for i := range itens {
+		if itens[i].Questao == nil {
+			continue
+		}
+		questao := *itens[i].Questao
+		questao.Alternativas = make([]models.AlternativaQuestao, len(itens[i].Questao.Alternativas))
+		copy(questao.Alternativas, itens[i].Questao.Alternativas)
+		sorteio.Shuffle(len(questao.Alternativas), func(a, b int) {
+			questao.Alternativas[a], questao.Alternativas[b] = questao.Alternativas[b], questao.Alternativas[a]
+		})
+		itens[i].Questao = &questao
+	}
+
+	return itens
+}
+
+// respostaCorreta compara a resposta de um aluno com o gabarito da questão
+func respostaCorreta(questao *models.Questao, resposta string) bool {
+	if questao == nil {
+		return false
+	}
+
+	switch questao.Tipo {
+	case models.QuestaoMultiplaEscolha:
+		for _, alternativa := range questao.Alternativas {
+			if alternativa.Id == resposta {
+				return alternativa.Correta
+			}
+		}
+	case models.QuestaoVerdadeiroFalso:
+		if questao.RespostaBooleana == nil {
+			return false
+		}
+		switch normalizaResposta(resposta) {
+		case "verdadeiro", "v", "true":
+			return *questao.RespostaBooleana
+		case "falso", "f", "false":
+			return !*questao.RespostaBooleana
+		}
+	case models.QuestaoNumerica:
+		if questao.RespostaNumerica == nil {
+			return false
+		}
+		valor, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(resposta), ",", ".", 1), 64)
+		if err != nil {
+			return false
+		}
+		return math.Abs(valor-*questao.RespostaNumerica) <= questao.Tolerancia+1e-9
+	case models.QuestaoRespostaCurta:
+		normalizada := normalizaResposta(resposta)
+		for _, aceita := range questao.RespostasAceitas {
+			if normalizada != "" && normalizada == normalizaResposta(aceita) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// provaAluno monta a visão do aluno de uma tentativa, sem o gabarito das questões
+func provaAluno(tentativa *models.Tentativa, questionario *models.Questionario) (*models.ProvaAluno, *utils.RestErr) {
+	var respostas []models.RespostaTentativa
+	if err := database.DB.Where("tentativa_id = ?", tentativa.Id).Find(&respostas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar respostas", err)
+	}
+
+	porQuestao := make(map[string]string, len(respostas))
+	for _, r := range respostas {
+		porQuestao[r.QuestaoId] = r.Resposta
+	}
+
+	prova := &models.ProvaAluno{
+		TentativaId:  tentativa.Id,
+		AvaliacaoId:  tentativa.AvaliacaoId,
+		IniciadaEm:   tentativa.IniciadaEm,
+		ExpiraEm:     tentativa.ExpiraEm,
+		FinalizadaEm: tentativa.FinalizadaEm,
+		Questoes:     []models.QuestaoAluno{},
+	}
+	if tentativa.Finalizada() {
+		prova.Pontos = &tentativa.Pontos
+		prova.Nota = &tentativa.Nota
+		prova.NotaEscala = tentativa.NotaEscala
+	}
+
+	for _, item := range questoesTentativa(questionario, tentativa.Semente) {
+		prova.PontosTotal += item.Pontos
+		if item.Questao == nil {
+			continue
+		}
+
+		questao := models.QuestaoAluno{
+			Id:        item.QuestaoId,
+			Tipo:      item.Questao.Tipo,
+			Enunciado: item.Questao.Enunciado,
+			Pontos:    item.Pontos,
+			Resposta:  porQuestao[item.QuestaoId],
+		}
+		for _, alternativa := range item.Questao.Alternativas {
+			questao.Alternativas = append(questao.Alternativas, models.AlternativaAluno{Id: alternativa.Id, Texto: alternativa.Texto})
+		}
+		prova.Questoes = append(prova.Questoes, questao)
+	}
+
+	return prova, nil
+}
+
+// buscaQuestionario busca o questionário de uma avaliação com as questões, o gabarito e as alternativas
+//
+// Retorna erro 404 caso a avaliação não seja aplicada como questionário
+func buscaQuestionario(avaliacaoId string) (*models.Questionario, *utils.RestErr) {
+	var questionario models.Questionario
+	err := database.DB.
+		Preload("Questoes", func(db *gorm.DB) *gorm.DB { return db.Order("ordem") }).
+		Preload("Questoes.Questao").
+		Preload("Questoes.Questao.Alternativas", func(db *gorm.DB) *gorm.DB { return db.Order("ordem") }).
+		Where("avaliacao_id = ?", avaliacaoId).
+		First(&questionario).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "A avaliação não possui questionário online", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar questionário", err)
+	}
+
+	return &questionario, nil
+}
+
+// buscaTentativa retorna a tentativa de um aluno em uma avaliação ou nil, se ele ainda não tiver iniciado
+func buscaTentativa(avaliacaoId string, alunoId string) (*models.Tentativa, *utils.RestErr) {
+	var tentativa models.Tentativa
+	err := database.DB.Where("avaliacao_id = ? AND aluno_id = ?", avaliacaoId, alunoId).Limit(1).Find(&tentativa).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar tentativa", err)
+	}
+
+	if tentativa.Id == "" {
+		return nil, nil
+	}
+	return &tentativa, nil
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// ListarResultadosDisciplina retorna os resultados finais dos alunos de uma disciplina, com a situação de cada um
+//
+// Se `situacao` for informada, apenas os resultados nessa situação são retornados
+func ListarResultadosDisciplina(disciplinaId string, situacao string) ([]models.AlunoMedia, *utils.RestErr) {
+	if _, restErr := buscaDisciplina(disciplinaId); restErr != nil {
+		return nil, restErr
+	}
+
+	query := database.DB.Where("disciplina_id = ?", disciplinaId)
+	if situacao != "" {
+		query = query.Where("situacao = ?", situacao)
+	}
+
+	var medias []models.AlunoMedia
+	if err := query.Order("created_at").Find(&medias).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar resultados da disciplina", err)
+	}
+
+	return medias, nil
+}
+
+// ListarHistoricoEscolar retorna o histórico escolar de um aluno: o resultado final em cada disciplina cursada
+//
+// Os resultados são ordenados por semestre e nome da disciplina
+func ListarHistoricoEscolar(alunoId string) ([]models.ItemHistoricoEscolar, *utils.RestErr) {
+	if _, restErr := buscaAluno(alunoId); restErr != nil {
+		return nil, restErr
+	}
+
+	var historico []models.ItemHistoricoEscolar
+	err := database.DB.Model(&models.AlunoMedia{}).
+		Select("aluno_media.disciplina_id, disciplinas.nome AS disciplina, disciplinas.ano_semestre, "+
+			"aluno_media.media_final, aluno_media.media_convertida, aluno_media.frequencia, aluno_media.situacao, "+
+			"aluno_media.aprovado, aluno_media.created_at").
+		Joins("JOIN disciplinas ON disciplinas.id = aluno_media.disciplina_id").
+		Where("aluno_media.aluno_id = ?", alunoId).
+		Order("disciplinas.ano_semestre, disciplinas.nome").
+		Scan(&historico).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar histórico escolar", err)
+	}
+
+	return historico, nil
+}
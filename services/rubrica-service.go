@@ -0,0 +1,396 @@
+package services
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"math"
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"strings"
+)
+
+// motivoCorrecaoRubrica é a justificativa registrada no histórico quando a nota é calculada pela rubrica sem justificativa
+const motivoCorrecaoRubrica = "Correção por rubrica"
+
+// DefinirRubrica cadastra ou substitui a rubrica de correção de um trabalho
+//
+// A rubrica só pode ser definida em avaliações do tipo trabalho, com o semestre aberto e enquanto nenhuma nota tiver
+// sido lançada na avaliação. Os nomes dos critérios e dos níveis de cada critério devem ser únicos e a pontuação
+// máxima da rubrica deve ser maior que zero. Critérios e níveis são ordenados conforme a ordem em que foram enviados
+//
+// Retorna a rubrica cadastrada ou erro caso a avaliação não permita rubricas ou a persistência falhe
+func DefinirRubrica(disciplinaId string, avaliacaoId string, rubrica models.Rubrica) (*models.Rubrica, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if avaliacao.Tipo != models.AvaliacaoTrabalho {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Rubricas só podem ser usadas em trabalhos", nil)
+	}
+
+	if restErr := verificaAvaliacaoAlteravel(disciplina, avaliacao.Tipo); restErr != nil {
+		return nil, restErr
+	}
+
+	var notas int64
+	if err := database.DB.Model(&models.AlunoAvaliacao{}).Where("avaliacao_id = ?", avaliacaoId).Count(&notas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar notas da avaliação", err)
+	}
+	if notas > 0 {
+		return nil, utils.NewRestErr(http.StatusConflict, "A rubrica não pode ser alterada após o lançamento de notas", nil)
+	}
+
+	if restErr := validaRubrica(&rubrica); restErr != nil {
+		return nil, restErr
+	}
+
+	rubrica.AvaliacaoId = avaliacaoId
+	rubrica.DisciplinaId = disciplinaId
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("avaliacao_id = ?", avaliacaoId).Delete(&models.Rubrica{}).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover rubrica anterior", err)
+			return err
+		}
+
+		if err := tx.Create(&rubrica).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar rubrica", err)
+			return err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar rubrica", err)
+	}
+
+	return &rubrica, nil
+}
+
+// GetRubrica retorna a rubrica de uma avaliação com seus critérios e níveis
+func GetRubrica(disciplinaId string, avaliacaoId string) (*models.Rubrica, *utils.RestErr) {
+	if _, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	return buscaRubrica(avaliacaoId)
+}
+
+// CorrigirRubrica corrige o trabalho de um aluno pela rubrica da avaliação e calcula sua nota
+//
+// Cada critério da rubrica deve receber exatamente um nível, pertencente ao próprio critério. A nota é a proporção
+// entre os pontos obtidos e a pontuação máxima da rubrica, na escala interna de 0 a 10, e é registrada no histórico
+// de notas. Uma nova correção substitui a anterior. O aluno deve estar matriculado e ativo na disciplina, que deve
+// estar com o semestre aberto. Se a entrega do aluno estiver atrasada, a penalidade da janela de entrega é descontada
+//
+// Retorna a nota calculada e o retorno de cada critério ou erro caso a correção seja inválida ou a persistência falhe
+func CorrigirRubrica(disciplinaId string, avaliacaoId string, alunoId string, correcao models.CorrecaoRubrica, professorId string) (*models.FeedbackRubrica, *utils.RestErr) {
+	disciplina, restErr := buscaDisciplina(disciplinaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	avaliacao, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if restErr := verificaNotaAlteravel(disciplina, avaliacao); restErr != nil {
+		return nil, restErr
+	}
+
+	rubrica, restErr := buscaRubrica(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	aluno, restErr := buscaAluno(alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	matriculado, restErr := alunoMatriculado(disciplinaId, alunoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+	if !matriculado {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Aluno não matriculado na disciplina", nil)
+	}
+	if !aluno.Ativo {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Aluno com matrícula trancada", nil)
+	}
+
+	criterios := make(map[string]*models.CriterioRubrica, len(rubrica.Criterios))
+	for i := range rubrica.Criterios {
+		criterios[rubrica.Criterios[i].Id] = &rubrica.Criterios[i]
+	}
+
+	var correcoes []models.AlunoRubrica
+	var pontos float64
+	corrigidos := make(map[string]bool, len(correcao.Criterios))
+	for _, c := range correcao.Criterios {
+		criterio, ok := criterios[c.CriterioId]
+		if !ok {
+			return nil, utils.NewRestErr(http.StatusBadRequest, "Critério não pertence à rubrica da avaliação", nil)
+		}
+		if corrigidos[c.CriterioId] {
+			return nil, utils.NewRestErr(http.StatusBadRequest, "Critério informado mais de uma vez: "+criterio.Nome, nil)
+		}
+		corrigidos[c.CriterioId] = true
+
+		nivel := buscaNivel(criterio, c.NivelId)
+		if nivel == nil {
+			return nil, utils.NewRestErr(http.StatusBadRequest, "Nível não pertence ao critério: "+criterio.Nome, nil)
+		}
+
+		pontos += nivel.Pontos
+		correcoes = append(correcoes, models.AlunoRubrica{
+			AlunoId:     alunoId,
+			AvaliacaoId: avaliacaoId,
+			CriterioId:  criterio.Id,
+			NivelId:     nivel.Id,
+			Pontos:      nivel.Pontos,
+			Feedback:    strings.TrimSpace(c.Feedback),
+		})
+	}
+
+	if len(corrigidos) != len(rubrica.Criterios) {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "Todos os critérios da rubrica devem ser corrigidos", nil)
+	}
+
+	penalidades, restErr := penalidadesAtraso(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	alunoNota := models.AlunoAvaliacao{
+		AlunoId:      alunoId,
+		AvaliacaoId:  avaliacaoId,
+		DisciplinaId: disciplinaId,
+		Nota:         math.Round(pontos/rubrica.PontuacaoMaxima*1000) / 100,
+	}
+	alunoNota.NotaEscala = converteMedia(disciplina.Escala, alunoNota.Nota)
+	aplicaPenalidade(disciplina.Escala, &alunoNota, penalidades[alunoId])
+
+	justificativa := correcao.Justificativa
+	if justificativa == "" {
+		justificativa = motivoCorrecaoRubrica
+	}
+
+	err := transacaoEventos(func(tx *gorm.DB) error {
+		if err := tx.Where("avaliacao_id = ? AND aluno_id = ?", avaliacaoId, alunoId).Delete(&models.AlunoRubrica{}).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover correção anterior", err)
+			return err
+		}
+
+		if err := tx.Create(&correcoes).Error; err != nil {
+			restErr = utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar correção", err)
+			return err
+		}
+
+		if _, restErr = registraNota(tx, alunoNota, professorId, models.AutorProfessor, justificativa); restErr != nil {
+			return restErr.Err
+		}
+		return nil
+	})
+	if restErr != nil {
+		return nil, restErr
+	}
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar correção", err)
+	}
+
+	return feedbackRubrica(rubrica, alunoId)
+}
+
+// GetFeedbackRubrica retorna a correção por rubrica de um aluno em uma avaliação da disciplina
+func GetFeedbackRubrica(disciplinaId string, avaliacaoId string, alunoId string) (*models.FeedbackRubrica, *utils.RestErr) {
+	if _, restErr := buscaAvaliacaoDisciplina(disciplinaId, avaliacaoId); restErr != nil {
+		return nil, restErr
+	}
+
+	rubrica, restErr := buscaRubrica(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	return feedbackRubrica(rubrica, alunoId)
+}
+
+// GetFeedbackRubricaAluno retorna ao aluno autenticado a correção por rubrica do seu trabalho em uma avaliação
+//
+// Retorna erro 404 caso a avaliação não tenha rubrica ou o trabalho do aluno ainda não tenha sido corrigido
+func GetFeedbackRubricaAluno(avaliacaoId string, alunoId string) (*models.FeedbackRubrica, *utils.RestErr) {
+	rubrica, restErr := buscaRubrica(avaliacaoId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	return feedbackRubrica(rubrica, alunoId)
+}
+
+// validaRubrica verifica os critérios e níveis de uma rubrica e calcula sua pontuação máxima
+//
+// Também limpa os identificadores enviados e define a ordem dos critérios e níveis conforme a posição na requisição
+func validaRubrica(rubrica *models.Rubrica) *utils.RestErr {
+	rubrica.Id = ""
+	rubrica.PontuacaoMaxima = 0
+
+	criterios := make(map[string]bool, len(rubrica.Criterios))
+	for i := range rubrica.Criterios {
+		criterio := &rubrica.Criterios[i]
+		nome := strings.ToLower(strings.TrimSpace(criterio.Nome))
+		if criterios[nome] {
+			return utils.NewRestErr(http.StatusBadRequest, "Critério repetido na rubrica: "+criterio.Nome, nil)
+		}
+		criterios[nome] = true
+
+		criterio.Id = ""
+		criterio.Ordem = i
+
+		var maximo float64
+		niveis := make(map[string]bool, len(criterio.Niveis))
+		for j := range criterio.Niveis {
+			nivel := &criterio.Niveis[j]
+			nomeNivel := strings.ToLower(strings.TrimSpace(nivel.Nome))
+			if niveis[nomeNivel] {
+				return utils.NewRestErr(http.StatusBadRequest, "Nível repetido no critério: "+criterio.Nome, nil)
+			}
+			niveis[nomeNivel] = true
+
+			nivel.Id = ""
+			nivel.Ordem = j
+			maximo = math.Max(maximo, nivel.Pontos)
+		}
+		rubrica.PontuacaoMaxima += maximo
+	}
+
+	if rubrica.PontuacaoMaxima <= 0 {
+		return utils.NewRestErr(http.StatusBadRequest, "A pontuação máxima da rubrica deve ser maior que zero", nil)
+	}
+	return nil
+}
+
+// buscaRubrica busca a rubrica de uma avaliação com seus critérios e níveis ordenados
+//
+// Retorna erro 404 caso a avaliação não possua rubrica
+func buscaRubrica(avaliacaoId string) (*models.Rubrica, *utils.RestErr) {
+	var rubrica models.Rubrica
+	err := database.DB.
+		Preload("Criterios", func(db *gorm.DB) *gorm.DB { return db.Order("ordem") }).
+		Preload("Criterios.Niveis", func(db *gorm.DB) *gorm.DB { return db.Order("ordem") }).
+		Where("avaliacao_id = ?", avaliacaoId).
+		First(&rubrica).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "A avaliação não possui rubrica", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar rubrica", err)
+	}
+
+	return &rubrica, nil
+}
+
+// possuiRubrica verifica se uma avaliação possui rubrica de correção
+func possuiRubrica(avaliacaoId string) (bool, *utils.RestErr) {
+	var total int64
+	if err := database.DB.Model(&models.Rubrica{}).Where("avaliacao_id = ?", avaliacaoId).Count(&total).Error; err != nil {
+		return false, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar rubrica", err)
+	}
+	return total > 0, nil
+}
+
+// verificaNotaSemRubrica impede o lançamento direto de notas em avaliações corrigidas por rubrica
+func verificaNotaSemRubrica(avaliacaoId string) *utils.RestErr {
+	rubrica, restErr := possuiRubrica(avaliacaoId)
+	if restErr != nil {
+		return restErr
+	}
+	if rubrica {
+		return utils.NewRestErr(http.StatusConflict, "A nota desta avaliação é calculada pela rubrica", nil)
+	}
+	return nil
+}
+
+// buscaNivel retorna o nível do critério com o ID informado ou nil, se ele não pertencer ao critério
+func buscaNivel(criterio *models.CriterioRubrica, nivelId string) *models.NivelRubrica {
+	for i := range criterio.Niveis {
+		if criterio.Niveis[i].Id == nivelId {
+			return &criterio.Niveis[i]
+		}
+	}
+	return nil
+}
+
+// feedbackRubrica monta a correção por rubrica de um aluno, na ordem dos critérios da rubrica
+//
+// Retorna erro 404 caso o trabalho do aluno ainda não tenha sido corrigido pela rubrica
+func feedbackRubrica(rubrica *models.Rubrica, alunoId string) (*models.FeedbackRubrica, *utils.RestErr) {
+	var correcoes []models.AlunoRubrica
+	err := database.DB.Where("avaliacao_id = ? AND aluno_id = ?", rubrica.AvaliacaoId, alunoId).Find(&correcoes).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar correção do aluno", err)
+	}
+	if len(correcoes) == 0 {
+		return nil, utils.NewRestErr(http.StatusNotFound, "O trabalho do aluno ainda não foi corrigido pela rubrica", nil)
+	}
+
+	var nota models.AlunoAvaliacao
+	err = database.DB.Where("avaliacao_id = ? AND aluno_id = ?", rubrica.AvaliacaoId, alunoId).Limit(1).Find(&nota).Error
+	if err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar nota do aluno", err)
+	}
+
+	porCriterio := make(map[string]models.AlunoRubrica, len(correcoes))
+	for _, c := range correcoes {
+		porCriterio[c.CriterioId] = c
+	}
+
+	feedback := &models.FeedbackRubrica{
+		AvaliacaoId:     rubrica.AvaliacaoId,
+		AlunoId:         alunoId,
+		Nota:            nota.Nota,
+		NotaEscala:      nota.NotaEscala,
+		PontuacaoMaxima: rubrica.PontuacaoMaxima,
+		Criterios:       []models.FeedbackCriterio{},
+	}
+	for i := range rubrica.Criterios {
+		criterio := &rubrica.Criterios[i]
+		correcao, ok := porCriterio[criterio.Id]
+		if !ok {
+			continue
+		}
+
+		var maximo float64
+		for _, n := range criterio.Niveis {
+			maximo = math.Max(maximo, n.Pontos)
+		}
+
+		item := models.FeedbackCriterio{
+			CriterioId:    criterio.Id,
+			Criterio:      criterio.Nome,
+			Pontos:        correcao.Pontos,
+			PontosMaximos: maximo,
+			Feedback:      correcao.Feedback,
+		}
+		if nivel := buscaNivel(criterio, correcao.NivelId); nivel != nil {
+			item.Nivel = nivel.Nome
+			item.DescricaoNivel = nivel.Descricao
+		}
+
+		feedback.Pontos += correcao.Pontos
+		feedback.Criterios = append(feedback.Criterios, item)
+	}
+
+	return feedback, nil
+}
@@ -0,0 +1,451 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"net/http"
+	"sistema-alunos-go/database"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+	"sistema-alunos-go/webhooks"
+	"time"
+)
+
+// Parâmetros do entregador de webhooks
+//
+// Cada ciclo reserva até loteEnviosWebhook envios pendentes por reservaEnvioWebhook, tempo suficiente para entregá-los
+// mesmo que todos os destinatários demorem até o limite de tempoEnvioWebhook. Se o servidor parar durante o ciclo,
+// os envios reservados voltam a ser tentados ao fim da reserva
+const (
+	loteEnviosWebhook    = 10
+	tempoEnvioWebhook    = 10 * time.Second
+	reservaEnvioWebhook  = 5 * time.Minute
+	limiteEnviosListados = 200
+)
+
+// clienteWebhooks é o cliente HTTP usado nas entregas de webhooks
+var clienteWebhooks = webhooks.NovoCliente(tempoEnvioWebhook)
+
+// avisoEntregadorWebhooks acorda o entregador de webhooks antes do próximo ciclo, quando há envios já gravados
+var avisoEntregadorWebhooks = make(chan struct{}, 1)
+
+// chaveEventosTransacao identifica, no contexto de uma transação aberta por transacaoEventos, os eventos publicados
+// nela
+type chaveEventosTransacao struct{}
+
+// eventosTransacao guarda as assinaturas ativas lidas em uma transação e indica se algum envio foi gravado nela
+type eventosTransacao struct {
+	assinaturas []models.AssinaturaWebhook
+	carregadas  bool
+	publicados  bool
+}
+
+// CadastrarWebhook inscreve um sistema externo nos eventos informados
+//
+// A URL deve usar http ou https. Se o segredo não for informado, um segredo aleatório é gerado. Eventos repetidos são
+// ignorados
+//
+// Retorna a assinatura criada, com o segredo, ou erro caso a URL seja inválida ou a persistência falhe
+func CadastrarWebhook(cadastro models.CadastroAssinaturaWebhook, professorId string) (*models.AssinaturaWebhook, *utils.RestErr) {
+	if err := webhooks.VerificaUrl(cadastro.Url); err != nil {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "A URL do webhook deve ser um endereço http ou https", err)
+	}
+
+	segredo := cadastro.Segredo
+	if segredo == "" {
+		var err error
+		if segredo, err = webhooks.GeraSegredo(); err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao gerar segredo do webhook", err)
+		}
+	}
+
+	assinatura := models.AssinaturaWebhook{
+		Url:         cadastro.Url,
+		Segredo:     segredo,
+		Eventos:     eventosUnicos(cadastro.Eventos),
+		Descricao:   cadastro.Descricao,
+		Ativa:       true,
+		ProfessorId: &professorId,
+	}
+	if err := database.DB.Create(&assinatura).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao cadastrar webhook", err)
+	}
+
+	return &assinatura, nil
+}
+
+// ListarWebhooks retorna as assinaturas de webhook, sem os segredos, da mais recente para a mais antiga
+func ListarWebhooks() ([]models.AssinaturaWebhook, *utils.RestErr) {
+	var assinaturas []models.AssinaturaWebhook
+	if err := database.DB.Omit("segredo").Order("created_at DESC").Find(&assinaturas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar webhooks", err)
+	}
+
+	return assinaturas, nil
+}
+
+// AtualizarWebhook altera a URL, os eventos, a descrição ou a situação de uma assinatura de webhook
+//
+// Apenas os campos informados são alterados. Desativar a assinatura interrompe as novas tentativas dos envios
+// pendentes, que são abandonados
+//
+// Retorna a assinatura atualizada, sem o segredo, ou erro caso não exista, a URL seja inválida ou a persistência falhe
+func AtualizarWebhook(id string, atualizacao models.AtualizacaoAssinaturaWebhook) (*models.AssinaturaWebhook, *utils.RestErr) {
+	assinatura, restErr := buscaWebhook(id)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	var campos []string
+	if atualizacao.Url != nil {
+		if err := webhooks.VerificaUrl(*atualizacao.Url); err != nil {
+			return nil, utils.NewRestErr(http.StatusBadRequest, "A URL do webhook deve ser um endereço http ou https", err)
+		}
+		assinatura.Url = *atualizacao.Url
+		campos = append(campos, "url")
+	}
+	if atualizacao.Eventos != nil {
+		assinatura.Eventos = eventosUnicos(atualizacao.Eventos)
+		campos = append(campos, "eventos")
+	}
+	if atualizacao.Descricao != nil {
+		assinatura.Descricao = *atualizacao.Descricao
+		campos = append(campos, "descricao")
+	}
+	if atualizacao.Ativa != nil {
+		assinatura.Ativa = *atualizacao.Ativa
+		campos = append(campos, "ativa")
+	}
+
+	if len(campos) > 0 {
+		if err := database.DB.Model(assinatura).Select(campos).Updates(assinatura).Error; err != nil {
+			return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao atualizar webhook", err)
+		}
+	}
+
+	assinatura.Segredo = ""
+	return assinatura, nil
+}
+
+// RemoverWebhook remove uma assinatura de webhook
+//
+// O registro de envios da assinatura é removido junto, pela chave estrangeira
+func RemoverWebhook(id string) *utils.RestErr {
+	assinatura, restErr := buscaWebhook(id)
+	if restErr != nil {
+		return restErr
+	}
+
+	if err := database.DB.Delete(assinatura).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao remover webhook", err)
+	}
+
+	return nil
+}
+
+// ListarEnviosWebhook retorna o registro de envios de uma assinatura de webhook, do mais recente para o mais antigo
+//
+// Se `situacao` for informada, apenas os envios nessa situação são retornados. São retornados no máximo os
+// limiteEnviosListados envios mais recentes
+func ListarEnviosWebhook(assinaturaId string, situacao string) ([]models.EnvioWebhook, *utils.RestErr) {
+	if _, restErr := buscaWebhook(assinaturaId); restErr != nil {
+		return nil, restErr
+	}
+
+	query := database.DB.Where("assinatura_id = ?", assinaturaId)
+	if situacao != "" {
+		query = query.Where("situacao = ?", situacao)
+	}
+
+	var envios []models.EnvioWebhook
+	if err := query.Order("created_at DESC").Limit(limiteEnviosListados).Find(&envios).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar envios do webhook", err)
+	}
+
+	return envios, nil
+}
+
+// ReenviarWebhook agenda um novo envio de um evento já enviado, ou abandonado, a uma assinatura de webhook
+//
+// O novo envio mantém o ID e o corpo do evento original, para que o destinatário possa identificar a repetição, e é
+// tentado imediatamente, com as mesmas regras de novas tentativas. A assinatura deve estar ativa
+//
+// Retorna o novo envio ou erro caso a assinatura ou o envio não existam
+func ReenviarWebhook(assinaturaId string, envioId string) (*models.EnvioWebhook, *utils.RestErr) {
+	assinatura, restErr := buscaWebhook(assinaturaId)
+	if restErr != nil {
+		return nil, restErr
+	}
+
+	if !assinatura.Ativa {
+		return nil, utils.NewRestErr(http.StatusBadRequest, "O webhook está desativado", nil)
+	}
+
+	var original models.EnvioWebhook
+	if err := database.DB.Where("id = ? AND assinatura_id = ?", envioId, assinaturaId).First(&original).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "Envio não encontrado", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar envio", err)
+	}
+
+	agora := time.Now()
+	envio := models.EnvioWebhook{
+		AssinaturaId:     assinaturaId,
+		EventoId:         original.EventoId,
+		Evento:           original.Evento,
+		Corpo:            original.Corpo,
+		Situacao:         models.EnvioPendente,
+		ProximaTentativa: &agora,
+		ReenvioDe:        &original.Id,
+	}
+	if err := database.DB.Create(&envio).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao agendar reenvio", err)
+	}
+
+	avisaEntregadorWebhooks()
+	return &envio, nil
+}
+
+// EntregarWebhooks entrega periodicamente os envios de webhooks pendentes
+//
+// Deve ser executada em uma goroutine própria; a função não retorna. Além do intervalo, o entregador é acordado
+// quando um reenvio é solicitado e quando uma transação com eventos publicados é confirmada. Erros são apenas
+// registrados no log e no envio, que é tentado novamente conforme a política de novas tentativas
+func EntregarWebhooks(intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		entregaWebhooksPendentes()
+
+		select {
+		case <-ticker.C:
+		case <-avisoEntregadorWebhooks:
+		}
+	}
+}
+
+// transacaoEventos executa `fn` em uma transação que pode publicar eventos de webhooks
+//
+// As assinaturas ativas são lidas uma única vez na transação e, se algum envio for gravado, o entregador é acordado
+// após a confirmação, para entregar os eventos sem esperar o próximo ciclo
+func transacaoEventos(fn func(tx *gorm.DB) error) error {
+	eventos := &eventosTransacao{}
+	ctx := context.WithValue(context.Background(), chaveEventosTransacao{}, eventos)
+
+	err := database.DB.WithContext(ctx).Transaction(fn)
+	if err == nil && eventos.publicados {
+		avisaEntregadorWebhooks()
+	}
+	return err
+}
+
+// publicaEvento grava um envio do evento para cada assinatura ativa que o inclui
+//
+// Deve ser chamada dentro da transação que registra a alteração que originou o evento, aberta por transacaoEventos:
+// os envios só são gravados, e entregues, se a transação for confirmada. A entrega é feita depois, em segundo plano,
+// por EntregarWebhooks
+func publicaEvento(tx *gorm.DB, evento string, dados interface{}) *utils.RestErr {
+	assinaturas, restErr := assinaturasAtivas(tx)
+	if restErr != nil {
+		return restErr
+	}
+
+	var envios []models.EnvioWebhook
+	for _, assinatura := range assinaturas {
+		if assinatura.Assina(evento) {
+			envios = append(envios, models.EnvioWebhook{AssinaturaId: assinatura.Id, Evento: evento})
+		}
+	}
+	if len(envios) == 0 {
+		return nil
+	}
+
+	agora := time.Now().UTC()
+	eventoWebhook := models.EventoWebhook{Id: uuid.New().String(), Evento: evento, OcorridoEm: agora, Dados: dados}
+	corpo, err := json.Marshal(eventoWebhook)
+	if err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao montar evento do webhook", err)
+	}
+
+	for i := range envios {
+		envios[i].EventoId = eventoWebhook.Id
+		envios[i].Corpo = string(corpo)
+		envios[i].Situacao = models.EnvioPendente
+		envios[i].ProximaTentativa = &agora
+	}
+	if err := tx.Create(&envios).Error; err != nil {
+		return utils.NewRestErr(http.StatusInternalServerError, "Erro ao registrar envios do webhook", err)
+	}
+
+	if eventos, ok := tx.Statement.Context.Value(chaveEventosTransacao{}).(*eventosTransacao); ok {
+		eventos.publicados = true
+	}
+	return nil
+}
+
+// assinaturasAtivas retorna as assinaturas de webhook ativas, sem os segredos
+//
+// Em transações abertas por transacaoEventos, as assinaturas são buscadas apenas na primeira chamada
+func assinaturasAtivas(tx *gorm.DB) ([]models.AssinaturaWebhook, *utils.RestErr) {
+	eventos, _ := tx.Statement.Context.Value(chaveEventosTransacao{}).(*eventosTransacao)
+	if eventos != nil && eventos.carregadas {
+		return eventos.assinaturas, nil
+	}
+
+	var assinaturas []models.AssinaturaWebhook
+	if err := tx.Omit("segredo").Where("ativa = true").Find(&assinaturas).Error; err != nil {
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar webhooks", err)
+	}
+
+	if eventos != nil {
+		eventos.assinaturas, eventos.carregadas = assinaturas, true
+	}
+	return assinaturas, nil
+}
+
+// avisaEntregadorWebhooks acorda o entregador de webhooks sem bloquear o chamador
+func avisaEntregadorWebhooks() {
+	select {
+	case avisoEntregadorWebhooks <- struct{}{}:
+	default:
+	}
+}
+
+// entregaWebhooksPendentes entrega, em lotes, todos os envios pendentes cuja tentativa já está agendada
+func entregaWebhooksPendentes() {
+	for {
+		envios, err := reservaEnviosWebhook()
+		if err != nil {
+			log.Printf("Erro ao buscar envios de webhook pendentes: %v", err)
+			return
+		}
+		if len(envios) == 0 {
+			return
+		}
+
+		assinaturaIds := make(map[string]bool, len(envios))
+		for _, envio := range envios {
+			assinaturaIds[envio.AssinaturaId] = true
+		}
+
+		var assinaturas []models.AssinaturaWebhook
+		if err := database.DB.Where("id IN ?", chaves(assinaturaIds)).Find(&assinaturas).Error; err != nil {
+			log.Printf("Erro ao buscar webhooks dos envios pendentes: %v", err)
+			return
+		}
+		porId := make(map[string]*models.AssinaturaWebhook, len(assinaturas))
+		for i := range assinaturas {
+			porId[assinaturas[i].Id] = &assinaturas[i]
+		}
+
+		for i := range envios {
+			if err := entregaWebhook(&envios[i], porId[envios[i].AssinaturaId]); err != nil {
+				log.Printf("Erro ao registrar envio de webhook %s: %v", envios[i].Id, err)
+			}
+		}
+	}
+}
+
+// reservaEnviosWebhook seleciona o próximo lote de envios pendentes e adia sua próxima tentativa pelo tempo da reserva
+//
+// Os envios reservados não são selecionados novamente, nem por outras instâncias da API, até o fim da reserva
+func reservaEnviosWebhook() ([]models.EnvioWebhook, error) {
+	agora := time.Now()
+
+	var envios []models.EnvioWebhook
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("situacao = ? AND proxima_tentativa <= ?", models.EnvioPendente, agora).
+			Order("proxima_tentativa").Limit(loteEnviosWebhook).Find(&envios).Error
+		if err != nil || len(envios) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(envios))
+		for i, envio := range envios {
+			ids[i] = envio.Id
+		}
+		return tx.Model(&models.EnvioWebhook{}).Where("id IN ?", ids).
+			Update("proxima_tentativa", agora.Add(reservaEnvioWebhook)).Error
+	})
+
+	return envios, err
+}
+
+// entregaWebhook faz uma tentativa de entrega de um envio e registra o resultado
+//
+// Envios de assinaturas desativadas são abandonados sem tentativa. Após uma falha, a próxima tentativa é agendada
+// conforme a política de novas tentativas ou, se elas se esgotaram, o envio é abandonado
+func entregaWebhook(envio *models.EnvioWebhook, assinatura *models.AssinaturaWebhook) error {
+	if assinatura == nil || !assinatura.Ativa {
+		return database.DB.Model(envio).Updates(map[string]interface{}{
+			"situacao":          models.EnvioAbandonado,
+			"proxima_tentativa": nil,
+			"ultimo_erro":       "O webhook está desativado",
+		}).Error
+	}
+
+	ctx, cancela := context.WithTimeout(context.Background(), tempoEnvioWebhook)
+	defer cancela()
+
+	agora := time.Now()
+	mensagem := webhooks.Mensagem{Id: envio.EventoId, Evento: envio.Evento, Corpo: []byte(envio.Corpo)}
+	resposta, err := webhooks.Envia(ctx, clienteWebhooks, assinatura.Url, assinatura.Segredo, mensagem, agora)
+
+	envio.Tentativas++
+	colunas := map[string]interface{}{
+		"tentativas":      envio.Tentativas,
+		"ultimo_status":   resposta.Status,
+		"ultima_resposta": resposta.Corpo,
+		"ultimo_erro":     "",
+	}
+
+	switch proxima, ok := webhooks.ProximaTentativa(envio.Tentativas, agora); {
+	case err == nil:
+		colunas["situacao"] = models.EnvioEntregue
+		colunas["proxima_tentativa"] = nil
+		colunas["entregue_em"] = agora
+	case ok:
+		colunas["ultimo_erro"] = err.Error()
+		colunas["proxima_tentativa"] = proxima
+	default:
+		colunas["ultimo_erro"] = err.Error()
+		colunas["situacao"] = models.EnvioAbandonado
+		colunas["proxima_tentativa"] = nil
+	}
+
+	return database.DB.Model(envio).Updates(colunas).Error
+}
+
+// buscaWebhook busca uma assinatura de webhook pelo ID
+func buscaWebhook(id string) (*models.AssinaturaWebhook, *utils.RestErr) {
+	var assinatura models.AssinaturaWebhook
+	if err := database.DB.Where("id = ?", id).First(&assinatura).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NewRestErr(http.StatusNotFound, "Webhook não encontrado", err)
+		}
+		return nil, utils.NewRestErr(http.StatusInternalServerError, "Erro ao buscar webhook", err)
+	}
+
+	return &assinatura, nil
+}
+
+// eventosUnicos retorna os eventos informados sem repetições, na ordem em que aparecem
+func eventosUnicos(eventos []string) []string {
+	vistos := make(map[string]bool, len(eventos))
+	unicos := make([]string, 0, len(eventos))
+	for _, evento := range eventos {
+		if !vistos[evento] {
+			vistos[evento] = true
+			unicos = append(unicos, evento)
+		}
+	}
+	return unicos
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrArquivoNaoEncontrado é retornado quando a chave solicitada não existe no armazenamento
+var ErrArquivoNaoEncontrado = errors.New("arquivo não encontrado")
+
+// Storage define as operações de um armazenamento de arquivos enviados à API, como documentos e entregas de trabalhos
+//
+// As chaves são caminhos relativos separados por "/" e são tratadas de forma opaca pela implementação
+type Storage interface {
+	// Salvar grava o conteúdo recebido sob a chave informada, substituindo um arquivo existente
+	Salvar(chave string, conteudo io.Reader) error
+	// Abrir retorna um leitor para o conteúdo armazenado sob a chave, que deve ser fechado pelo chamador
+	Abrir(chave string) (io.ReadCloser, error)
+	// Remover apaga o conteúdo armazenado sob a chave; remover uma chave inexistente não é erro
+	Remover(chave string) error
+}
+
+// Arquivos é a instância global de armazenamento usada pelos serviços da API
+//
+// Deve ser inicializada por meio da função Inicializa()
+var Arquivos Storage
+
+// Inicializa configura o armazenamento global com base nas variáveis de ambiente
+//
+// Usa o sistema de arquivos local no diretório definido em STORAGE_DIR (padrão "arquivos")
+func Inicializa() {
+	diretorio := os.Getenv("STORAGE_DIR")
+	if diretorio == "" {
+		diretorio = "arquivos"
+	}
+
+	Arquivos = NewLocalStorage(diretorio)
+}
+
+// LocalStorage implementa Storage gravando os arquivos em um diretório do sistema de arquivos local
+type LocalStorage struct {
+	Diretorio string
+}
+
+// NewLocalStorage cria um armazenamento local com raiz no diretório informado
+func NewLocalStorage(diretorio string) *LocalStorage {
+	return &LocalStorage{Diretorio: diretorio}
+}
+
+// Salvar grava o conteúdo no arquivo correspondente à chave, criando os diretórios intermediários necessários
+func (l *LocalStorage) Salvar(chave string, conteudo io.Reader) error {
+	caminho, err := l.caminho(chave)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(caminho), 0o750); err != nil {
+		return fmt.Errorf("erro ao criar diretório: %w", err)
+	}
+
+	arquivo, err := os.Create(caminho)
+	if err != nil {
+		return fmt.Errorf("erro ao criar arquivo: %w", err)
+	}
+
+	if _, err := io.Copy(arquivo, conteudo); err != nil {
+		_ = arquivo.Close()
+		_ = os.Remove(caminho)
+		return fmt.Errorf("erro ao gravar arquivo: %w", err)
+	}
+
+	return arquivo.Close()
+}
+
+// Abrir abre o arquivo correspondente à chave para leitura
+func (l *LocalStorage) Abrir(chave string) (io.ReadCloser, error) {
+	caminho, err := l.caminho(chave)
+	if err != nil {
+		return nil, err
+	}
+
+	arquivo, err := os.Open(caminho)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrArquivoNaoEncontrado
+	}
+	return arquivo, err
+}
+
+// Remover apaga o arquivo correspondente à chave
+func (l *LocalStorage) Remover(chave string) error {
+	caminho, err := l.caminho(chave)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(caminho); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// caminho converte uma chave no caminho do arquivo dentro do diretório raiz
+//
+// Rejeita chaves vazias, absolutas ou que tentem sair do diretório raiz
+func (l *LocalStorage) caminho(chave string) (string, error) {
+	limpa := filepath.Clean(filepath.FromSlash(chave))
+	if chave == "" || filepath.IsAbs(limpa) || limpa == ".." || strings.HasPrefix(limpa, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("chave de arquivo inválida: %q", chave)
+	}
+
+	return filepath.Join(l.Diretorio, limpa), nil
+}
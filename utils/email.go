@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// EmailConfigurado indica se as variáveis de ambiente de envio de e-mail (SMTP_HOST e SMTP_FROM) foram definidas
+func EmailConfigurado() bool {
+	return os.Getenv("SMTP_HOST") != "" && os.Getenv("SMTP_FROM") != ""
+}
+
+// EnviaEmail envia uma mensagem de texto simples para o destinatário usando o servidor SMTP configurado
+//
+// Usa as variáveis de ambiente SMTP_HOST, SMTP_PORT (padrão 587), SMTP_USER, SMTP_PASS e SMTP_FROM. A autenticação só
+// é feita quando SMTP_USER é informado. O assunto é codificado conforme a RFC 2047, para que os acentos sejam exibidos
+// corretamente
+//
+// Retorna erro caso o envio de e-mail não esteja configurado ou o servidor recuse a mensagem
+func EnviaEmail(para string, assunto string, corpo string) error {
+	if !EmailConfigurado() {
+		return fmt.Errorf("envio de e-mail não configurado")
+	}
+
+	host := os.Getenv("SMTP_HOST")
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("SMTP_FROM")
+
+	var auth smtp.Auth
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASS"), host)
+	}
+
+	mensagem := strings.Join([]string{
+		"From: " + from,
+		"To: " + para,
+		"Subject: " + mime.QEncoding.Encode("utf-8", assunto),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		corpo,
+	}, "\r\n")
+
+	return smtp.SendMail(host+":"+port, auth, from, []string{para}, []byte(mensagem))
+}
@@ -6,18 +6,25 @@ import (
 )
 
 // RestErr representa uma estrutura padronizada para lidar com erros da API RESTful
-// Inclui um código de status HTTP, uma mensagem amigável e um erro interno opcional
+// Inclui um código de status HTTP, uma mensagem amigável, um erro interno opcional e detalhes opcionais que são
+// devolvidos ao cliente no campo `errors` da resposta
 type RestErr struct {
-	Code int
-	Msg  string
-	Err  error
+	Code   int
+	Msg    string
+	Err    error
+	Errors interface{}
 }
 
 // NewRestErr cria uma nova instância de RestErr contendo código HTTP, mensagem e erro interno
 //
 // Usada para representar erros personalizados em respostas JSON estruturadas
-func NewRestErr(code int, msg string, err error) *RestErr {
-	return &RestErr{Code: code, Msg: msg, Err: err}
+// Pode receber detalhes opcionais no parâmetro variádico `errors`, sendo usado apenas o primeiro elemento
+func NewRestErr(code int, msg string, err error, errors ...interface{}) *RestErr {
+	restErr := &RestErr{Code: code, Msg: msg, Err: err}
+	if len(errors) > 0 {
+		restErr.Errors = errors[0]
+	}
+	return restErr
 }
 
 // RespondRestErr envia uma resposta de erro JSON padronizada para o cliente.
@@ -37,6 +44,7 @@ func RespondRestErr(err *RestErr, ctx *gin.Context) {
 			err.Msg,
 			err.Code,
 			nil,
+			err.Errors,
 		))
 	} else {
 		ctx.JSON(500, NewAppMessage(
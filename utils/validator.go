@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"net/http"
 	"strings"
@@ -17,6 +18,15 @@ type ValidationError struct {
 	Message  string `json:"message,omitempty"`
 }
 
+// ErroAluno representa um problema encontrado em um item de uma lista de alunos enviada na requisição
+//
+// Indica a posição do item na lista, o aluno envolvido e o motivo da rejeição
+type ErroAluno struct {
+	Indice  int    `json:"indice"`
+	AlunoId string `json:"aluno_id"`
+	Motivo  string `json:"motivo"`
+}
+
 // MapValidationError converte um erro de validação do pacote validator para o formato personalizado ValidationError
 //
 // Analisa a tag de validação (`err.Tag()`) e retorna uma estrutura contendo mensagens de erro e valores esperados
@@ -79,19 +89,61 @@ func MapValidationError(err validator.FieldError) ValidationError {
 // Retorna true se os dados forem válidos; caso contrário, false.
 func BindAndValidate[T any](obj *T, ctx *gin.Context) bool {
 	if err := ctx.ShouldBindJSON(obj); err != nil {
-		var validationErrors validator.ValidationErrors
-		if errors.As(err, &validationErrors) {
-			var errorsList []ValidationError
-			for _, e := range validationErrors {
-				errorsList = append(errorsList, MapValidationError(e))
-			}
-
-			ctx.JSON(http.StatusBadRequest, NewAppMessage("Erro de Validação", http.StatusBadRequest, nil, errorsList))
-			return false
-		}
+		respondBindError(err, ctx)
+		return false
+	}
+	return true
+}
 
-		ctx.JSON(http.StatusBadRequest, NewAppMessage("Dados inválidos", http.StatusBadRequest, nil, err.Error()))
+// BindFormAndValidate realiza o bind dos campos de um formulário (inclusive multipart) para a struct fornecida e
+// valida os campos com base nas tags de validação
+//
+// As respostas de erro seguem o mesmo formato de BindAndValidate.
+//
+// Retorna true se os dados forem válidos; caso contrário, false.
+func BindFormAndValidate[T any](obj *T, ctx *gin.Context) bool {
+	if err := ctx.ShouldBind(obj); err != nil {
+		respondBindError(err, ctx)
 		return false
 	}
 	return true
 }
+
+// ValidateStruct valida os campos de uma struct já preenchida com as mesmas regras usadas no bind das requisições
+//
+// É usada quando os dados não vêm do corpo da requisição, como nas linhas de uma planilha importada.
+//
+// Retorna a lista de erros formatados, vazia se os dados forem válidos.
+func ValidateStruct(obj any) []ValidationError {
+	err := binding.Validator.ValidateStruct(obj)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []ValidationError{{Message: err.Error()}}
+	}
+
+	errorsList := make([]ValidationError, 0, len(validationErrors))
+	for _, e := range validationErrors {
+		errorsList = append(errorsList, MapValidationError(e))
+	}
+	return errorsList
+}
+
+// respondBindError envia a resposta de erro adequada para uma falha de bind ou validação
+func respondBindError(err error, ctx *gin.Context) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		var errorsList []ValidationError
+		for _, e := range validationErrors {
+			errorsList = append(errorsList, MapValidationError(e))
+		}
+
+		ctx.JSON(http.StatusBadRequest, NewAppMessage("Erro de Validação", http.StatusBadRequest, nil, errorsList))
+		return
+	}
+
+	ctx.JSON(http.StatusBadRequest, NewAppMessage("Dados inválidos", http.StatusBadRequest, nil, err.Error()))
+}
@@ -2,6 +2,7 @@ package validations
 
 import (
 	"github.com/gin-gonic/gin"
+	"net/http"
 	"sistema-alunos-go/models"
 	"sistema-alunos-go/utils"
 )
@@ -10,3 +11,29 @@ import (
 func AlunoValido(aluno *models.Aluno, ctx *gin.Context) bool {
 	return utils.BindAndValidate(aluno, ctx)
 }
+
+// DefinicaoSenhaValida valida os campos de um objeto DefinicaoSenha com base nas regras definidas, além de confirmar se
+// as senhas em 'senha' e 'confirmar_senha' são iguais, retornando true para dados válidos.
+func DefinicaoSenhaValida(definicao *models.DefinicaoSenha, ctx *gin.Context) bool {
+	if !utils.BindAndValidate(definicao, ctx) {
+		return false
+	}
+
+	if definicao.Senha != definicao.ConfirmarSenha {
+		response := utils.NewAppMessage("Erro de Validação", http.StatusBadRequest, nil, []map[string]interface{}{
+			{
+				"expected": "Senhas iguais",
+				"message":  "as duas senhas devem ser iguais",
+			},
+		})
+		ctx.JSON(http.StatusBadRequest, response)
+		return false
+	}
+	return true
+}
+
+// ImportacaoPlanilhaValida valida as opções do envio de uma planilha para importação, retornando true para dados
+// válidos.
+func ImportacaoPlanilhaValida(importacao *models.ImportacaoPlanilha, ctx *gin.Context) bool {
+	return utils.BindFormAndValidate(importacao, ctx)
+}
@@ -10,3 +10,13 @@ import (
 func AulaValida(aula *models.Aula, ctx *gin.Context) bool {
 	return utils.BindAndValidate(aula, ctx)
 }
+
+// AtualizacaoAulaValida valida os campos de um objeto AtualizacaoAula com base nas regras definidas, retornando true para dados válidos.
+func AtualizacaoAulaValida(atualizacao *models.AtualizacaoAula, ctx *gin.Context) bool {
+	return utils.BindAndValidate(atualizacao, ctx)
+}
+
+// CorrecaoPresencaValida valida os campos de um objeto CorrecaoPresenca com base nas regras definidas, retornando true para dados válidos.
+func CorrecaoPresencaValida(correcao *models.CorrecaoPresenca, ctx *gin.Context) bool {
+	return utils.BindAndValidate(correcao, ctx)
+}
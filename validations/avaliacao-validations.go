@@ -11,7 +11,32 @@ func AvaliacaoValida(avaliacao *models.Avaliacao, ctx *gin.Context) bool {
 	return utils.BindAndValidate(avaliacao, ctx)
 }
 
+// AtualizacaoAvaliacaoValida valida os campos de um objeto AtualizacaoAvaliacao com base nas regras definidas, retornando true para dados válidos.
+func AtualizacaoAvaliacaoValida(atualizacao *models.AtualizacaoAvaliacao, ctx *gin.Context) bool {
+	return utils.BindAndValidate(atualizacao, ctx)
+}
+
 // NotaValida valida os campos de um objeto AlunoAvaliacao com base nas regras definidas, retornando true para dados válidos.
 func NotaValida(alunoNota *[]models.AlunoAvaliacao, ctx *gin.Context) bool {
 	return utils.BindAndValidate(alunoNota, ctx)
 }
+
+// CorrecaoNotaValida valida os campos de um objeto CorrecaoNota com base nas regras definidas, retornando true para dados válidos.
+func CorrecaoNotaValida(correcao *models.CorrecaoNota, ctx *gin.Context) bool {
+	return utils.BindAndValidate(correcao, ctx)
+}
+
+// RubricaValida valida os campos de um objeto Rubrica com base nas regras definidas, retornando true para dados válidos.
+func RubricaValida(rubrica *models.Rubrica, ctx *gin.Context) bool {
+	return utils.BindAndValidate(rubrica, ctx)
+}
+
+// CorrecaoRubricaValida valida os campos de um objeto CorrecaoRubrica com base nas regras definidas, retornando true para dados válidos.
+func CorrecaoRubricaValida(correcao *models.CorrecaoRubrica, ctx *gin.Context) bool {
+	return utils.BindAndValidate(correcao, ctx)
+}
+
+// ImportacaoNotasValida valida as opções do envio de uma planilha de notas, retornando true para dados válidos.
+func ImportacaoNotasValida(importacao *models.ImportacaoNotas, ctx *gin.Context) bool {
+	return utils.BindFormAndValidate(importacao, ctx)
+}
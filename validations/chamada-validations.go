@@ -0,0 +1,17 @@
+package validations
+
+import (
+	"github.com/gin-gonic/gin"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// AberturaChamadaValida valida os campos de um objeto AberturaChamada com base nas regras definidas, retornando true para dados válidos.
+func AberturaChamadaValida(abertura *models.AberturaChamada, ctx *gin.Context) bool {
+	return utils.BindAndValidate(abertura, ctx)
+}
+
+// CheckInValido valida os campos de um objeto CheckIn com base nas regras definidas, retornando true para dados válidos.
+func CheckInValido(checkIn *models.CheckIn, ctx *gin.Context) bool {
+	return utils.BindAndValidate(checkIn, ctx)
+}
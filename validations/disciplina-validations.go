@@ -15,6 +15,11 @@ func DisciplinaValida(disciplina *models.Disciplina, ctx *gin.Context) bool {
 	return utils.BindAndValidate(disciplina, ctx)
 }
 
+// ConfiguracaoDisciplinaValida valida os campos de um objeto ConfiguracaoDisciplina com base nas regras definidas, retornando true para dados válidos.
+func ConfiguracaoDisciplinaValida(configuracao *models.ConfiguracaoDisciplina, ctx *gin.Context) bool {
+	return utils.BindAndValidate(configuracao, ctx)
+}
+
 // AnoSemestre valida se uma string representa um formato ano-semestre válido (AAAA-01 ou AAAA-02) a partir de 2021.
 func AnoSemestre(fl validator.FieldLevel) bool {
 	data := fl.Field().String()
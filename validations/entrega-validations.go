@@ -0,0 +1,12 @@
+package validations
+
+import (
+	"github.com/gin-gonic/gin"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// JanelaEntregaValida valida os campos de um objeto JanelaEntrega com base nas regras definidas, retornando true para dados válidos.
+func JanelaEntregaValida(janela *models.JanelaEntrega, ctx *gin.Context) bool {
+	return utils.BindAndValidate(janela, ctx)
+}
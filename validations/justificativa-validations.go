@@ -0,0 +1,17 @@
+package validations
+
+import (
+	"github.com/gin-gonic/gin"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// SolicitacaoJustificativaValida valida os campos do formulário de uma SolicitacaoJustificativa com base nas regras definidas, retornando true para dados válidos.
+func SolicitacaoJustificativaValida(solicitacao *models.SolicitacaoJustificativa, ctx *gin.Context) bool {
+	return utils.BindFormAndValidate(solicitacao, ctx)
+}
+
+// AvaliacaoJustificativaValida valida os campos de um objeto AvaliacaoJustificativa com base nas regras definidas, retornando true para dados válidos.
+func AvaliacaoJustificativaValida(avaliacao *models.AvaliacaoJustificativa, ctx *gin.Context) bool {
+	return utils.BindAndValidate(avaliacao, ctx)
+}
@@ -0,0 +1,22 @@
+package validations
+
+import (
+	"github.com/gin-gonic/gin"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// QuestaoValida valida os campos de um objeto Questao com base nas regras definidas, retornando true para dados válidos.
+func QuestaoValida(questao *models.Questao, ctx *gin.Context) bool {
+	return utils.BindAndValidate(questao, ctx)
+}
+
+// MontagemQuestionarioValida valida os campos de um objeto MontagemQuestionario com base nas regras definidas, retornando true para dados válidos.
+func MontagemQuestionarioValida(montagem *models.MontagemQuestionario, ctx *gin.Context) bool {
+	return utils.BindAndValidate(montagem, ctx)
+}
+
+// RespostaQuestaoValida valida os campos de um objeto RespostaQuestao com base nas regras definidas, retornando true para dados válidos.
+func RespostaQuestaoValida(resposta *models.RespostaQuestao, ctx *gin.Context) bool {
+	return utils.BindAndValidate(resposta, ctx)
+}
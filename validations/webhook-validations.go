@@ -0,0 +1,19 @@
+package validations
+
+import (
+	"github.com/gin-gonic/gin"
+	"sistema-alunos-go/models"
+	"sistema-alunos-go/utils"
+)
+
+// CadastroWebhookValido valida os campos de um objeto CadastroAssinaturaWebhook com base nas regras definidas,
+// retornando true para dados válidos.
+func CadastroWebhookValido(cadastro *models.CadastroAssinaturaWebhook, ctx *gin.Context) bool {
+	return utils.BindAndValidate(cadastro, ctx)
+}
+
+// AtualizacaoWebhookValida valida os campos de um objeto AtualizacaoAssinaturaWebhook com base nas regras definidas,
+// retornando true para dados válidos.
+func AtualizacaoWebhookValida(atualizacao *models.AtualizacaoAssinaturaWebhook, ctx *gin.Context) bool {
+	return utils.BindAndValidate(atualizacao, ctx)
+}
@@ -0,0 +1,215 @@
+// Package webhooks entrega os eventos do sistema aos serviços externos inscritos, como o AVA e o sistema da secretaria
+//
+// Cada entrega é um POST com o evento em JSON, assinado com HMAC-SHA256 usando o segredo da inscrição. O pacote também
+// define o intervalo entre as novas tentativas de entregas que falharam; o registro das entregas fica a cargo de quem
+// o usa
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Cabeçalhos enviados em cada entrega
+//
+// CabecalhoAssinatura traz "sha256=" seguido do HMAC-SHA256, em hexadecimal, de CabecalhoTimestamp, um ponto e o corpo
+// da requisição. O timestamp, em segundos desde 1970, permite ao destinatário recusar entregas antigas reenviadas
+// por terceiros
+const (
+	CabecalhoEvento     = "X-Webhook-Evento"
+	CabecalhoId         = "X-Webhook-Id"
+	CabecalhoTimestamp  = "X-Webhook-Timestamp"
+	CabecalhoAssinatura = "X-Webhook-Assinatura"
+)
+
+// Política de novas tentativas
+//
+// Após a n-ésima falha, a entrega é tentada novamente depois de AtrasoInicial * 2^(n-1), limitado a AtrasoMaximo. Após
+// MaximoTentativas falhas, a entrega é abandonada
+const (
+	MaximoTentativas = 8
+	AtrasoInicial    = 30 * time.Second
+	AtrasoMaximo     = 6 * time.Hour
+)
+
+// tamanhoMaximoResposta limita o trecho do corpo da resposta guardado para diagnóstico
+const tamanhoMaximoResposta = 1024
+
+// Mensagem representa a entrega de um evento a um destinatário
+//
+// Id identifica o evento e é o mesmo em todas as tentativas e destinatários, para que entregas repetidas possam ser
+// descartadas
+type Mensagem struct {
+	Id     string
+	Evento string
+	Corpo  []byte
+}
+
+// Resposta representa o resultado de uma tentativa de entrega
+//
+// Status é zero quando o destinatário não chegou a responder. Corpo traz o início da resposta, para diagnóstico
+type Resposta struct {
+	Status int
+	Corpo  string
+}
+
+// redeCompartilhada é a faixa 100.64.0.0/10, usada por provedores e nuvens para endereços internos
+var redeCompartilhada = netip.MustParsePrefix("100.64.0.0/10")
+
+// ErrUrlInvalida indica que a URL de uma inscrição não é um endereço HTTP ou HTTPS
+var ErrUrlInvalida = errors.New("a URL do webhook deve ser um endereço http ou https")
+
+// ErrEnderecoInterno indica que o destinatário de uma entrega está em um endereço interno, que não pode ser acessado
+var ErrEnderecoInterno = errors.New("o destinatário está em um endereço de rede interno")
+
+// VerificaUrl confere se a URL de uma inscrição usa http ou https e informa o servidor
+//
+// O endereço do servidor só é conferido nas entregas, por NovoCliente, pois o nome pode passar a apontar para outro
+// endereço após o cadastro
+func VerificaUrl(endereco string) error {
+	destino, err := url.Parse(endereco)
+	if err != nil || (destino.Scheme != "http" && destino.Scheme != "https") || destino.Hostname() == "" {
+		return ErrUrlInvalida
+	}
+	return nil
+}
+
+// NovoCliente cria o cliente HTTP das entregas, com o tempo limite informado
+//
+// O cliente recusa conexões com endereços de loopback, de redes privadas, de link local e não especificados, inclusive
+// após redirecionamentos, para que as inscrições não sejam usadas para acessar serviços internos. A conferência é
+// feita no endereço já resolvido, no momento da conexão. Proxies configurados no ambiente não são usados
+func NovoCliente(tempo time.Duration) *http.Client {
+	discador := &net.Dialer{Timeout: tempo, Control: recusaEnderecoInterno}
+	transporte := &http.Transport{
+		DialContext:         discador.DialContext,
+		TLSHandshakeTimeout: tempo,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &http.Client{Timeout: tempo, Transport: transporte}
+}
+
+// recusaEnderecoInterno impede a conexão com endereços internos; é chamada pelo discador antes de cada conexão
+func recusaEnderecoInterno(_ string, endereco string, _ syscall.RawConn) error {
+	destino, err := netip.ParseAddrPort(endereco)
+	if err != nil {
+		return err
+	}
+	if enderecoInterno(destino.Addr()) {
+		return ErrEnderecoInterno
+	}
+	return nil
+}
+
+// enderecoInterno indica se um endereço IP é de loopback, de rede privada ou compartilhada, de link local, multicast
+// ou não especificado
+func enderecoInterno(endereco netip.Addr) bool {
+	endereco = endereco.Unmap()
+	return endereco.IsLoopback() || endereco.IsPrivate() || endereco.IsLinkLocalUnicast() ||
+		endereco.IsLinkLocalMulticast() || endereco.IsInterfaceLocalMulticast() || endereco.IsMulticast() ||
+		endereco.IsUnspecified() || redeCompartilhada.Contains(endereco)
+}
+
+// GeraSegredo gera um segredo aleatório para a assinatura das entregas de uma inscrição
+func GeraSegredo() (string, error) {
+	segredo := make([]byte, 32)
+	if _, err := rand.Read(segredo); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(segredo), nil
+}
+
+// Assina calcula o valor do cabeçalho de assinatura de uma entrega
+func Assina(segredo string, timestamp int64, corpo []byte) string {
+	mac := hmac.New(sha256.New, []byte(segredo))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(corpo)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verifica confere a assinatura de uma entrega recebida, a partir dos cabeçalhos de timestamp e assinatura
+//
+// É usada pelos destinatários escritos em Go e nos testes. A comparação leva sempre o mesmo tempo, para não revelar
+// o valor esperado
+func Verifica(segredo string, timestamp string, corpo []byte, assinatura string) bool {
+	valor, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(Assina(segredo, valor, corpo)), []byte(assinatura))
+}
+
+// Envia entrega uma mensagem à URL informada, assinada com o segredo
+//
+// Apenas respostas com status 2xx confirmam a entrega; para as demais, e se o destinatário não responder, retorna
+// erro. A resposta é retornada sempre que recebida, mesmo com erro
+func Envia(ctx context.Context, cliente *http.Client, url string, segredo string, mensagem Mensagem, agora time.Time) (Resposta, error) {
+	requisicao, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(mensagem.Corpo))
+	if err != nil {
+		return Resposta{}, err
+	}
+
+	timestamp := agora.Unix()
+	requisicao.Header.Set("Content-Type", "application/json")
+	requisicao.Header.Set("User-Agent", "sistema-alunos-go/webhooks")
+	requisicao.Header.Set(CabecalhoEvento, mensagem.Evento)
+	requisicao.Header.Set(CabecalhoId, mensagem.Id)
+	requisicao.Header.Set(CabecalhoTimestamp, strconv.FormatInt(timestamp, 10))
+	requisicao.Header.Set(CabecalhoAssinatura, Assina(segredo, timestamp, mensagem.Corpo))
+
+	resposta, err := cliente.Do(requisicao)
+	if err != nil {
+		return Resposta{}, err
+	}
+	defer resposta.Body.Close()
+
+	corpo, _ := io.ReadAll(io.LimitReader(resposta.Body, tamanhoMaximoResposta))
+	// Descarta o restante para que a conexão possa ser reaproveitada
+	_, _ = io.Copy(io.Discard, io.LimitReader(resposta.Body, 64<<10))
+
+	resultado := Resposta{Status: resposta.StatusCode, Corpo: strings.ToValidUTF8(string(corpo), "")}
+	if resposta.StatusCode < 200 || resposta.StatusCode > 299 {
+		return resultado, fmt.Errorf("o destinatário respondeu com status %d", resposta.StatusCode)
+	}
+	return resultado, nil
+}
+
+// Atraso retorna o intervalo até a próxima tentativa de uma entrega que já falhou `falhas` vezes
+func Atraso(falhas int) time.Duration {
+	if falhas < 1 {
+		return 0
+	}
+
+	atraso := AtrasoInicial
+	for i := 1; i < falhas && atraso < AtrasoMaximo; i++ {
+		atraso *= 2
+	}
+	return min(atraso, AtrasoMaximo)
+}
+
+// ProximaTentativa retorna quando uma entrega que já falhou `falhas` vezes deve ser tentada novamente
+//
+// Retorna false se as tentativas se esgotaram e a entrega deve ser abandonada
+func ProximaTentativa(falhas int, agora time.Time) (time.Time, bool) {
+	if falhas >= MaximoTentativas {
+		return time.Time{}, false
+	}
+	return agora.Add(Atraso(falhas)), true
+}
@@ -0,0 +1,279 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// segredoTeste é o segredo da inscrição usada nos testes
+const segredoTeste = "segredo-de-teste"
+
+// mensagemTeste é um evento de nota lançada usado nos testes
+var mensagemTeste = Mensagem{
+	Id:     "5b0f8d9e-3c1a-4f2e-9d7b-2a6c8e4f1b3d",
+	Evento: "nota.lancada",
+	Corpo:  []byte(`{"id":"5b0f8d9e-3c1a-4f2e-9d7b-2a6c8e4f1b3d","evento":"nota.lancada","dados":{"nota":7.5}}`),
+}
+
+// recebido guarda o que o destinatário de teste recebeu em uma entrega
+type recebido struct {
+	metodo     string
+	cabecalhos http.Header
+	corpo      []byte
+}
+
+// destinatarioTeste cria um servidor que registra as entregas recebidas e responde com os status informados, na
+// ordem; após o último, repete-o
+func destinatarioTeste(t *testing.T, status ...int) (*httptest.Server, *[]recebido) {
+	t.Helper()
+
+	var entregas []recebido
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		corpo, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("erro ao ler corpo: %v", err)
+		}
+		entregas = append(entregas, recebido{metodo: r.Method, cabecalhos: r.Header.Clone(), corpo: corpo})
+
+		w.WriteHeader(status[min(len(entregas), len(status))-1])
+		_, _ = w.Write([]byte("recebido"))
+	}))
+	t.Cleanup(servidor.Close)
+
+	return servidor, &entregas
+}
+
+func TestEnviaAssinaEntrega(t *testing.T) {
+	servidor, entregas := destinatarioTeste(t, http.StatusNoContent)
+	agora := time.Date(2025, time.July, 10, 14, 30, 0, 0, time.UTC)
+
+	resposta, err := Envia(context.Background(), servidor.Client(), servidor.URL, segredoTeste, mensagemTeste, agora)
+	if err != nil {
+		t.Fatalf("Envia retornou erro: %v", err)
+	}
+	if resposta.Status != http.StatusNoContent {
+		t.Errorf("status = %d, esperado %d", resposta.Status, http.StatusNoContent)
+	}
+
+	if len(*entregas) != 1 {
+		t.Fatalf("%d entregas recebidas, esperada 1", len(*entregas))
+	}
+	entrega := (*entregas)[0]
+
+	if entrega.metodo != http.MethodPost {
+		t.Errorf("método = %s, esperado POST", entrega.metodo)
+	}
+	if string(entrega.corpo) != string(mensagemTeste.Corpo) {
+		t.Errorf("corpo = %s, esperado %s", entrega.corpo, mensagemTeste.Corpo)
+	}
+
+	esperados := map[string]string{
+		"Content-Type":     "application/json",
+		CabecalhoEvento:    mensagemTeste.Evento,
+		CabecalhoId:        mensagemTeste.Id,
+		CabecalhoTimestamp: strconv.FormatInt(agora.Unix(), 10),
+	}
+	for cabecalho, valor := range esperados {
+		if obtido := entrega.cabecalhos.Get(cabecalho); obtido != valor {
+			t.Errorf("%s = %q, esperado %q", cabecalho, obtido, valor)
+		}
+	}
+
+	assinatura := entrega.cabecalhos.Get(CabecalhoAssinatura)
+	if !Verifica(segredoTeste, entrega.cabecalhos.Get(CabecalhoTimestamp), entrega.corpo, assinatura) {
+		t.Errorf("assinatura %q não confere", assinatura)
+	}
+	if Verifica("outro-segredo", entrega.cabecalhos.Get(CabecalhoTimestamp), entrega.corpo, assinatura) {
+		t.Error("assinatura conferiu com outro segredo")
+	}
+	if Verifica(segredoTeste, entrega.cabecalhos.Get(CabecalhoTimestamp), append(entrega.corpo, ' '), assinatura) {
+		t.Error("assinatura conferiu com o corpo alterado")
+	}
+	if Verifica(segredoTeste, strconv.FormatInt(agora.Unix()+1, 10), entrega.corpo, assinatura) {
+		t.Error("assinatura conferiu com outro timestamp")
+	}
+}
+
+func TestEnviaFalhas(t *testing.T) {
+	servidor, _ := destinatarioTeste(t, http.StatusInternalServerError)
+
+	resposta, err := Envia(context.Background(), servidor.Client(), servidor.URL, segredoTeste, mensagemTeste, time.Now())
+	if err == nil {
+		t.Fatal("Envia não retornou erro para status 500")
+	}
+	if resposta.Status != http.StatusInternalServerError || resposta.Corpo != "recebido" {
+		t.Errorf("resposta = %+v, esperado status 500 com o corpo", resposta)
+	}
+
+	servidor.Close()
+	resposta, err = Envia(context.Background(), http.DefaultClient, servidor.URL, segredoTeste, mensagemTeste, time.Now())
+	if err == nil {
+		t.Fatal("Envia não retornou erro com o destinatário fora do ar")
+	}
+	if resposta.Status != 0 {
+		t.Errorf("status = %d, esperado 0 sem resposta", resposta.Status)
+	}
+}
+
+func TestEnviaTentaNovamenteAteEntregar(t *testing.T) {
+	servidor, entregas := destinatarioTeste(t, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+
+	// Reproduz o ciclo do entregador: tenta, e após cada falha agenda a próxima tentativa
+	agora := time.Date(2025, time.July, 10, 14, 30, 0, 0, time.UTC)
+	var agendadas []time.Time
+	falhas := 0
+	for {
+		_, err := Envia(context.Background(), servidor.Client(), servidor.URL, segredoTeste, mensagemTeste, agora)
+		if err == nil {
+			break
+		}
+
+		falhas++
+		proxima, ok := ProximaTentativa(falhas, agora)
+		if !ok {
+			t.Fatalf("tentativas esgotadas após %d falhas", falhas)
+		}
+		agendadas = append(agendadas, proxima)
+		agora = proxima
+	}
+
+	if len(*entregas) != 3 {
+		t.Fatalf("%d entregas recebidas, esperadas 3", len(*entregas))
+	}
+	inicio := time.Date(2025, time.July, 10, 14, 30, 0, 0, time.UTC)
+	esperadas := []time.Time{inicio.Add(30 * time.Second), inicio.Add(90 * time.Second)}
+	for i := range esperadas {
+		if !agendadas[i].Equal(esperadas[i]) {
+			t.Errorf("tentativa %d agendada para %v, esperado %v", i+2, agendadas[i], esperadas[i])
+		}
+	}
+
+	for i, entrega := range *entregas {
+		if entrega.cabecalhos.Get(CabecalhoId) != mensagemTeste.Id {
+			t.Errorf("tentativa %d com id %q, esperado %q", i+1, entrega.cabecalhos.Get(CabecalhoId), mensagemTeste.Id)
+		}
+		if !Verifica(segredoTeste, entrega.cabecalhos.Get(CabecalhoTimestamp), entrega.corpo, entrega.cabecalhos.Get(CabecalhoAssinatura)) {
+			t.Errorf("assinatura da tentativa %d não confere", i+1)
+		}
+	}
+}
+
+func TestAtraso(t *testing.T) {
+	casos := []struct {
+		falhas int
+		atraso time.Duration
+	}{
+		{0, 0},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, AtrasoMaximo},
+		{100, AtrasoMaximo},
+	}
+
+	for _, caso := range casos {
+		if atraso := Atraso(caso.falhas); atraso != caso.atraso {
+			t.Errorf("Atraso(%d) = %v, esperado %v", caso.falhas, atraso, caso.atraso)
+		}
+	}
+}
+
+func TestProximaTentativaEsgota(t *testing.T) {
+	agora := time.Date(2025, time.July, 10, 14, 30, 0, 0, time.UTC)
+
+	if proxima, ok := ProximaTentativa(MaximoTentativas-1, agora); !ok || !proxima.After(agora) {
+		t.Errorf("ProximaTentativa(%d) = %v, %v; esperada nova tentativa", MaximoTentativas-1, proxima, ok)
+	}
+	if _, ok := ProximaTentativa(MaximoTentativas, agora); ok {
+		t.Errorf("ProximaTentativa(%d) agendou nova tentativa; esperado abandono", MaximoTentativas)
+	}
+}
+
+func TestGeraSegredo(t *testing.T) {
+	primeiro, err := GeraSegredo()
+	if err != nil {
+		t.Fatalf("GeraSegredo retornou erro: %v", err)
+	}
+	segundo, _ := GeraSegredo()
+
+	if len(primeiro) != 64 {
+		t.Errorf("segredo com %d caracteres, esperados 64", len(primeiro))
+	}
+	if primeiro == segundo {
+		t.Error("GeraSegredo repetiu o segredo")
+	}
+}
+
+func TestVerificaUrl(t *testing.T) {
+	casos := []struct {
+		url    string
+		valida bool
+	}{
+		{"https://ava.escola.br/webhooks", true},
+		{"http://secretaria.escola.br:8080/eventos?origem=notas", true},
+		{"ftp://ava.escola.br/webhooks", false},
+		{"file:///etc/passwd", false},
+		{"gopher://ava.escola.br", false},
+		{"https://", false},
+		{"ava.escola.br/webhooks", false},
+		{"", false},
+	}
+
+	for _, caso := range casos {
+		if err := VerificaUrl(caso.url); (err == nil) != caso.valida {
+			t.Errorf("VerificaUrl(%q) = %v, esperado válida = %v", caso.url, err, caso.valida)
+		}
+	}
+}
+
+func TestEnderecoInterno(t *testing.T) {
+	casos := []struct {
+		endereco string
+		interno  bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.10", true},
+		{"192.168.1.20", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"224.0.0.1", true},
+		{"::1", true},
+		{"::", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"200.160.2.3", false},
+		{"8.8.8.8", false},
+		{"2001:4860:4860::8888", false},
+	}
+
+	for _, caso := range casos {
+		if interno := enderecoInterno(netip.MustParseAddr(caso.endereco)); interno != caso.interno {
+			t.Errorf("enderecoInterno(%s) = %v, esperado %v", caso.endereco, interno, caso.interno)
+		}
+	}
+}
+
+func TestNovoClienteRecusaEnderecoInterno(t *testing.T) {
+	servidor, entregas := destinatarioTeste(t, http.StatusOK)
+
+	_, err := Envia(context.Background(), NovoCliente(time.Second), servidor.URL, segredoTeste, mensagemTeste, time.Now())
+	if !errors.Is(err, ErrEnderecoInterno) {
+		t.Errorf("Envia para %s retornou %v, esperado ErrEnderecoInterno", servidor.URL, err)
+	}
+	if len(*entregas) != 0 {
+		t.Errorf("%d entregas recebidas pelo servidor local, esperada nenhuma", len(*entregas))
+	}
+}